@@ -0,0 +1,276 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"grpc-client/models"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Assertion operators
+const (
+	assertEquals      = "equals"
+	assertNotEquals   = "notEquals"
+	assertContains    = "contains"
+	assertNotContains = "notContains"
+	assertMatches     = "matches"
+	assertExists      = "exists"
+	assertNotExists   = "notExists"
+	assertGreaterThan = "greaterThan"
+	assertLessThan    = "lessThan"
+)
+
+// evaluateAssertions checks the enabled assertions against a call result
+func evaluateAssertions(assertions []models.Assertion, result *models.CallResult) []models.AssertionResult {
+	var results []models.AssertionResult
+	for _, assertion := range assertions {
+		if assertion.Disabled {
+			continue
+		}
+		results = append(results, evaluateAssertion(assertion, result))
+	}
+	return results
+}
+
+// assertionsPassed reports whether a run passed: every assertion must pass, and the call must
+// succeed unless a status assertion states which status is expected instead.
+func assertionsPassed(result *models.CallResult, assertions []models.Assertion, results []models.AssertionResult) bool {
+	for _, assertionResult := range results {
+		if !assertionResult.Passed {
+			return false
+		}
+	}
+	for _, assertion := range assertions {
+		if !assertion.Disabled && assertion.Type == models.AssertionStatus {
+			return true
+		}
+	}
+	return result.Succeeded()
+}
+
+func evaluateAssertion(assertion models.Assertion, result *models.CallResult) models.AssertionResult {
+	outcome := models.AssertionResult{Assertion: assertion}
+
+	switch assertion.Type {
+	case models.AssertionStatus:
+		outcome.Actual = result.Status
+		expected := formatFieldValue(assertion.Expected)
+		matched := statusMatches(result, expected)
+		if assertion.Operator == assertNotEquals {
+			matched = !matched
+		}
+		outcome.Passed = matched
+		if !matched {
+			outcome.Message = fmt.Sprintf("expected status %s %s, got %s (%d)", operatorOrDefault(assertion.Operator, assertEquals), expected, result.Status, result.StatusCode)
+		}
+
+	case models.AssertionJSONPath:
+		value, found, err := evaluateJSONPath(result.Body, assertion.Path)
+		if err != nil {
+			outcome.Message = err.Error()
+			return outcome
+		}
+		if found {
+			outcome.Actual = value
+		}
+		outcome.Passed, outcome.Message = compareAssertionValue(assertion, value, found)
+
+	case models.AssertionHeader, models.AssertionTrailer:
+		values := result.Headers
+		if assertion.Type == models.AssertionTrailer {
+			values = result.Trailers
+		}
+		headerValues, found := values[strings.ToLower(assertion.Path)]
+		found = found && len(headerValues) > 0
+		var value interface{}
+		if found {
+			value = strings.Join(headerValues, ", ")
+			outcome.Actual = value
+		}
+		// Without an expected value a header assertion checks presence
+		if assertion.Operator == "" && assertion.Expected == nil {
+			assertion.Operator = assertExists
+		}
+		outcome.Passed, outcome.Message = compareAssertionValue(assertion, value, found)
+
+	case models.AssertionLatency:
+		outcome.Actual = result.DurationMs
+		limit, ok := toFloat(assertion.Expected)
+		if !ok {
+			outcome.Message = fmt.Sprintf("invalid latency limit %v", assertion.Expected)
+			return outcome
+		}
+		outcome.Passed = float64(result.DurationMs) < limit
+		if !outcome.Passed {
+			outcome.Message = fmt.Sprintf("took %dms, expected under %vms", result.DurationMs, limit)
+		}
+
+	case models.AssertionSchema:
+		value := result.Body
+		if assertion.Path != "" {
+			var err error
+			if value, _, err = evaluateJSONPath(result.Body, assertion.Path); err != nil {
+				outcome.Message = err.Error()
+				return outcome
+			}
+		}
+		violations := validateJSONSchema(value, assertion.Schema)
+		outcome.Passed = len(violations) == 0
+		if !outcome.Passed {
+			outcome.Message = strings.Join(violations, "; ")
+		}
+
+	default:
+		outcome.Message = fmt.Sprintf("unknown assertion type %q", assertion.Type)
+	}
+
+	return outcome
+}
+
+// compareAssertionValue applies the assertion operator to the actual value
+func compareAssertionValue(assertion models.Assertion, actual interface{}, found bool) (bool, string) {
+	operator := assertion.Operator
+	if operator == "" {
+		operator = assertEquals
+		if assertion.Expected == nil {
+			operator = assertExists
+		}
+	}
+
+	switch operator {
+	case assertExists:
+		if !found {
+			return false, "value does not exist"
+		}
+		return true, ""
+	case assertNotExists:
+		if found {
+			return false, fmt.Sprintf("value exists: %v", describeValue(actual))
+		}
+		return true, ""
+	}
+
+	if !found {
+		return false, "value does not exist"
+	}
+
+	switch operator {
+	case assertEquals:
+		if valuesEqual(actual, assertion.Expected) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("expected %s, got %s", describeValue(assertion.Expected), describeValue(actual))
+	case assertNotEquals:
+		if !valuesEqual(actual, assertion.Expected) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("expected a value other than %s", describeValue(assertion.Expected))
+	case assertContains, assertNotContains:
+		contains := valueContains(actual, assertion.Expected)
+		if contains == (operator == assertContains) {
+			return true, ""
+		}
+		if contains {
+			return false, fmt.Sprintf("%s contains %s", describeValue(actual), describeValue(assertion.Expected))
+		}
+		return false, fmt.Sprintf("%s does not contain %s", describeValue(actual), describeValue(assertion.Expected))
+	case assertMatches:
+		pattern := formatFieldValue(assertion.Expected)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Sprintf("invalid pattern %q: %v", pattern, err)
+		}
+		if re.MatchString(formatFieldValue(actual)) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("%s does not match %q", describeValue(actual), pattern)
+	case assertGreaterThan, assertLessThan:
+		actualNumber, ok := toFloat(actual)
+		expectedNumber, expectedOk := toFloat(assertion.Expected)
+		if !ok || !expectedOk {
+			return false, fmt.Sprintf("cannot compare %s with %s as numbers", describeValue(actual), describeValue(assertion.Expected))
+		}
+		if (operator == assertGreaterThan && actualNumber > expectedNumber) || (operator == assertLessThan && actualNumber < expectedNumber) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("expected %s %v, got %v", operator, expectedNumber, actualNumber)
+	default:
+		return false, fmt.Sprintf("unknown operator %q", operator)
+	}
+}
+
+// statusMatches compares a status by code ("5", "200") or name ("NOT_FOUND", "200 OK")
+func statusMatches(result *models.CallResult, expected string) bool {
+	if code, err := strconv.Atoi(expected); err == nil {
+		return result.StatusCode == code
+	}
+	return strings.EqualFold(result.Status, expected) || strings.EqualFold(strings.TrimPrefix(result.Status, strconv.Itoa(result.StatusCode)+" "), expected)
+}
+
+// valuesEqual compares JSON values; numbers and strings holding the same number are equal,
+// since protobuf JSON encodes 64-bit integers as strings
+func valuesEqual(actual, expected interface{}) bool {
+	if actualNumber, ok := toFloat(actual); ok {
+		if expectedNumber, ok := toFloat(expected); ok {
+			return actualNumber == expectedNumber
+		}
+	}
+	switch actual.(type) {
+	case map[string]interface{}, []interface{}:
+		return reflect.DeepEqual(actual, expected)
+	}
+	if expected == nil || actual == nil {
+		return actual == expected
+	}
+	return formatFieldValue(actual) == formatFieldValue(expected)
+}
+
+// valueContains checks for a substring in strings and an element in arrays
+func valueContains(actual, expected interface{}) bool {
+	if list, ok := actual.([]interface{}); ok {
+		for _, item := range list {
+			if valuesEqual(item, expected) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(formatFieldValue(actual), formatFieldValue(expected))
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func describeValue(value interface{}) string {
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(jsonBytes)
+}
+
+func operatorOrDefault(operator, fallback string) string {
+	if operator == "" {
+		return fallback
+	}
+	return operator
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"encoding/json"
+	"grpc-client/models"
+	"testing"
+)
+
+func TestEvaluateAssertion(t *testing.T) {
+	result := &models.CallResult{
+		Protocol:   models.ProtocolGRPC,
+		Status:     "OK",
+		StatusCode: 0,
+		Headers:    map[string][]string{"content-type": {"application/grpc"}},
+		Trailers:   map[string][]string{"x-trace": {"a", "b"}},
+		Body: map[string]interface{}{
+			"id":    "9007199254740993",
+			"count": float64(3),
+			"name":  "widget",
+			"tags":  []interface{}{"red", "blue"},
+		},
+		DurationMs: 120,
+	}
+
+	tests := []struct {
+		name       string
+		assertion  models.Assertion
+		wantPassed bool
+	}{
+		{name: "status by name", assertion: models.Assertion{Type: models.AssertionStatus, Expected: "OK"}, wantPassed: true},
+		{name: "status by code", assertion: models.Assertion{Type: models.AssertionStatus, Expected: float64(0)}, wantPassed: true},
+		{name: "status not equals", assertion: models.Assertion{Type: models.AssertionStatus, Operator: assertNotEquals, Expected: "NOT_FOUND"}, wantPassed: true},
+		{name: "status mismatch", assertion: models.Assertion{Type: models.AssertionStatus, Expected: "NOT_FOUND"}, wantPassed: false},
+		{name: "path equals", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.name", Expected: "widget"}, wantPassed: true},
+		{name: "path equals number as string", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.count", Expected: "3"}, wantPassed: true},
+		{name: "path without expected checks existence", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.name"}, wantPassed: true},
+		{name: "path missing", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.missing"}, wantPassed: false},
+		{name: "path not exists", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.missing", Operator: assertNotExists}, wantPassed: true},
+		{name: "path contains element", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.tags", Operator: assertContains, Expected: "blue"}, wantPassed: true},
+		{name: "path not contains", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.name", Operator: assertNotContains, Expected: "gadget"}, wantPassed: true},
+		{name: "path matches", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.name", Operator: assertMatches, Expected: "^wid"}, wantPassed: true},
+		{name: "path invalid pattern", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.name", Operator: assertMatches, Expected: "("}, wantPassed: false},
+		{name: "path greater than", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.count", Operator: assertGreaterThan, Expected: float64(2)}, wantPassed: true},
+		{name: "path less than", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.count", Operator: assertLessThan, Expected: float64(2)}, wantPassed: false},
+		{name: "path compares non numbers", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.name", Operator: assertGreaterThan, Expected: float64(2)}, wantPassed: false},
+		{name: "path with invalid syntax", assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.tags[", Expected: "red"}, wantPassed: false},
+		{name: "header present", assertion: models.Assertion{Type: models.AssertionHeader, Path: "Content-Type"}, wantPassed: true},
+		{name: "header missing", assertion: models.Assertion{Type: models.AssertionHeader, Path: "x-missing"}, wantPassed: false},
+		{name: "trailer joins values", assertion: models.Assertion{Type: models.AssertionTrailer, Path: "x-trace", Expected: "a, b"}, wantPassed: true},
+		{name: "latency under limit", assertion: models.Assertion{Type: models.AssertionLatency, Expected: float64(500)}, wantPassed: true},
+		{name: "latency over limit", assertion: models.Assertion{Type: models.AssertionLatency, Expected: float64(100)}, wantPassed: false},
+		{name: "latency invalid limit", assertion: models.Assertion{Type: models.AssertionLatency, Expected: "fast"}, wantPassed: false},
+		{name: "schema at path", assertion: models.Assertion{Type: models.AssertionSchema, Path: "$.tags", Schema: map[string]interface{}{"type": "array", "minItems": float64(2)}}, wantPassed: true},
+		{name: "schema violation", assertion: models.Assertion{Type: models.AssertionSchema, Schema: map[string]interface{}{"required": []interface{}{"missing"}}}, wantPassed: false},
+		{name: "unknown type", assertion: models.Assertion{Type: "body"}, wantPassed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateAssertion(tt.assertion, result)
+			if got.Passed != tt.wantPassed {
+				t.Errorf("evaluateAssertion() passed = %v, want %v (message %q)", got.Passed, tt.wantPassed, got.Message)
+			}
+			if !got.Passed && got.Message == "" {
+				t.Errorf("evaluateAssertion() failed without a message")
+			}
+		})
+	}
+}
+
+func TestAssertionsPassed(t *testing.T) {
+	failed := &models.CallResult{Protocol: models.ProtocolGRPC, Status: "NOT_FOUND", StatusCode: 5}
+	succeeded := &models.CallResult{Protocol: models.ProtocolGRPC, Status: "OK"}
+	statusAssertion := models.Assertion{Type: models.AssertionStatus, Expected: "NOT_FOUND"}
+
+	tests := []struct {
+		name       string
+		result     *models.CallResult
+		assertions []models.Assertion
+		want       bool
+	}{
+		{name: "successful call without assertions", result: succeeded, want: true},
+		{name: "failed call without assertions", result: failed, want: false},
+		{name: "failed call with expected status", result: failed, assertions: []models.Assertion{statusAssertion}, want: true},
+		{name: "disabled status assertion", result: failed, assertions: []models.Assertion{{Type: models.AssertionStatus, Expected: "NOT_FOUND", Disabled: true}}, want: false},
+		{name: "failing assertion", result: succeeded, assertions: []models.Assertion{statusAssertion}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := evaluateAssertions(tt.assertions, tt.result)
+			if got := assertionsPassed(tt.result, tt.assertions, results); got != tt.want {
+				t.Errorf("assertionsPassed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   *models.CallResult
+		expected string
+		want     bool
+	}{
+		{name: "gRPC name", result: &models.CallResult{Status: "NOT_FOUND", StatusCode: 5}, expected: "not_found", want: true},
+		{name: "gRPC code", result: &models.CallResult{Status: "NOT_FOUND", StatusCode: 5}, expected: "5", want: true},
+		{name: "gRPC other code", result: &models.CallResult{Status: "NOT_FOUND", StatusCode: 5}, expected: "0", want: false},
+		{name: "HTTP code", result: &models.CallResult{Status: "404 Not Found", StatusCode: 404}, expected: "404", want: true},
+		{name: "HTTP full status", result: &models.CallResult{Status: "404 Not Found", StatusCode: 404}, expected: "404 Not Found", want: true},
+		{name: "HTTP reason", result: &models.CallResult{Status: "404 Not Found", StatusCode: 404}, expected: "not found", want: true},
+		{name: "HTTP other reason", result: &models.CallResult{Status: "404 Not Found", StatusCode: 404}, expected: "OK", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statusMatches(tt.result, tt.expected); got != tt.want {
+				t.Errorf("statusMatches(%q, %q) = %v, want %v", tt.result.Status, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValuesEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   interface{}
+		expected interface{}
+		want     bool
+	}{
+		{name: "same strings", actual: "a", expected: "a", want: true},
+		{name: "different strings", actual: "a", expected: "b", want: false},
+		{name: "number and numeric string", actual: "42", expected: float64(42), want: true},
+		{name: "json number", actual: json.Number("1.5"), expected: float64(1.5), want: true},
+		{name: "booleans", actual: true, expected: true, want: true},
+		{name: "boolean and string", actual: true, expected: "true", want: true},
+		{name: "nil and nil", actual: nil, expected: nil, want: true},
+		{name: "nil and empty string", actual: nil, expected: "", want: false},
+		{name: "equal objects", actual: map[string]interface{}{"a": float64(1)}, expected: map[string]interface{}{"a": float64(1)}, want: true},
+		{name: "different arrays", actual: []interface{}{"a"}, expected: []interface{}{"b"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := valuesEqual(tt.actual, tt.expected); got != tt.want {
+				t.Errorf("valuesEqual(%#v, %#v) = %v, want %v", tt.actual, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
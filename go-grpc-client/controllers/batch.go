@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"grpc-client/models"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	defaultBatchConcurrency = 10
+	maxBatchConcurrency     = 100
+	maxBatchRequests        = 1000
+)
+
+// batchSession shares connections and method descriptors between the native gRPC calls of a
+// batch, so each host is connected and each method reflected once. Failed lookups are not
+// kept, so a retried call tries again.
+type batchSession struct {
+	executor *CallExecutor
+	headers  http.Header
+
+	mu      sync.Mutex
+	conns   map[string]*batchConnection
+	methods map[string]*batchMethod
+}
+
+type batchConnection struct {
+	mu   sync.Mutex
+	conn *grpc.ClientConn
+}
+
+type batchMethod struct {
+	mu         sync.Mutex
+	methodDesc *desc.MethodDescriptor
+}
+
+// ExecuteBatch executes resolved gRPC requests with at most concurrency calls in flight and
+// returns their results in request order. Native gRPC calls to the same host share one
+// connection; Connect and transcoded calls are executed as single calls.
+func (ce *CallExecutor) ExecuteBatch(ctx context.Context, requests []models.GrpcRequest, concurrency int, headers http.Header) []*models.CallResult {
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if concurrency > maxBatchConcurrency {
+		concurrency = maxBatchConcurrency
+	}
+
+	session := &batchSession{
+		executor: ce,
+		headers:  headers,
+		conns:    make(map[string]*batchConnection),
+		methods:  make(map[string]*batchMethod),
+	}
+	defer session.Close()
+
+	results := make([]*models.CallResult, len(requests))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i] = executeWithRetry(ctx, requests[i].Retry, func() *models.CallResult {
+				return session.executeAttempt(ctx, requests[i])
+			})
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// executeAttempt makes a single attempt of a call of the batch
+func (bs *batchSession) executeAttempt(ctx context.Context, grpcRequest models.GrpcRequest) *models.CallResult {
+	if grpcRequest.Protocol != "" && grpcRequest.Protocol != models.ProtocolGRPC {
+		return bs.executor.executeGrpcAttempt(ctx, grpcRequest, bs.headers)
+	}
+
+	startedAt := time.Now()
+	result := &models.CallResult{
+		Protocol:  models.ProtocolGRPC,
+		StartedAt: startedAt,
+	}
+	defer func() {
+		result.DurationMs = time.Since(startedAt).Milliseconds()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, defaultCallTimeout)
+	defer cancel()
+
+	conn, err := bs.connection(grpcRequest.Host)
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to connect: %v", err)
+		result.Status = grpcStatusNames[codes.Unavailable]
+		result.StatusCode = int(codes.Unavailable)
+		return result
+	}
+
+	methodDesc, err := bs.method(conn, grpcRequest.Host, grpcRequest.Method)
+	if err != nil {
+		setCallError(result, fmt.Errorf("method descriptor error: %v", err), "gRPC call failed")
+		return result
+	}
+
+	body, err := bs.executor.invokeGrpcMethod(ctx, conn, methodDesc, grpcRequest, bs.headers, result)
+	if err != nil {
+		setCallError(result, err, "gRPC call failed")
+		return result
+	}
+	setCallSuccess(result, body)
+	return result
+}
+
+// connection returns the shared connection to a host, connecting on first use
+func (bs *batchSession) connection(host string) (*grpc.ClientConn, error) {
+	bs.mu.Lock()
+	entry, ok := bs.conns[host]
+	if !ok {
+		entry = &batchConnection{}
+		bs.conns[host] = entry
+	}
+	bs.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.conn == nil {
+		conn, err := bs.executor.createConnection(host)
+		if err != nil {
+			return nil, err
+		}
+		entry.conn = conn
+	}
+	return entry.conn, nil
+}
+
+// method returns the descriptor of a method on a host, reflecting it on first use
+func (bs *batchSession) method(conn *grpc.ClientConn, host, methodName string) (*desc.MethodDescriptor, error) {
+	key := host + " " + methodName
+	bs.mu.Lock()
+	entry, ok := bs.methods[key]
+	if !ok {
+		entry = &batchMethod{}
+		bs.methods[key] = entry
+	}
+	bs.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.methodDesc == nil {
+		methodDesc, err := bs.executor.getMethodDescriptor(conn, methodName)
+		if err != nil {
+			return nil, err
+		}
+		entry.methodDesc = methodDesc
+	}
+	return entry.methodDesc, nil
+}
+
+// Close closes the shared connections
+func (bs *batchSession) Close() {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	for _, entry := range bs.conns {
+		if entry.conn != nil {
+			entry.conn.Close()
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"grpc-client/models"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExecuteBatch(t *testing.T) {
+	first, firstAccepted := startTestGrpcServer(t)
+	second, secondAccepted := startTestGrpcServer(t)
+
+	// Each request asks for a different status, so a result in the wrong place shows up
+	services := []string{"", "NOT_FOUND", "ALREADY_EXISTS", "PERMISSION_DENIED", "UNAVAILABLE", "INTERNAL"}
+	var requests []models.GrpcRequest
+	var want []string
+	for i := 0; i < 24; i++ {
+		host := first
+		if i%2 == 1 {
+			host = second
+		}
+		service := services[i%len(services)]
+		requests = append(requests, models.GrpcRequest{
+			Host:    host,
+			Method:  "grpc.health.v1.Health.Check",
+			Message: map[string]interface{}{"service": service},
+		})
+		if service == "" {
+			service = "OK"
+		}
+		want = append(want, service)
+	}
+	requests = append(requests, models.GrpcRequest{Host: first, Method: "grpc.health.v1.Health.Missing"})
+
+	results := NewCallExecutor().ExecuteBatch(context.Background(), requests, 8, http.Header{})
+	if len(results) != len(requests) {
+		t.Fatalf("batch returned %d results for %d requests", len(results), len(requests))
+	}
+	for i, status := range want {
+		if results[i].Status != status {
+			t.Errorf("result %d status = %s, want %s (error %q)", i, results[i].Status, status, results[i].Error)
+		}
+	}
+	if last := results[len(results)-1]; last.Succeeded() || last.Error == "" {
+		t.Errorf("unknown method result = %+v, want an error", last)
+	}
+
+	// Calls to the same host share one connection
+	if got := atomic.LoadInt32(firstAccepted); got != 1 {
+		t.Errorf("first server accepted %d connections, want 1", got)
+	}
+	if got := atomic.LoadInt32(secondAccepted); got != 1 {
+		t.Errorf("second server accepted %d connections, want 1", got)
+	}
+}
+
+func TestExecuteBatchUnreachableHost(t *testing.T) {
+	address, _ := startTestGrpcServer(t)
+	requests := []models.GrpcRequest{
+		{Host: "127.0.0.1:1", Method: "grpc.health.v1.Health.Check"},
+		{Host: address, Method: "grpc.health.v1.Health.Check", Message: map[string]interface{}{}},
+	}
+
+	results := NewCallExecutor().ExecuteBatch(context.Background(), requests, 0, http.Header{})
+	if results[0].Status != "UNAVAILABLE" || results[0].Error == "" {
+		t.Errorf("unreachable host result = %+v, want UNAVAILABLE", results[0])
+	}
+	if results[1].Status != "OK" {
+		t.Errorf("second result status = %s, want OK", results[1].Status)
+	}
+}
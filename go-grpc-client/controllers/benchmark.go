@@ -0,0 +1,342 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"grpc-client/models"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultBenchmarkConcurrency = 10
+	defaultBenchmarkRequests    = 200
+	maxBenchmarkConcurrency     = 1000
+	maxBenchmarkRPS             = 100000
+	benchmarkHistogramBuckets   = 10
+	maxBenchmarkErrorMessages   = 20
+	benchmarkProgressInterval   = time.Second
+)
+
+// preparedGrpcCall is a gRPC call whose method descriptor, request message and metadata
+// are resolved once, so it can be invoked repeatedly over a pool of connections
+type preparedGrpcCall struct {
+	conns          []*grpc.ClientConn
+	methodDesc     *desc.MethodDescriptor
+	fullMethodName string
+	request        *dynamic.Message
+	md             metadata.MD
+}
+
+// prepareGrpcCall opens the connections and resolves the method of a native gRPC request
+func (ce *CallExecutor) prepareGrpcCall(grpcRequest models.GrpcRequest, connections int) (*preparedGrpcCall, error) {
+	if grpcRequest.Protocol != "" && grpcRequest.Protocol != models.ProtocolGRPC {
+		return nil, fmt.Errorf("benchmarks support the native gRPC protocol only, not %s", protocolName(grpcRequest.Protocol))
+	}
+
+	prepared := &preparedGrpcCall{}
+	for i := 0; i < connections; i++ {
+		conn, err := ce.createConnection(grpcRequest.Host)
+		if err != nil {
+			prepared.Close()
+			return nil, fmt.Errorf("failed to connect: %v", err)
+		}
+		prepared.conns = append(prepared.conns, conn)
+	}
+
+	methodDesc, err := ce.getMethodDescriptor(prepared.conns[0], grpcRequest.Method)
+	if err != nil {
+		prepared.Close()
+		return nil, fmt.Errorf("method descriptor error: %v", err)
+	}
+	if methodDesc.IsClientStreaming() || methodDesc.IsServerStreaming() {
+		prepared.Close()
+		return nil, fmt.Errorf("benchmarks support unary methods only")
+	}
+
+	request, err := parseDynamicMessage(methodDesc.GetInputType(), grpcRequest.Message)
+	if err != nil {
+		prepared.Close()
+		return nil, fmt.Errorf("failed to parse request message: %v", err)
+	}
+
+	prepared.methodDesc = methodDesc
+	prepared.fullMethodName = fmt.Sprintf("/%s/%s", methodDesc.GetService().GetFullyQualifiedName(), methodDesc.GetName())
+	prepared.request = request
+	prepared.md = ce.createMetadata(grpcRequest, http.Header{})
+	return prepared, nil
+}
+
+// invoke sends the call over the connection picked by n (round-robin)
+func (pc *preparedGrpcCall) invoke(ctx context.Context, n int) error {
+	ctx, cancel := context.WithTimeout(metadata.NewOutgoingContext(ctx, pc.md), defaultCallTimeout)
+	defer cancel()
+
+	conn := pc.conns[n%len(pc.conns)]
+	return conn.Invoke(ctx, pc.fullMethodName, pc.request, dynamic.NewMessage(pc.methodDesc.GetOutputType()))
+}
+
+func (pc *preparedGrpcCall) Close() {
+	for _, conn := range pc.conns {
+		conn.Close()
+	}
+}
+
+// benchmarkSample is the outcome of one benchmark call
+type benchmarkSample struct {
+	latency time.Duration
+	code    codes.Code
+	message string
+}
+
+// Benchmark fires a gRPC request with the given concurrency until the request count or
+// duration is reached, optionally limited to a target rate, and summarizes the latencies.
+// progress, if set, is called every second with the number of completed and failed calls.
+func (ce *CallExecutor) Benchmark(ctx context.Context, grpcRequest models.GrpcRequest, options models.BenchmarkRequest, progress func(completed, failed int)) (*models.BenchmarkResult, error) {
+	if err := validateBenchmarkOptions(options); err != nil {
+		return nil, err
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultBenchmarkConcurrency
+	}
+	if options.TotalRequests == 0 && options.DurationMs == 0 {
+		options.TotalRequests = defaultBenchmarkRequests
+	}
+	if options.Connections <= 0 {
+		options.Connections = 1
+	}
+	if options.Connections > options.Concurrency {
+		options.Connections = options.Concurrency
+	}
+
+	prepared, err := ce.prepareGrpcCall(grpcRequest, options.Connections)
+	if err != nil {
+		return nil, err
+	}
+	defer prepared.Close()
+
+	log.Printf("Benchmarking %s on %s: concurrency %d, %d connections, %d requests, %dms, %d rps",
+		grpcRequest.Method, grpcRequest.Host, options.Concurrency, options.Connections, options.TotalRequests, options.DurationMs, options.RPS)
+
+	// Dispatching stops at the deadline; calls already sent may still complete
+	dispatchCtx := ctx
+	if options.DurationMs > 0 {
+		var cancel context.CancelFunc
+		dispatchCtx, cancel = context.WithTimeout(ctx, time.Duration(options.DurationMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	startedAt := time.Now()
+	jobs := make(chan int)
+	go dispatchBenchmarkCalls(dispatchCtx, jobs, options.TotalRequests, options.RPS)
+
+	// Report progress until the last call completed
+	var completed, failed int64
+	progressDone := make(chan struct{})
+	var progressWg sync.WaitGroup
+	if progress != nil {
+		progressWg.Add(1)
+		go func() {
+			defer progressWg.Done()
+			ticker := time.NewTicker(benchmarkProgressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					progress(int(atomic.LoadInt64(&completed)), int(atomic.LoadInt64(&failed)))
+				case <-progressDone:
+					return
+				}
+			}
+		}()
+	}
+
+	samples := make([][]benchmarkSample, options.Concurrency)
+	var wg sync.WaitGroup
+	for worker := 0; worker < options.Concurrency; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for n := range jobs {
+				callStarted := time.Now()
+				err := prepared.invoke(ctx, n)
+				sample := benchmarkSample{latency: time.Since(callStarted)}
+				if err != nil {
+					st, _ := status.FromError(err)
+					sample.code = st.Code()
+					sample.message = st.Message()
+					atomic.AddInt64(&failed, 1)
+				}
+				atomic.AddInt64(&completed, 1)
+				samples[worker] = append(samples[worker], sample)
+			}
+		}(worker)
+	}
+	wg.Wait()
+	close(progressDone)
+	progressWg.Wait()
+
+	result := summarizeBenchmark(samples, time.Since(startedAt))
+	result.Host = grpcRequest.Host
+	result.Method = grpcRequest.Method
+	result.Concurrency = options.Concurrency
+	result.Connections = options.Connections
+	result.TargetRPS = options.RPS
+	result.Stopped = ctx.Err() != nil
+	result.StartedAt = startedAt
+
+	log.Printf("Benchmark of %s finished: %d calls, %d failed, %.1f calls/s", grpcRequest.Method, result.Total, result.Failed, result.Throughput)
+	return result, nil
+}
+
+// validateBenchmarkOptions checks the load settings of a benchmark
+func validateBenchmarkOptions(options models.BenchmarkRequest) error {
+	if options.Concurrency > maxBenchmarkConcurrency {
+		return fmt.Errorf("concurrency must not exceed %d", maxBenchmarkConcurrency)
+	}
+	if options.RPS > maxBenchmarkRPS {
+		return fmt.Errorf("rps must not exceed %d", maxBenchmarkRPS)
+	}
+	if options.TotalRequests < 0 || options.DurationMs < 0 || options.RPS < 0 {
+		return fmt.Errorf("totalRequests, durationMs and rps must not be negative")
+	}
+	return nil
+}
+
+// dispatchBenchmarkCalls hands out call numbers until total is reached (0 is unlimited)
+// or ctx is done, at most rps per second when rps is set
+func dispatchBenchmarkCalls(ctx context.Context, jobs chan<- int, total, rps int) {
+	defer close(jobs)
+
+	var tick <-chan time.Time
+	if rps > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(rps))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for n := 0; total == 0 || n < total; n++ {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case jobs <- n:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// summarizeBenchmark computes counts, throughput, latency percentiles and the histogram
+func summarizeBenchmark(workerSamples [][]benchmarkSample, elapsed time.Duration) *models.BenchmarkResult {
+	result := &models.BenchmarkResult{
+		StatusCodes: make(map[string]int),
+		Histogram:   []models.HistogramBucket{},
+		DurationMs:  elapsed.Milliseconds(),
+	}
+
+	var latencies []float64
+	for _, samples := range workerSamples {
+		for _, sample := range samples {
+			latencies = append(latencies, float64(sample.latency)/float64(time.Millisecond))
+			result.StatusCodes[grpcStatusNames[sample.code]]++
+			if sample.code == codes.OK {
+				result.Succeeded++
+				continue
+			}
+			result.Failed++
+			if result.Errors == nil {
+				result.Errors = make(map[string]int)
+			}
+			message := fmt.Sprintf("%s: %s", grpcStatusNames[sample.code], sample.message)
+			if _, seen := result.Errors[message]; !seen && len(result.Errors) >= maxBenchmarkErrorMessages {
+				message = "other errors"
+			}
+			result.Errors[message]++
+		}
+	}
+
+	result.Total = len(latencies)
+	if result.Total == 0 {
+		return result
+	}
+	if elapsed > 0 {
+		result.Throughput = roundMetric(float64(result.Total) / elapsed.Seconds())
+	}
+
+	sort.Float64s(latencies)
+	result.Latency = summarizeLatencies(latencies)
+	result.Histogram = latencyHistogram(latencies)
+	return result
+}
+
+// summarizeLatencies computes the statistics of sorted, non-empty latencies in milliseconds
+func summarizeLatencies(sorted []float64) models.LatencySummary {
+	sum := 0.0
+	for _, latency := range sorted {
+		sum += latency
+	}
+	return models.LatencySummary{
+		Min:  roundMetric(sorted[0]),
+		Mean: roundMetric(sum / float64(len(sorted))),
+		P50:  roundMetric(percentile(sorted, 50)),
+		P90:  roundMetric(percentile(sorted, 90)),
+		P99:  roundMetric(percentile(sorted, 99)),
+		Max:  roundMetric(sorted[len(sorted)-1]),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// latencyHistogram spreads sorted latencies over equally wide buckets from the fastest to the slowest call
+func latencyHistogram(sorted []float64) []models.HistogramBucket {
+	minimum, maximum := sorted[0], sorted[len(sorted)-1]
+	width := (maximum - minimum) / benchmarkHistogramBuckets
+	if width == 0 {
+		return []models.HistogramBucket{{UpperMs: roundMetric(maximum), Count: len(sorted), Frequency: 1}}
+	}
+
+	buckets := make([]models.HistogramBucket, benchmarkHistogramBuckets)
+	for i := range buckets {
+		buckets[i].UpperMs = roundMetric(minimum + width*float64(i+1))
+	}
+	for _, latency := range sorted {
+		index := int((latency - minimum) / width)
+		if index >= benchmarkHistogramBuckets {
+			index = benchmarkHistogramBuckets - 1
+		}
+		buckets[index].Count++
+	}
+	for i := range buckets {
+		buckets[i].Frequency = roundMetric(float64(buckets[i].Count) / float64(len(sorted)))
+	}
+	return buckets
+}
+
+// roundMetric rounds a latency, rate or share to three decimals
+func roundMetric(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
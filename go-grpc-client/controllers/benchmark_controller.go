@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"fmt"
+	"grpc-client/models"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BenchmarkController runs load tests against gRPC methods
+type BenchmarkController struct {
+	executor *CallExecutor
+	runner   *RequestRunner
+}
+
+func NewBenchmarkController(executor *CallExecutor, runner *RequestRunner) *BenchmarkController {
+	return &BenchmarkController{
+		executor: executor,
+		runner:   runner,
+	}
+}
+
+// RunBenchmark fires an inline or saved gRPC request with the configured load and returns
+// throughput, status breakdown and latency statistics. Variables are resolved once, so
+// template functions such as {{$uuid}} produce the same value for every call.
+func (bc *BenchmarkController) RunBenchmark(c *gin.Context) {
+	var benchmarkRequest models.BenchmarkRequest
+	if err := c.ShouldBindJSON(&benchmarkRequest); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if err := validateBenchmarkOptions(benchmarkRequest); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	grpcRequest, undefined, err := bc.runner.ResolveBenchmarkRequest(benchmarkRequest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if len(undefined) > 0 {
+		log.Printf("Warning: undefined variables in benchmark request: %s", strings.Join(undefined, ", "))
+	}
+
+	result, err := bc.executor.Benchmark(c.Request.Context(), grpcRequest, benchmarkRequest, nil)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"grpc-client/models"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{p: 0, want: 1},
+		{p: 10, want: 1},
+		{p: 11, want: 2},
+		{p: 50, want: 5},
+		{p: 90, want: 9},
+		{p: 99, want: 10},
+		{p: 100, want: 10},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile([]float64{7}, 99); got != 7 {
+		t.Errorf("percentile of a single value = %v, want 7", got)
+	}
+}
+
+func TestSummarizeLatencies(t *testing.T) {
+	sorted := make([]float64, 100)
+	for i := range sorted {
+		sorted[i] = float64(i + 1)
+	}
+	sorted[99] = 1000.12345
+
+	want := models.LatencySummary{Min: 1, Mean: 59.501, P50: 50, P90: 90, P99: 99, Max: 1000.123}
+	if got := summarizeLatencies(sorted); got != want {
+		t.Errorf("summarizeLatencies() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	got := latencyHistogram([]float64{10, 11, 12, 15, 20, 29.9, 30})
+	if len(got) != benchmarkHistogramBuckets {
+		t.Fatalf("histogram has %d buckets, want %d", len(got), benchmarkHistogramBuckets)
+	}
+	wantCounts := []int{2, 1, 1, 0, 0, 1, 0, 0, 0, 2}
+	total := 0
+	for i, bucket := range got {
+		if bucket.Count != wantCounts[i] {
+			t.Errorf("bucket %d (up to %vms) holds %d calls, want %d", i, bucket.UpperMs, bucket.Count, wantCounts[i])
+		}
+		total += bucket.Count
+	}
+	if total != 7 {
+		t.Errorf("histogram holds %d calls, want 7", total)
+	}
+	if got[0].UpperMs != 12 || got[9].UpperMs != 30 || got[0].Frequency != 0.286 {
+		t.Errorf("first bucket = %+v, last bucket = %+v", got[0], got[9])
+	}
+
+	same := latencyHistogram([]float64{5, 5, 5})
+	if want := []models.HistogramBucket{{UpperMs: 5, Count: 3, Frequency: 1}}; !reflect.DeepEqual(same, want) {
+		t.Errorf("histogram of equal latencies = %+v, want %+v", same, want)
+	}
+}
+
+func TestSummarizeBenchmark(t *testing.T) {
+	samples := [][]benchmarkSample{
+		{{latency: 2 * time.Millisecond}, {latency: 4 * time.Millisecond}},
+		{{latency: 6 * time.Millisecond, code: codes.Unavailable, message: "down"}},
+		{},
+	}
+	for i := 0; i < maxBenchmarkErrorMessages+2; i++ {
+		samples[2] = append(samples[2], benchmarkSample{latency: time.Millisecond, code: codes.Internal, message: fmt.Sprintf("failure %d", i)})
+	}
+
+	result := summarizeBenchmark(samples, 2*time.Second)
+	total := 3 + maxBenchmarkErrorMessages + 2
+	if result.Total != total || result.Succeeded != 2 || result.Failed != total-2 {
+		t.Errorf("total = %d, succeeded = %d, failed = %d", result.Total, result.Succeeded, result.Failed)
+	}
+	if result.Throughput != float64(total)/2 {
+		t.Errorf("throughput = %v, want %v", result.Throughput, float64(total)/2)
+	}
+	wantCodes := map[string]int{"OK": 2, "UNAVAILABLE": 1, "INTERNAL": maxBenchmarkErrorMessages + 2}
+	if !reflect.DeepEqual(result.StatusCodes, wantCodes) {
+		t.Errorf("status codes = %v, want %v", result.StatusCodes, wantCodes)
+	}
+	if len(result.Errors) != maxBenchmarkErrorMessages+1 || result.Errors["UNAVAILABLE: down"] != 1 || result.Errors["other errors"] != 3 {
+		t.Errorf("errors = %v, want %d distinct messages and the rest as other errors", result.Errors, maxBenchmarkErrorMessages)
+	}
+	if result.Latency.Min != 1 || result.Latency.Max != 6 {
+		t.Errorf("latency = %+v", result.Latency)
+	}
+
+	empty := summarizeBenchmark([][]benchmarkSample{{}}, time.Second)
+	if empty.Total != 0 || empty.Histogram == nil || empty.Throughput != 0 {
+		t.Errorf("empty summary = %+v", empty)
+	}
+}
+
+func TestValidateBenchmarkOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options models.BenchmarkRequest
+		wantErr bool
+	}{
+		{name: "defaults", options: models.BenchmarkRequest{}},
+		{name: "limits", options: models.BenchmarkRequest{Concurrency: maxBenchmarkConcurrency, RPS: maxBenchmarkRPS}},
+		{name: "too many workers", options: models.BenchmarkRequest{Concurrency: maxBenchmarkConcurrency + 1}, wantErr: true},
+		{name: "rate too high", options: models.BenchmarkRequest{RPS: maxBenchmarkRPS + 1}, wantErr: true},
+		{name: "negative duration", options: models.BenchmarkRequest{DurationMs: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateBenchmarkOptions(tt.options); (err != nil) != tt.wantErr {
+				t.Errorf("validateBenchmarkOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDispatchBenchmarkCalls(t *testing.T) {
+	jobs := make(chan int)
+	go dispatchBenchmarkCalls(context.Background(), jobs, 5, 0)
+	var got []int
+	for n := range jobs {
+		got = append(got, n)
+	}
+	if !reflect.DeepEqual(got, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("dispatched %v, want 0 to 4", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	jobs = make(chan int)
+	go dispatchBenchmarkCalls(ctx, jobs, 0, 100)
+	count := 0
+	for range jobs {
+		count++
+	}
+	if count == 0 || count > 10 {
+		t.Errorf("dispatched %d calls in 50ms at 100 rps", count)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	address, accepted := startTestGrpcServer(t)
+	executor := NewCallExecutor()
+
+	request := models.GrpcRequest{Host: address, Method: "grpc.health.v1.Health.Check", Message: map[string]interface{}{}}
+	result, err := executor.Benchmark(context.Background(), request, models.BenchmarkRequest{Concurrency: 4, TotalRequests: 40, Connections: 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 40 || result.Succeeded != 40 || result.StatusCodes["OK"] != 40 {
+		t.Errorf("result = %+v, want 40 successful calls", result)
+	}
+	if result.Concurrency != 4 || result.Connections != 2 || result.Stopped {
+		t.Errorf("concurrency = %d, connections = %d, stopped = %v", result.Concurrency, result.Connections, result.Stopped)
+	}
+	if got := atomic.LoadInt32(accepted); got != 2 {
+		t.Errorf("server accepted %d connections, want 2", got)
+	}
+
+	request.Message = map[string]interface{}{"service": "UNAVAILABLE"}
+	result, err = executor.Benchmark(context.Background(), request, models.BenchmarkRequest{Concurrency: 2, TotalRequests: 6, Connections: 5}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Failed != 6 || result.Errors["UNAVAILABLE: checked UNAVAILABLE"] != 6 || result.Connections != 2 {
+		t.Errorf("result = %+v, want 6 failed calls over 2 connections", result)
+	}
+
+	if _, err := executor.Benchmark(context.Background(), models.GrpcRequest{Host: address, Method: "grpc.health.v1.Health.Watch"}, models.BenchmarkRequest{}, nil); err == nil {
+		t.Error("benchmarking a streaming method should fail")
+	}
+}
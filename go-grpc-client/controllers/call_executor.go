@@ -0,0 +1,444 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"grpc-client/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	reflectpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const defaultCallTimeout = 30 * time.Second
+
+// grpcStatusNames maps gRPC codes to their canonical names
+var grpcStatusNames = map[codes.Code]string{
+	codes.OK:                 "OK",
+	codes.Canceled:           "CANCELLED",
+	codes.Unknown:            "UNKNOWN",
+	codes.InvalidArgument:    "INVALID_ARGUMENT",
+	codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+	codes.NotFound:           "NOT_FOUND",
+	codes.AlreadyExists:      "ALREADY_EXISTS",
+	codes.PermissionDenied:   "PERMISSION_DENIED",
+	codes.ResourceExhausted:  "RESOURCE_EXHAUSTED",
+	codes.FailedPrecondition: "FAILED_PRECONDITION",
+	codes.Aborted:            "ABORTED",
+	codes.OutOfRange:         "OUT_OF_RANGE",
+	codes.Unimplemented:      "UNIMPLEMENTED",
+	codes.Internal:           "INTERNAL",
+	codes.Unavailable:        "UNAVAILABLE",
+	codes.DataLoss:           "DATA_LOSS",
+	codes.Unauthenticated:    "UNAUTHENTICATED",
+}
+
+// CallExecutor executes gRPC, Connect and REST calls and wraps their outcome in a CallResult
+type CallExecutor struct{}
+
+func NewCallExecutor() *CallExecutor {
+	return &CallExecutor{}
+}
+
+// ExecuteGrpc executes a gRPC method using the protocol selected in the request, retrying
+// it as the request's retry policy allows
+func (ce *CallExecutor) ExecuteGrpc(ctx context.Context, grpcRequest models.GrpcRequest, headers http.Header) *models.CallResult {
+	return executeWithRetry(ctx, grpcRequest.Retry, func() *models.CallResult {
+		return ce.executeGrpcAttempt(ctx, grpcRequest, headers)
+	})
+}
+
+// executeGrpcAttempt makes a single attempt of a gRPC call
+func (ce *CallExecutor) executeGrpcAttempt(ctx context.Context, grpcRequest models.GrpcRequest, headers http.Header) *models.CallResult {
+	startedAt := time.Now()
+	result := &models.CallResult{
+		Protocol:  models.ProtocolGRPC,
+		StartedAt: startedAt,
+	}
+	defer func() {
+		result.DurationMs = time.Since(startedAt).Milliseconds()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, defaultCallTimeout)
+	defer cancel()
+
+	if grpcRequest.Protocol == models.ProtocolConnect {
+		result.Protocol = models.ProtocolConnect
+		body, err := ce.executeConnectCall(ctx, grpcRequest, headers, result)
+		if err != nil {
+			setCallError(result, err, "Connect call failed")
+			return result
+		}
+		setCallSuccess(result, body)
+		return result
+	}
+
+	if grpcRequest.Protocol == models.ProtocolTranscoding {
+		*result = *ce.executeTranscodedCall(ctx, grpcRequest, headers)
+		result.StartedAt = startedAt
+		return result
+	}
+
+	// Create gRPC connection
+	conn, err := ce.createConnection(grpcRequest.Host)
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to connect: %v", err)
+		result.Status = grpcStatusNames[codes.Unavailable]
+		result.StatusCode = int(codes.Unavailable)
+		return result
+	}
+	defer conn.Close()
+
+	body, err := ce.executeGrpcCall(ctx, conn, grpcRequest, headers, result)
+	if err != nil {
+		setCallError(result, err, "gRPC call failed")
+		return result
+	}
+	setCallSuccess(result, body)
+	return result
+}
+
+func setCallSuccess(result *models.CallResult, body interface{}) {
+	result.Status = grpcStatusNames[codes.OK]
+	result.StatusCode = int(codes.OK)
+	result.Body = body
+}
+
+// setCallError fills the status of a failed gRPC or Connect call from its error
+func setCallError(result *models.CallResult, err error, prefix string) {
+	result.Error = fmt.Sprintf("%s: %v", prefix, err)
+
+	var connectErr *ConnectError
+	if errors.As(err, &connectErr) {
+		code := connectCodeToGrpc(connectErr.Code)
+		result.Status = grpcStatusNames[code]
+		result.StatusCode = int(code)
+		result.ErrorDetails = connectErr.Details
+		return
+	}
+
+	st, _ := status.FromError(err)
+	result.Status = grpcStatusNames[st.Code()]
+	result.StatusCode = int(st.Code())
+	for _, detail := range st.Proto().GetDetails() {
+		decoded := map[string]interface{}{"type": strings.TrimPrefix(detail.GetTypeUrl(), "type.googleapis.com/")}
+		if msg, err := detail.UnmarshalNew(); err == nil {
+			if jsonBytes, err := protojson.Marshal(msg); err == nil {
+				var value interface{}
+				if json.Unmarshal(jsonBytes, &value) == nil {
+					decoded["value"] = value
+				}
+			}
+		}
+		result.ErrorDetails = append(result.ErrorDetails, decoded)
+	}
+}
+
+// connectCodeToGrpc maps a Connect error code (e.g. "not_found") to its gRPC code
+func connectCodeToGrpc(connectCode string) codes.Code {
+	name := strings.ToUpper(connectCode)
+	if name == "CANCELED" {
+		name = "CANCELLED"
+	}
+	for code, codeName := range grpcStatusNames {
+		if codeName == name {
+			return code
+		}
+	}
+	return codes.Unknown
+}
+
+func isSupportedProtocol(protocol models.CallProtocol) bool {
+	switch protocol {
+	case "", models.ProtocolGRPC, models.ProtocolConnect, models.ProtocolTranscoding:
+		return true
+	}
+	return false
+}
+
+func protocolName(protocol models.CallProtocol) string {
+	switch protocol {
+	case models.ProtocolConnect:
+		return "Connect"
+	case models.ProtocolTranscoding:
+		return "HTTP transcoding"
+	}
+	return "gRPC"
+}
+
+func (ce *CallExecutor) createConnection(host string) (*grpc.ClientConn, error) {
+	return CreateFlexibleConnection(host)
+}
+
+func (ce *CallExecutor) executeGrpcCall(ctx context.Context, conn *grpc.ClientConn, grpcRequest models.GrpcRequest, headers http.Header, result *models.CallResult) (interface{}, error) {
+	// Get method descriptor using reflection
+	methodDesc, err := ce.getMethodDescriptor(conn, grpcRequest.Method)
+	if err != nil {
+		return nil, fmt.Errorf("method descriptor error: %v", err)
+	}
+	return ce.invokeGrpcMethod(ctx, conn, methodDesc, grpcRequest, headers, result)
+}
+
+// invokeGrpcMethod builds the request message and metadata of a call to a resolved method and makes the call
+func (ce *CallExecutor) invokeGrpcMethod(ctx context.Context, conn *grpc.ClientConn, methodDesc *desc.MethodDescriptor, grpcRequest models.GrpcRequest, headers http.Header, result *models.CallResult) (interface{}, error) {
+	// Parse request message
+	requestMsg, err := parseDynamicMessage(methodDesc.GetInputType(), grpcRequest.Message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request message: %v", err)
+	}
+
+	// Create metadata
+	md := ce.createMetadata(grpcRequest, headers)
+
+	// Create context with metadata
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// Make the call
+	return ce.makeUnaryCall(ctx, conn, methodDesc, requestMsg, result)
+}
+
+func (ce *CallExecutor) getMethodDescriptor(conn *grpc.ClientConn, methodName string) (*desc.MethodDescriptor, error) {
+	// Parse method name: "addsvc.Add.Sum" -> service="addsvc.Add", method="Sum"
+	parts := strings.Split(methodName, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid method name format: %s", methodName)
+	}
+
+	serviceName := strings.Join(parts[:len(parts)-1], ".")
+	methodShortName := parts[len(parts)-1]
+
+	log.Printf("Looking for service: '%s', method: '%s'", serviceName, methodShortName)
+
+	// Create reflection client
+	refClient := grpcreflect.NewClient(context.Background(), reflectpb.NewServerReflectionClient(conn))
+	defer refClient.Reset()
+
+	// List all services
+	services, err := refClient.ListServices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %v", err)
+	}
+
+	log.Printf("Available services: %v", services)
+
+	// Find matching service name
+	actualServiceName := ce.findMatchingServiceName(services, serviceName)
+	if actualServiceName == "" {
+		return nil, fmt.Errorf("service '%s' not found. Available services: %v", serviceName, services)
+	}
+
+	log.Printf("Using actual service name: '%s'", actualServiceName)
+
+	// Get service descriptor
+	serviceDesc, err := refClient.ResolveService(actualServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve service '%s': %v", actualServiceName, err)
+	}
+
+	// Find method in service
+	for _, method := range serviceDesc.GetMethods() {
+		if strings.EqualFold(method.GetName(), methodShortName) {
+			log.Printf("Found method: %s", method.GetFullyQualifiedName())
+			return method, nil
+		}
+	}
+
+	return nil, fmt.Errorf("method '%s' not found in service '%s'", methodShortName, actualServiceName)
+}
+
+func (ce *CallExecutor) findMatchingServiceName(availableServices []string, requestedServiceName string) string {
+	// Try exact match first
+	for _, service := range availableServices {
+		if service == requestedServiceName {
+			return service
+		}
+	}
+
+	// Try matching just the service name part (e.g., "Add" from "addsvc.Add")
+	shortServiceName := requestedServiceName
+	if idx := strings.LastIndex(requestedServiceName, "."); idx != -1 {
+		shortServiceName = requestedServiceName[idx+1:]
+	}
+
+	for _, service := range availableServices {
+		if service == shortServiceName {
+			return service
+		}
+	}
+
+	// Try case-insensitive match
+	for _, service := range availableServices {
+		if strings.EqualFold(service, requestedServiceName) || strings.EqualFold(service, shortServiceName) {
+			return service
+		}
+	}
+
+	// Try partial match (contains)
+	for _, service := range availableServices {
+		if strings.Contains(strings.ToLower(service), strings.ToLower(shortServiceName)) ||
+			strings.Contains(strings.ToLower(shortServiceName), strings.ToLower(service)) {
+			return service
+		}
+	}
+
+	return ""
+}
+
+func parseDynamicMessage(msgDesc *desc.MessageDescriptor, message interface{}) (*dynamic.Message, error) {
+	if message == nil {
+		message = make(map[string]interface{})
+	}
+
+	// Variable substitution can turn values into numbers or booleans; string fields still need strings
+	message = coerceStringFields(msgDesc, message)
+
+	// Convert message to JSON and back to ensure proper format
+	jsonBytes, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %v", err)
+	}
+
+	// Create dynamic message
+	msg := dynamic.NewMessage(msgDesc)
+	if err := msg.UnmarshalJSON(jsonBytes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %v", err)
+	}
+
+	return msg, nil
+}
+
+// coerceStringFields converts number and boolean values of string fields to their text form
+func coerceStringFields(msgDesc *desc.MessageDescriptor, message interface{}) interface{} {
+	fields, ok := message.(map[string]interface{})
+	if !ok {
+		return message
+	}
+
+	for key, value := range fields {
+		fieldDesc := msgDesc.FindFieldByJSONName(key)
+		if fieldDesc == nil {
+			fieldDesc = msgDesc.FindFieldByName(key)
+		}
+		if fieldDesc == nil {
+			continue
+		}
+
+		switch fieldDesc.GetType() {
+		case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+			if fieldDesc.IsRepeated() {
+				if items, ok := value.([]interface{}); ok {
+					for i, item := range items {
+						items[i] = stringifyScalar(item)
+					}
+				}
+			} else if !fieldDesc.IsMap() {
+				fields[key] = stringifyScalar(value)
+			}
+		case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE:
+			if fieldDesc.IsMap() {
+				continue
+			}
+			if fieldDesc.IsRepeated() {
+				if items, ok := value.([]interface{}); ok {
+					for i, item := range items {
+						items[i] = coerceStringFields(fieldDesc.GetMessageType(), item)
+					}
+				}
+			} else {
+				fields[key] = coerceStringFields(fieldDesc.GetMessageType(), value)
+			}
+		}
+	}
+	return fields
+}
+
+func stringifyScalar(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return value
+	}
+}
+
+func (ce *CallExecutor) createMetadata(grpcRequest models.GrpcRequest, headers http.Header) metadata.MD {
+	md := metadata.New(nil)
+
+	// Add metadata from request - now using simple flat object format
+	if grpcRequest.MetaData != nil {
+		for key, value := range grpcRequest.MetaData {
+			if value != "" { // Only add non-empty values
+				md.Append(strings.ToLower(key), value)
+			}
+		}
+	}
+
+	// Add credentials from the request auth configuration
+	if grpcRequest.Auth != nil {
+		for key, value := range authHeaders(*grpcRequest.Auth) {
+			if _, exists := md[strings.ToLower(key)]; !exists {
+				md.Append(strings.ToLower(key), value)
+			}
+		}
+	}
+
+	// Add authorization header if present and not already in metadata
+	if authHeader := headers.Get("Authorization"); authHeader != "" {
+		if _, exists := md["authorization"]; !exists {
+			md.Append("authorization", authHeader)
+		}
+	}
+
+	return md
+}
+
+func (ce *CallExecutor) makeUnaryCall(ctx context.Context, conn *grpc.ClientConn, methodDesc *desc.MethodDescriptor, request *dynamic.Message, result *models.CallResult) (interface{}, error) {
+	log.Printf("Making gRPC unary call to method: %s", methodDesc.GetFullyQualifiedName())
+
+	// Prepare method invocation
+	fullMethodName := fmt.Sprintf("/%s/%s", methodDesc.GetService().GetFullyQualifiedName(), methodDesc.GetName())
+
+	// Create response message
+	responseMsg := dynamic.NewMessage(methodDesc.GetOutputType())
+
+	// Invoke method, capturing response headers and trailers
+	var header, trailer metadata.MD
+	err := conn.Invoke(ctx, fullMethodName, request, responseMsg, grpc.Header(&header), grpc.Trailer(&trailer))
+	result.Headers = header
+	result.Trailers = trailer
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert response to JSON
+	jsonBytes, err := responseMsg.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %v", err)
+	}
+
+	// Parse JSON to interface{}
+	var body interface{}
+	if err := json.Unmarshal(jsonBytes, &body); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response JSON: %v", err)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"grpc-client/models"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// compareCall is a request resolved for one comparison target
+type compareCall struct {
+	request            models.GrpcRequest
+	undefinedVariables []string
+}
+
+// Compare sends each request to all targets concurrently and diffs every response with the
+// response of the first target. Requests are resolved per target, so environment variables
+// such as hosts and tokens differ while the request itself stays the same.
+func (rr *RequestRunner) Compare(ctx context.Context, compareRequest models.CompareRequest) (*models.CompareRunResult, error) {
+	if err := validateCompareRequest(compareRequest); err != nil {
+		return nil, err
+	}
+
+	targets := make([]models.CompareTarget, len(compareRequest.Targets))
+	for i, target := range compareRequest.Targets {
+		targets[i] = target
+		if targets[i].Name == "" {
+			targets[i].Name = compareTargetName(target)
+		}
+	}
+
+	result := &models.CompareRunResult{
+		Baseline:  targets[0].Name,
+		Matched:   true,
+		Results:   []models.CompareResult{},
+		StartedAt: time.Now(),
+	}
+
+	if compareRequest.Request != nil {
+		comparison, err := rr.compareCalls(ctx, targets, compareRequest.IgnorePaths, func(target models.CompareTarget) (compareCall, error) {
+			grpcRequest := *compareRequest.Request
+			if target.EnvironmentID != "" {
+				grpcRequest.EnvironmentID = target.EnvironmentID
+			}
+			variables := make(map[string]string, len(grpcRequest.Variables)+len(compareRequest.Variables))
+			for key, value := range grpcRequest.Variables {
+				variables[key] = value
+			}
+			for key, value := range compareRequest.Variables {
+				variables[key] = value
+			}
+
+			resolver, _, err := rr.store.ResolverFor(grpcRequest.CollectionID, grpcRequest.EnvironmentID, variables)
+			if err != nil {
+				return compareCall{}, err
+			}
+			return compareCall{request: resolver.ResolveGrpcRequest(grpcRequest), undefinedVariables: resolver.Undefined()}, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Results = append(result.Results, *comparison)
+	}
+
+	for _, requestID := range compareRequest.RequestIDs {
+		request, collection, err := rr.FindRequest(requestID)
+		if err != nil {
+			return nil, err
+		}
+		comparison, err := rr.compareCalls(ctx, targets, compareRequest.IgnorePaths, func(target models.CompareTarget) (compareCall, error) {
+			grpcRequest, undefinedVariables, err := rr.ResolveGrpcCall(request, collection, models.RunRequestOptions{
+				EnvironmentID: target.EnvironmentID,
+				Variables:     compareRequest.Variables,
+			})
+			return compareCall{request: grpcRequest, undefinedVariables: undefinedVariables}, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		comparison.RequestID = request.ID
+		comparison.RequestName = request.Name
+		result.Results = append(result.Results, *comparison)
+	}
+
+	for _, comparison := range result.Results {
+		result.Matched = result.Matched && comparison.Matched
+	}
+	result.DurationMs = time.Since(result.StartedAt).Milliseconds()
+	return result, nil
+}
+
+// compareCalls resolves a request for every target, sends the calls concurrently and diffs
+// the responses with the baseline
+func (rr *RequestRunner) compareCalls(ctx context.Context, targets []models.CompareTarget, ignorePaths []string, resolve func(target models.CompareTarget) (compareCall, error)) (*models.CompareResult, error) {
+	calls := make([]compareCall, len(targets))
+	for i, target := range targets {
+		call, err := resolve(target)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %v", target.Name, err)
+		}
+		if target.Host != "" {
+			call.request.Host = target.Host
+		}
+		calls[i] = call
+	}
+
+	log.Printf("Comparing %s across %d targets", calls[0].request.Method, len(targets))
+
+	results := make([]*models.CallResult, len(targets))
+	var wg sync.WaitGroup
+	for i := range calls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = rr.executor.ExecuteGrpc(ctx, calls[i].request, http.Header{})
+		}(i)
+	}
+	wg.Wait()
+
+	comparison := &models.CompareResult{
+		Method:    calls[0].request.Method,
+		Matched:   true,
+		Responses: make([]models.CompareResponse, 0, len(targets)),
+		Diffs:     make([]models.CompareDiff, 0, len(targets)-1),
+	}
+	for i, target := range targets {
+		comparison.Responses = append(comparison.Responses, models.CompareResponse{
+			Target:             target.Name,
+			EnvironmentID:      target.EnvironmentID,
+			Host:               calls[i].request.Host,
+			Status:             results[i].Status,
+			StatusCode:         results[i].StatusCode,
+			Error:              results[i].Error,
+			Body:               results[i].Body,
+			UndefinedVariables: calls[i].undefinedVariables,
+			DurationMs:         results[i].DurationMs,
+		})
+		if i == 0 {
+			continue
+		}
+
+		differences := diffResponses(results[0], results[i], ignorePaths)
+		comparison.Diffs = append(comparison.Diffs, models.CompareDiff{
+			Target:         target.Name,
+			Matched:        len(differences) == 0,
+			Differences:    differences,
+			LatencyDeltaMs: results[i].DurationMs - results[0].DurationMs,
+		})
+		comparison.Matched = comparison.Matched && len(differences) == 0
+	}
+	return comparison, nil
+}
+
+// validateCompareRequest checks the requests, targets and ignore paths of a comparison
+func validateCompareRequest(compareRequest models.CompareRequest) error {
+	if (compareRequest.Request == nil) == (len(compareRequest.RequestIDs) == 0) {
+		return fmt.Errorf("set either request or requestIds")
+	}
+	if len(compareRequest.Targets) < 2 {
+		return fmt.Errorf("at least two targets are required")
+	}
+	for i, target := range compareRequest.Targets {
+		if target.EnvironmentID == "" && target.Host == "" {
+			return fmt.Errorf("target %d needs an environmentId or a host", i+1)
+		}
+	}
+	return validateIgnorePaths(compareRequest.IgnorePaths)
+}
+
+// compareTargetName labels a target by its environment and host
+func compareTargetName(target models.CompareTarget) string {
+	switch {
+	case target.EnvironmentID != "" && target.Host != "":
+		return target.EnvironmentID + "@" + target.Host
+	case target.EnvironmentID != "":
+		return target.EnvironmentID
+	}
+	return target.Host
+}
@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"fmt"
+	"grpc-client/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompareController compares the responses of gRPC requests across environments and hosts
+type CompareController struct {
+	runner *RequestRunner
+}
+
+func NewCompareController(runner *RequestRunner) *CompareController {
+	return &CompareController{
+		runner: runner,
+	}
+}
+
+// CompareResponses sends an inline gRPC request or saved gRPC requests to every target and returns the
+// responses with their field-level differences from the first target. Lookup and validation
+// errors fail the request; call failures are part of the compared responses.
+func (cc *CompareController) CompareResponses(c *gin.Context) {
+	var compareRequest models.CompareRequest
+	if err := c.ShouldBindJSON(&compareRequest); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	result, err := cc.runner.Compare(c.Request.Context(), compareRequest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
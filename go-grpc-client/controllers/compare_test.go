@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"context"
+	"grpc-client/models"
+	"reflect"
+	"testing"
+)
+
+func TestValidateCompareRequest(t *testing.T) {
+	request := &models.GrpcRequest{Method: "demo.Get"}
+	targets := []models.CompareTarget{{EnvironmentID: "prod"}, {Host: "localhost:50051"}}
+
+	tests := []struct {
+		name    string
+		compare models.CompareRequest
+		wantErr bool
+	}{
+		{name: "inline request", compare: models.CompareRequest{Request: request, Targets: targets}},
+		{name: "saved requests", compare: models.CompareRequest{RequestIDs: []string{"r1"}, Targets: targets}},
+		{name: "no request", compare: models.CompareRequest{Targets: targets}, wantErr: true},
+		{name: "both request kinds", compare: models.CompareRequest{Request: request, RequestIDs: []string{"r1"}, Targets: targets}, wantErr: true},
+		{name: "one target", compare: models.CompareRequest{Request: request, Targets: targets[:1]}, wantErr: true},
+		{name: "target without environment or host", compare: models.CompareRequest{Request: request, Targets: []models.CompareTarget{targets[0], {Name: "empty"}}}, wantErr: true},
+		{name: "invalid ignore path", compare: models.CompareRequest{Request: request, Targets: targets, IgnorePaths: []string{"$.items["}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateCompareRequest(tt.compare); (err != nil) != tt.wantErr {
+				t.Errorf("validateCompareRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompareTargetName(t *testing.T) {
+	tests := []struct {
+		target models.CompareTarget
+		want   string
+	}{
+		{target: models.CompareTarget{EnvironmentID: "prod", Host: "a:1"}, want: "prod@a:1"},
+		{target: models.CompareTarget{EnvironmentID: "prod"}, want: "prod"},
+		{target: models.CompareTarget{Host: "a:1"}, want: "a:1"},
+	}
+
+	for _, tt := range tests {
+		if got := compareTargetName(tt.target); got != tt.want {
+			t.Errorf("compareTargetName(%+v) = %s, want %s", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	address, _ := startTestGrpcServer(t)
+	runner, store := newTestRunner(t)
+	saveTestCollection(t, store, models.Collection{
+		ID:   "c1",
+		Name: "health",
+		Environments: []models.Environment{
+			{ID: "prod", Name: "Production", Variables: map[string]string{"host": address, "service": ""}},
+			{ID: "staging", Name: "Staging", Variables: map[string]string{"host": address, "service": ""}},
+			{ID: "broken", Name: "Broken", Variables: map[string]string{"host": address, "service": "NOT_FOUND"}},
+		},
+		Requests: []models.Request{{
+			ID:   "r1",
+			Name: "check",
+			Type: models.RequestTypeGRPC,
+			Host: "{{host}}",
+			GRPCConfig: &models.GRPCConfig{
+				Service: "grpc.health.v1.Health",
+				Method:  "Check",
+				Message: map[string]interface{}{"service": "{{service}}"},
+			},
+		}},
+	})
+
+	// Saved requests are resolved against each target's environment
+	result, err := runner.Compare(context.Background(), models.CompareRequest{
+		RequestIDs: []string{"r1"},
+		Targets:    []models.CompareTarget{{EnvironmentID: "prod"}, {EnvironmentID: "staging"}, {EnvironmentID: "broken"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Baseline != "prod" || result.Matched || len(result.Results) != 1 {
+		t.Fatalf("result = %+v, want an unmatched comparison against prod", result)
+	}
+	comparison := result.Results[0]
+	if comparison.RequestID != "r1" || comparison.RequestName != "check" || comparison.Method != "grpc.health.v1.Health.Check" {
+		t.Errorf("comparison = %+v", comparison)
+	}
+	var statuses []string
+	for _, response := range comparison.Responses {
+		statuses = append(statuses, response.Status)
+		if response.Host != address {
+			t.Errorf("target %s called %s, want %s", response.Target, response.Host, address)
+		}
+	}
+	if want := []string{"OK", "OK", "NOT_FOUND"}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+	if len(comparison.Diffs) != 2 {
+		t.Fatalf("comparison has %d diffs, want one per target after the baseline", len(comparison.Diffs))
+	}
+	if diff := comparison.Diffs[0]; diff.Target != "staging" || !diff.Matched || len(diff.Differences) != 0 {
+		t.Errorf("staging diff = %+v, want a match", diff)
+	}
+	if diff := comparison.Diffs[1]; diff.Target != "broken" || diff.Matched || len(diff.Differences) == 0 || diff.Differences[0].Path != "status" {
+		t.Errorf("broken diff = %+v, want a status difference", diff)
+	}
+
+	// An inline request takes the target host and the comparison variables
+	result, err = runner.Compare(context.Background(), models.CompareRequest{
+		Request: &models.GrpcRequest{
+			Host:    "127.0.0.1:1",
+			Method:  "grpc.health.v1.Health.Check",
+			Message: map[string]interface{}{"service": "{{service}}"},
+		},
+		Variables: map[string]string{"service": ""},
+		Targets:   []models.CompareTarget{{Name: "first", Host: address}, {Host: address}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Matched || result.Baseline != "first" || result.Results[0].Diffs[0].Target != address {
+		t.Errorf("result = %+v, want matching responses from both hosts", result)
+	}
+	for _, response := range result.Results[0].Responses {
+		if response.Status != "OK" {
+			t.Errorf("target %s status = %s, want OK", response.Target, response.Status)
+		}
+	}
+
+	if _, err := runner.Compare(context.Background(), models.CompareRequest{
+		RequestIDs: []string{"missing"},
+		Targets:    []models.CompareTarget{{EnvironmentID: "prod"}, {EnvironmentID: "staging"}},
+	}); err == nil {
+		t.Error("comparing an unknown request should fail")
+	}
+	if _, err := runner.Compare(context.Background(), models.CompareRequest{
+		RequestIDs: []string{"r1"},
+		Targets:    []models.CompareTarget{{EnvironmentID: "prod"}, {EnvironmentID: "missing"}},
+	}); err == nil {
+		t.Error("comparing against an unknown environment should fail")
+	}
+}
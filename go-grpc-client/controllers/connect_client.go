@@ -0,0 +1,471 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"grpc-client/models"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"golang.org/x/net/http2"
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails" // registers google.rpc error detail types
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	connectCodecJSON  = "json"
+	connectCodecProto = "proto"
+
+	connectProtocolVersion = "1"
+	connectFlagCompressed  = 0x01
+	connectFlagEndStream   = 0x02
+)
+
+// ConnectError represents an error returned by a Connect server
+type ConnectError struct {
+	Code       string
+	Message    string
+	Details    []interface{}
+	HTTPStatus int
+}
+
+func (e *ConnectError) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// connectWireError is the JSON error format defined by the Connect protocol
+type connectWireError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details []struct {
+		Type  string          `json:"type"`
+		Value string          `json:"value"`
+		Debug json.RawMessage `json:"debug,omitempty"`
+	} `json:"details"`
+}
+
+// connectEndStream is the payload of the final message of a Connect stream
+type connectEndStream struct {
+	Error    *connectWireError   `json:"error,omitempty"`
+	Metadata map[string][]string `json:"metadata,omitempty"`
+}
+
+func (ce *CallExecutor) executeConnectCall(ctx context.Context, grpcRequest models.GrpcRequest, headers http.Header, result *models.CallResult) (interface{}, error) {
+	options := models.ConnectOptions{}
+	if grpcRequest.Connect != nil {
+		options = *grpcRequest.Connect
+	}
+	if options.Codec == "" {
+		options.Codec = connectCodecJSON
+	}
+	if options.Codec != connectCodecJSON && options.Codec != connectCodecProto {
+		return nil, fmt.Errorf("unsupported connect codec: %s", options.Codec)
+	}
+
+	baseURL, err := httpBaseURL(grpcRequest.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the method through reflection. JSON calls can still be made
+	// without a descriptor since the server decodes the payload itself.
+	methodDesc, err := ce.resolveConnectMethod(grpcRequest)
+	if err != nil {
+		if options.Codec != connectCodecJSON {
+			return nil, fmt.Errorf("method descriptor error: %v", err)
+		}
+		log.Printf("Reflection unavailable for %s, sending raw JSON: %v", grpcRequest.Host, err)
+	}
+
+	procedure, err := connectProcedure(grpcRequest.Method, methodDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	client := newConnectHTTPClient(baseURL.Scheme, options.HTTPVersion)
+	md := ce.createMetadata(grpcRequest, headers)
+	endpoint := strings.TrimSuffix(baseURL.String(), "/") + procedure
+
+	log.Printf("Making Connect call to %s (codec: %s)", endpoint, options.Codec)
+
+	if methodDesc != nil && (methodDesc.IsClientStreaming() || methodDesc.IsServerStreaming()) {
+		return makeConnectStreamingCall(ctx, client, endpoint, options.Codec, methodDesc, grpcRequest.Message, md, result)
+	}
+	return makeConnectUnaryCall(ctx, client, endpoint, options.Codec, methodDesc, grpcRequest.Message, md, result)
+}
+
+func (ce *CallExecutor) resolveConnectMethod(grpcRequest models.GrpcRequest) (*desc.MethodDescriptor, error) {
+	conn, err := ce.createConnection(grpcRequest.Host)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return ce.getMethodDescriptor(conn, grpcRequest.Method)
+}
+
+// httpBaseURL builds the HTTP base URL for a host, defaulting to HTTPS on port 443 or when no port is given
+func httpBaseURL(host string) (*url.URL, error) {
+	switch {
+	case strings.HasPrefix(host, "grpcs://"):
+		host = "https://" + strings.TrimPrefix(host, "grpcs://")
+	case strings.HasPrefix(host, "grpc://"):
+		host = "http://" + strings.TrimPrefix(host, "grpc://")
+	case !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://"):
+		scheme := "http"
+		if _, port, err := net.SplitHostPort(host); err != nil || port == "443" {
+			scheme = "https"
+		}
+		host = scheme + "://" + host
+	}
+
+	baseURL, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %q: %v", host, err)
+	}
+	return baseURL, nil
+}
+
+// connectProcedure returns the "/package.Service/Method" path for a call
+func connectProcedure(methodName string, methodDesc *desc.MethodDescriptor) (string, error) {
+	if methodDesc != nil {
+		return fmt.Sprintf("/%s/%s", methodDesc.GetService().GetFullyQualifiedName(), methodDesc.GetName()), nil
+	}
+
+	idx := strings.LastIndex(methodName, ".")
+	if idx <= 0 || idx == len(methodName)-1 {
+		return "", fmt.Errorf("invalid method name format: %s", methodName)
+	}
+	return fmt.Sprintf("/%s/%s", methodName[:idx], methodName[idx+1:]), nil
+}
+
+func newConnectHTTPClient(scheme, httpVersion string) *http.Client {
+	if httpVersion == "2" {
+		transport := &http2.Transport{}
+		if scheme == "http" {
+			// HTTP/2 over cleartext (h2c) with prior knowledge
+			transport.AllowHTTP = true
+			transport.DialTLSContext = func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, addr)
+			}
+		}
+		return &http.Client{Transport: transport}
+	}
+
+	// Disable HTTP/2 negotiation so requests stay on HTTP/1.1
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	return &http.Client{Transport: transport}
+}
+
+func makeConnectUnaryCall(ctx context.Context, client *http.Client, endpoint, codec string, methodDesc *desc.MethodDescriptor, message interface{}, md metadata.MD, result *models.CallResult) (interface{}, error) {
+	payload, err := encodeConnectMessage(codec, methodDesc, message)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/"+codec)
+	setConnectHeaders(ctx, req, md)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("connect request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Unary responses carry trailers as "Trailer-" prefixed headers
+	result.Headers = make(map[string][]string)
+	result.Trailers = make(map[string][]string)
+	for key, values := range resp.Header {
+		if strings.HasPrefix(key, "Trailer-") {
+			result.Trailers[strings.ToLower(strings.TrimPrefix(key, "Trailer-"))] = values
+		} else {
+			result.Headers[strings.ToLower(key)] = values
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, decodeConnectUnaryError(resp.StatusCode, body)
+	}
+
+	return decodeConnectMessage(codec, methodDesc, body)
+}
+
+func makeConnectStreamingCall(ctx context.Context, client *http.Client, endpoint, codec string, methodDesc *desc.MethodDescriptor, message interface{}, md metadata.MD, result *models.CallResult) (interface{}, error) {
+	// Client and bidi streams take an array of messages; everything is sent
+	// before the responses are read, so bidi calls work as half-duplex.
+	messages := []interface{}{message}
+	if methodDesc.IsClientStreaming() {
+		if list, ok := message.([]interface{}); ok {
+			messages = list
+		}
+	}
+
+	var requestBody bytes.Buffer
+	for _, msg := range messages {
+		payload, err := encodeConnectMessage(codec, methodDesc, msg)
+		if err != nil {
+			return nil, err
+		}
+		writeConnectEnvelope(&requestBody, 0, payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &requestBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/connect+"+codec)
+	setConnectHeaders(ctx, req, md)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("connect request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	result.Headers = lowerCaseHeaders(resp.Header)
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, decodeConnectUnaryError(resp.StatusCode, body)
+	}
+
+	results := make([]interface{}, 0)
+	for {
+		flags, payload, err := readConnectEnvelope(resp.Body)
+		if err == io.EOF {
+			return nil, fmt.Errorf("connect stream ended without end-of-stream message")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stream: %v", err)
+		}
+		if flags&connectFlagCompressed != 0 {
+			return nil, fmt.Errorf("compressed stream messages are not supported")
+		}
+
+		if flags&connectFlagEndStream != 0 {
+			var end connectEndStream
+			if err := json.Unmarshal(payload, &end); err != nil {
+				return nil, fmt.Errorf("invalid end-of-stream message: %v", err)
+			}
+			result.Trailers = lowerCaseHeaders(end.Metadata)
+			if end.Error != nil {
+				return nil, newConnectError(resp.StatusCode, end.Error)
+			}
+			break
+		}
+
+		response, err := decodeConnectMessage(codec, methodDesc, payload)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, response)
+	}
+
+	if !methodDesc.IsServerStreaming() && len(results) == 1 {
+		return results[0], nil
+	}
+	return results, nil
+}
+
+func setConnectHeaders(ctx context.Context, req *http.Request, md metadata.MD) {
+	req.Header.Set("Connect-Protocol-Version", connectProtocolVersion)
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set("Connect-Timeout-Ms", strconv.FormatInt(time.Until(deadline).Milliseconds(), 10))
+	}
+	for key, values := range md {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+}
+
+func lowerCaseHeaders(headers map[string][]string) map[string][]string {
+	lowered := make(map[string][]string, len(headers))
+	for key, values := range headers {
+		lowered[strings.ToLower(key)] = values
+	}
+	return lowered
+}
+
+func encodeConnectMessage(codec string, methodDesc *desc.MethodDescriptor, message interface{}) ([]byte, error) {
+	if methodDesc == nil {
+		if message == nil {
+			message = make(map[string]interface{})
+		}
+		return json.Marshal(message)
+	}
+
+	msg, err := parseDynamicMessage(methodDesc.GetInputType(), message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request message: %v", err)
+	}
+	if codec == connectCodecProto {
+		return msg.Marshal()
+	}
+	return msg.MarshalJSON()
+}
+
+func decodeConnectMessage(codec string, methodDesc *desc.MethodDescriptor, payload []byte) (interface{}, error) {
+	if methodDesc != nil {
+		msg := dynamic.NewMessage(methodDesc.GetOutputType())
+		var err error
+		if codec == connectCodecProto {
+			err = msg.Unmarshal(payload)
+		} else {
+			err = msg.UnmarshalJSON(payload)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+		}
+		if payload, err = msg.MarshalJSON(); err != nil {
+			return nil, fmt.Errorf("failed to marshal response: %v", err)
+		}
+	}
+
+	var result interface{}
+	if len(payload) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	if err := json.Unmarshal(payload, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response JSON: %v", err)
+	}
+	return result, nil
+}
+
+func writeConnectEnvelope(w *bytes.Buffer, flags byte, payload []byte) {
+	var prefix [5]byte
+	prefix[0] = flags
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(payload)))
+	w.Write(prefix[:])
+	w.Write(payload)
+}
+
+func readConnectEnvelope(r io.Reader) (byte, []byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return prefix[0], payload, nil
+}
+
+func decodeConnectUnaryError(httpStatus int, body []byte) error {
+	var wireErr connectWireError
+	if err := json.Unmarshal(body, &wireErr); err != nil || wireErr.Code == "" {
+		// Not a Connect error (e.g. a proxy response); infer the code from the HTTP status
+		return &ConnectError{
+			Code:       connectCodeFromHTTPStatus(httpStatus),
+			Message:    strings.TrimSpace(string(body)),
+			HTTPStatus: httpStatus,
+		}
+	}
+	return newConnectError(httpStatus, &wireErr)
+}
+
+func newConnectError(httpStatus int, wireErr *connectWireError) *ConnectError {
+	connectErr := &ConnectError{
+		Code:       wireErr.Code,
+		Message:    wireErr.Message,
+		Details:    make([]interface{}, 0, len(wireErr.Details)),
+		HTTPStatus: httpStatus,
+	}
+
+	for _, detail := range wireErr.Details {
+		decoded := map[string]interface{}{"type": detail.Type}
+		if value, err := decodeConnectDetail(detail.Type, detail.Value); err == nil {
+			decoded["value"] = value
+		} else {
+			decoded["value"] = detail.Value
+			if len(detail.Debug) > 0 {
+				var debug interface{}
+				if json.Unmarshal(detail.Debug, &debug) == nil {
+					decoded["debug"] = debug
+				}
+			}
+		}
+		connectErr.Details = append(connectErr.Details, decoded)
+	}
+
+	return connectErr
+}
+
+// decodeConnectDetail decodes a base64 encoded error detail of a registered message type
+func decodeConnectDetail(typeName, value string) (interface{}, error) {
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	msgType, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(typeName))
+	if err != nil {
+		return nil, err
+	}
+
+	msg := msgType.New().Interface()
+	if err := proto.Unmarshal(raw, msg); err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(jsonBytes, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func connectCodeFromHTTPStatus(httpStatus int) string {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return "internal"
+	case http.StatusUnauthorized:
+		return "unauthenticated"
+	case http.StatusForbidden:
+		return "permission_denied"
+	case http.StatusNotFound:
+		return "unimplemented"
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return "unavailable"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"grpc-client/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+)
+
+const connectTestProto = `
+syntax = "proto3";
+package demo.v1;
+
+message Item {
+  string id = 1;
+  int32 count = 2;
+}
+
+service ItemService {
+  rpc GetItem(Item) returns (Item);
+  rpc WatchItems(Item) returns (stream Item);
+}
+`
+
+func TestConnectEnvelope(t *testing.T) {
+	var buf bytes.Buffer
+	writeConnectEnvelope(&buf, 0, []byte(`{"id":"1"}`))
+	writeConnectEnvelope(&buf, connectFlagEndStream, []byte(`{}`))
+
+	if got := buf.Bytes()[:5]; !bytes.Equal(got, []byte{0, 0, 0, 0, 10}) {
+		t.Errorf("envelope prefix = %v, want flags 0 and length 10", got)
+	}
+
+	flags, payload, err := readConnectEnvelope(&buf)
+	if err != nil || flags != 0 || string(payload) != `{"id":"1"}` {
+		t.Errorf("first envelope = %d, %q, %v", flags, payload, err)
+	}
+	flags, payload, err = readConnectEnvelope(&buf)
+	if err != nil || flags != connectFlagEndStream || string(payload) != `{}` {
+		t.Errorf("second envelope = %d, %q, %v", flags, payload, err)
+	}
+	if _, _, err := readConnectEnvelope(&buf); err != io.EOF {
+		t.Errorf("reading past the last envelope: %v, want EOF", err)
+	}
+	if _, _, err := readConnectEnvelope(bytes.NewReader([]byte{0, 0, 0, 0, 4, 'a'})); err == nil {
+		t.Error("truncated envelope should fail")
+	}
+}
+
+func TestConnectProcedureAndBaseURL(t *testing.T) {
+	file := parseTestProto(t, connectTestProto)
+	method := file.FindService("demo.v1.ItemService").FindMethodByName("GetItem")
+
+	if got, err := connectProcedure("ignored", method); err != nil || got != "/demo.v1.ItemService/GetItem" {
+		t.Errorf("connectProcedure(descriptor) = %q, %v", got, err)
+	}
+	if got, err := connectProcedure("demo.v1.ItemService.GetItem", nil); err != nil || got != "/demo.v1.ItemService/GetItem" {
+		t.Errorf("connectProcedure(name) = %q, %v", got, err)
+	}
+	for _, name := range []string{"GetItem", ".GetItem", "demo.v1.ItemService."} {
+		if _, err := connectProcedure(name, nil); err == nil {
+			t.Errorf("connectProcedure(%q) should fail", name)
+		}
+	}
+
+	hosts := map[string]string{
+		"localhost:8080":      "http://localhost:8080",
+		"api.example.com:443": "https://api.example.com:443",
+		"api.example.com":     "https://api.example.com",
+		"grpc://local:50051":  "http://local:50051",
+		"grpcs://remote:8443": "https://remote:8443",
+		"http://local:80/api": "http://local:80/api",
+	}
+	for host, want := range hosts {
+		if got, err := httpBaseURL(host); err != nil || got.String() != want {
+			t.Errorf("httpBaseURL(%q) = %v, %v, want %s", host, got, err, want)
+		}
+	}
+}
+
+func TestDecodeConnectUnaryError(t *testing.T) {
+	info, err := proto.Marshal(&errdetails.ErrorInfo{Reason: "QUOTA", Domain: "example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wire, _ := json.Marshal(map[string]interface{}{
+		"code":    "resource_exhausted",
+		"message": "too many calls",
+		"details": []map[string]interface{}{
+			{"type": "google.rpc.ErrorInfo", "value": base64.RawStdEncoding.EncodeToString(info)},
+			{"type": "example.Unknown", "value": "AAAA", "debug": map[string]interface{}{"a": 1}},
+		},
+	})
+
+	err = decodeConnectUnaryError(http.StatusTooManyRequests, wire)
+	var connectErr *ConnectError
+	if !errors.As(err, &connectErr) {
+		t.Fatalf("decodeConnectUnaryError() = %v, want a ConnectError", err)
+	}
+	if connectErr.Code != "resource_exhausted" || connectErr.Message != "too many calls" || connectErr.HTTPStatus != http.StatusTooManyRequests {
+		t.Errorf("ConnectError = %+v", connectErr)
+	}
+	wantDetails := []interface{}{
+		map[string]interface{}{"type": "google.rpc.ErrorInfo", "value": map[string]interface{}{"reason": "QUOTA", "domain": "example.com"}},
+		map[string]interface{}{"type": "example.Unknown", "value": "AAAA", "debug": map[string]interface{}{"a": float64(1)}},
+	}
+	if !reflect.DeepEqual(connectErr.Details, wantDetails) {
+		t.Errorf("details = %#v, want %#v", connectErr.Details, wantDetails)
+	}
+
+	statuses := map[int]string{
+		http.StatusBadRequest:          "internal",
+		http.StatusUnauthorized:        "unauthenticated",
+		http.StatusForbidden:           "permission_denied",
+		http.StatusNotFound:            "unimplemented",
+		http.StatusServiceUnavailable:  "unavailable",
+		http.StatusInternalServerError: "unknown",
+	}
+	for status, want := range statuses {
+		err := decodeConnectUnaryError(status, []byte(" upstream failure \n"))
+		if !errors.As(err, &connectErr) || connectErr.Code != want || connectErr.Message != "upstream failure" {
+			t.Errorf("decodeConnectUnaryError(%d, text) = %+v, want code %s", status, err, want)
+		}
+	}
+}
+
+func TestMakeConnectUnaryCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Connect-Protocol-Version") != "1" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request headers = %v", r.Header)
+		}
+		if r.Header.Get("x-team") != "core" {
+			t.Errorf("metadata header x-team = %q, want core", r.Header.Get("x-team"))
+		}
+		w.Header().Set("Trailer-Request-Id", "42")
+		w.Header().Set("X-Server", "test")
+		if r.URL.Path == "/demo.v1.ItemService/Missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": "not_found", "message": "no such item"}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := newConnectHTTPClient("http", "")
+	md := map[string][]string{"x-team": {"core"}}
+
+	result := &models.CallResult{}
+	response, err := makeConnectUnaryCall(context.Background(), client, server.URL+"/demo.v1.ItemService/GetItem", connectCodecJSON, nil, map[string]interface{}{"id": "7"}, md, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(response, map[string]interface{}{"id": "7"}) {
+		t.Errorf("response = %v", response)
+	}
+	if !reflect.DeepEqual(result.Trailers["request-id"], []string{"42"}) || !reflect.DeepEqual(result.Headers["x-server"], []string{"test"}) {
+		t.Errorf("headers = %v, trailers = %v", result.Headers, result.Trailers)
+	}
+
+	_, err = makeConnectUnaryCall(context.Background(), client, server.URL+"/demo.v1.ItemService/Missing", connectCodecJSON, nil, nil, md, &models.CallResult{})
+	var connectErr *ConnectError
+	if !errors.As(err, &connectErr) || connectErr.Code != "not_found" || connectErr.HTTPStatus != http.StatusNotFound {
+		t.Errorf("error = %v, want not_found", err)
+	}
+}
+
+func TestMakeConnectStreamingCall(t *testing.T) {
+	file := parseTestProto(t, connectTestProto)
+	method := file.FindService("demo.v1.ItemService").FindMethodByName("WatchItems")
+
+	tests := []struct {
+		name      string
+		codec     string
+		end       string
+		want      []interface{}
+		wantCode  string
+		wantTrail []string
+	}{
+		{
+			name:      "json messages and trailers",
+			codec:     connectCodecJSON,
+			end:       `{"metadata": {"Request-Id": ["42"]}}`,
+			want:      []interface{}{map[string]interface{}{"id": "a", "count": float64(1)}, map[string]interface{}{"id": "b", "count": float64(2)}},
+			wantTrail: []string{"42"},
+		},
+		{
+			name:  "proto messages",
+			codec: connectCodecProto,
+			end:   `{}`,
+			want:  []interface{}{map[string]interface{}{"id": "a", "count": float64(1)}, map[string]interface{}{"id": "b", "count": float64(2)}},
+		},
+		{
+			name:     "error in end of stream",
+			codec:    connectCodecJSON,
+			end:      `{"error": {"code": "aborted", "message": "stopped"}}`,
+			wantCode: "aborted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Content-Type"); got != "application/connect+"+tt.codec {
+					t.Errorf("content type = %q", got)
+				}
+				if _, payload, err := readConnectEnvelope(r.Body); err != nil || len(payload) == 0 {
+					t.Errorf("request envelope = %q, %v", payload, err)
+				}
+
+				var body bytes.Buffer
+				for _, item := range []map[string]interface{}{{"id": "a", "count": 1}, {"id": "b", "count": 2}} {
+					payload, err := encodeConnectMessage(tt.codec, method, item)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					writeConnectEnvelope(&body, 0, payload)
+				}
+				writeConnectEnvelope(&body, connectFlagEndStream, []byte(tt.end))
+				w.Write(body.Bytes())
+			}))
+			defer server.Close()
+
+			result := &models.CallResult{}
+			response, err := makeConnectStreamingCall(context.Background(), newConnectHTTPClient("http", ""), server.URL, tt.codec, method, map[string]interface{}{"id": "x"}, nil, result)
+			if tt.wantCode != "" {
+				var connectErr *ConnectError
+				if !errors.As(err, &connectErr) || connectErr.Code != tt.wantCode {
+					t.Fatalf("error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(response, tt.want) {
+				t.Errorf("response = %#v, want %#v", response, tt.want)
+			}
+			if got := result.Trailers["request-id"]; !reflect.DeepEqual(got, tt.wantTrail) {
+				t.Errorf("trailers = %v, want request-id %v", result.Trailers, tt.wantTrail)
+			}
+		})
+	}
+}
+
+func TestMakeConnectStreamingCallWithoutEndOfStream(t *testing.T) {
+	file := parseTestProto(t, connectTestProto)
+	method := file.FindService("demo.v1.ItemService").FindMethodByName("WatchItems")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		writeConnectEnvelope(&body, 0, []byte(`{"id": "a"}`))
+		w.Write(body.Bytes())
+	}))
+	defer server.Close()
+
+	_, err := makeConnectStreamingCall(context.Background(), newConnectHTTPClient("http", ""), server.URL, connectCodecJSON, method, nil, nil, &models.CallResult{})
+	if err == nil {
+		t.Fatal("stream without end-of-stream message should fail")
+	}
+}
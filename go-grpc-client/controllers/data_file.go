@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// parseDataFile reads the rows of a CSV file (first row holds the column names) or a
+// JSON array of objects. The format is taken from the file extension, or detected from
+// the content when the extension is neither .csv nor .json.
+func parseDataFile(filename string, content []byte) ([]map[string]interface{}, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")) // UTF-8 byte order mark
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return parseJSONData(content)
+	case ".csv":
+		return parseCSVData(content)
+	}
+
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '[' {
+		return parseJSONData(content)
+	}
+	return parseCSVData(content)
+}
+
+func parseJSONData(content []byte) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	if err := decodeJSONWithNumbers(bytes.NewReader(content), &rows); err != nil {
+		return nil, fmt.Errorf("data file must be a JSON array of objects: %v", err)
+	}
+	return rows, nil
+}
+
+// decodeJSONWithNumbers decodes a JSON document keeping numbers as json.Number, so 64-bit IDs
+// in data rows keep all their digits
+func decodeJSONWithNumbers(reader io.Reader, target interface{}) error {
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	if err := decoder.Decode(target); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected content after the JSON value")
+	}
+	return nil
+}
+
+func parseCSVData(content []byte) ([]map[string]interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV data file: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV data file is empty")
+	}
+
+	header := records[0]
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	rows := make([]map[string]interface{}, 0, len(records)-1)
+	for _, record := range records[1:] {
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue // blank line
+		}
+		row := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			if column == "" {
+				continue
+			}
+			if i < len(record) {
+				row[column] = record[i]
+			} else {
+				row[column] = ""
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseDataFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     []map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "CSV",
+			filename: "users.csv",
+			content:  "id, name\n1, alice\n2,bob\n",
+			want:     []map[string]interface{}{{"id": "1", "name": "alice"}, {"id": "2", "name": "bob"}},
+		},
+		{
+			name:     "CSV with byte order mark and quoted field",
+			filename: "users.CSV",
+			content:  "\xef\xbb\xbfid,note\n1,\"a, b\"\n",
+			want:     []map[string]interface{}{{"id": "1", "note": "a, b"}},
+		},
+		{
+			name:     "CSV short rows, blank lines and unnamed columns",
+			filename: "rows.csv",
+			content:  "id,,name\n1,x\n\n2,y,bob\n",
+			want:     []map[string]interface{}{{"id": "1", "name": ""}, {"id": "2", "name": "bob"}},
+		},
+		{
+			name:     "CSV with header only",
+			filename: "empty.csv",
+			content:  "id,name\n",
+			want:     []map[string]interface{}{},
+		},
+		{
+			name:     "empty CSV",
+			filename: "empty.csv",
+			content:  "",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid CSV",
+			filename: "bad.csv",
+			content:  "id\n\"unclosed\n",
+			wantErr:  true,
+		},
+		{
+			name:     "JSON keeps types",
+			filename: "users.json",
+			content:  `[{"id": 1, "active": true, "tags": ["a"]}]`,
+			want:     []map[string]interface{}{{"id": json.Number("1"), "active": true, "tags": []interface{}{"a"}}},
+		},
+		{
+			name:     "JSON large integers keep their digits",
+			filename: "ids.json",
+			content:  `[{"id": 9007199254740993}, {"id": 12345678901234567890}]`,
+			want:     []map[string]interface{}{{"id": json.Number("9007199254740993")}, {"id": json.Number("12345678901234567890")}},
+		},
+		{
+			name:     "JSON with trailing content",
+			filename: "users.json",
+			content:  `[{"id": 1}] [{"id": 2}]`,
+			wantErr:  true,
+		},
+		{
+			name:     "JSON that is not an array of objects",
+			filename: "users.json",
+			content:  `{"id": 1}`,
+			wantErr:  true,
+		},
+		{
+			name:     "JSON detected from content",
+			filename: "users.txt",
+			content:  "  [{\"id\": \"1\"}]",
+			want:     []map[string]interface{}{{"id": "1"}},
+		},
+		{
+			name:     "CSV detected from content",
+			filename: "users",
+			content:  "id\n1\n",
+			want:     []map[string]interface{}{{"id": "1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDataFile(tt.filename, []byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDataFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDataFile() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataRowLargeIntegerFormatting(t *testing.T) {
+	rows, err := parseDataFile("ids.json", []byte(`[{"id": 9007199254740993, "big": 1e21}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := formatFieldValue(rows[0]["id"]); got != "9007199254740993" {
+		t.Errorf("formatted id = %q, want 9007199254740993", got)
+	}
+	if got := formatFieldValue(rows[0]["big"]); got != "1e21" {
+		t.Errorf("formatted big = %q, want the number as written", got)
+	}
+}
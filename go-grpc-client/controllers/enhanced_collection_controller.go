@@ -2,14 +2,12 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"grpc-client/constants"
 	"grpc-client/models"
-	"io/ioutil"
 	"log"
 	"net/http"
-	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -19,34 +17,18 @@ import (
 )
 
 type EnhancedCollectionController struct {
-	baseFolderPath string
-	workspaceFile  string
+	store *WorkspaceStore
 }
 
-func NewEnhancedCollectionController() *EnhancedCollectionController {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		log.Printf("Error getting home directory: %v", err)
-		homeDir = "."
-	}
-
-	baseFolderPath := filepath.Join(homeDir, constants.GrpcCollectionLocation)
-	workspaceFile := filepath.Join(baseFolderPath, "workspace.json")
-
-	// Ensure that the base folder exists
-	if err := os.MkdirAll(baseFolderPath, 0755); err != nil {
-		log.Printf("Error creating base folder: %v", err)
-	}
-
+func NewEnhancedCollectionController(store *WorkspaceStore) *EnhancedCollectionController {
 	return &EnhancedCollectionController{
-		baseFolderPath: baseFolderPath,
-		workspaceFile:  workspaceFile,
+		store: store,
 	}
 }
 
 // LoadWorkspace loads the entire workspace with all collections
 func (ecc *EnhancedCollectionController) LoadWorkspace(c *gin.Context) {
-	workspace, err := ecc.loadWorkspaceFromFile()
+	workspace, err := ecc.store.Load()
 	if err != nil {
 		log.Printf("Error loading workspace: %v", err)
 		// Return empty workspace if file doesn't exist or is corrupted
@@ -77,23 +59,6 @@ func (ecc *EnhancedCollectionController) CreateCollection(c *gin.Context) {
 		return
 	}
 
-	workspace, err := ecc.loadWorkspaceFromFile()
-	if err != nil {
-		workspace = &models.Workspace{
-			Collections: []models.Collection{},
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		}
-	}
-
-	// Check if collection name already exists
-	for _, collection := range workspace.Collections {
-		if strings.EqualFold(collection.Name, req.Name) {
-			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Collection with this name already exists"})
-			return
-		}
-	}
-
 	// Create new collection
 	newCollection := models.Collection{
 		ID:           uuid.New().String(),
@@ -107,12 +72,20 @@ func (ecc *EnhancedCollectionController) CreateCollection(c *gin.Context) {
 		UpdatedAt:    time.Now(),
 	}
 
-	workspace.Collections = append(workspace.Collections, newCollection)
-	workspace.UpdatedAt = time.Now()
+	err := ecc.store.Update(func(workspace *models.Workspace) error {
+		// Check if collection name already exists
+		for _, collection := range workspace.Collections {
+			if strings.EqualFold(collection.Name, req.Name) {
+				return &workspaceUpdateError{status: http.StatusConflict, message: "Collection with this name already exists"}
+			}
+		}
 
-	if err := ecc.saveWorkspaceToFile(workspace); err != nil {
-		log.Printf("Error saving workspace: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to save collection"})
+		workspace.Collections = append(workspace.Collections, newCollection)
+		workspace.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		respondWorkspaceUpdateError(c, err, "Failed to save collection")
 		return
 	}
 
@@ -137,75 +110,77 @@ func (ecc *EnhancedCollectionController) UpdateCollection(c *gin.Context) {
 		return
 	}
 
-	workspace, err := ecc.loadWorkspaceFromFile()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
-		return
-	}
+	var updatedCollection models.Collection
+	err := ecc.store.Update(func(workspace *models.Workspace) error {
+		// Find the collection in workspace
+		var targetCollection *models.Collection
+		for i, collection := range workspace.Collections {
+			if collection.ID == collectionID {
+				targetCollection = &workspace.Collections[i]
+				break
+			}
+		}
 
-	// Find the collection in workspace
-	var targetCollection *models.Collection
-	for i, collection := range workspace.Collections {
-		if collection.ID == collectionID {
-			targetCollection = &workspace.Collections[i]
-			break
+		if targetCollection == nil {
+			return &workspaceUpdateError{status: http.StatusNotFound, message: "Collection not found"}
 		}
-	}
 
-	if targetCollection == nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Collection not found"})
-		return
-	}
+		// Check if collection is read-only (legacy collections)
+		for _, legacyCol := range ecc.loadLegacyCollections() {
+			if legacyCol.ID == collectionID {
+				return &workspaceUpdateError{status: http.StatusForbidden, message: "Cannot update read-only collections"}
+			}
+		}
 
-	// Check if collection is read-only (legacy collections)
-	legacyCollections := ecc.loadLegacyCollections()
-	isLegacy := false
-	for _, legacyCol := range legacyCollections {
-		if legacyCol.ID == collectionID {
-			isLegacy = true
-			break
+		// Update fields if provided
+		updated := false
+		if req.Name != "" {
+			// Check if new name already exists (excluding current collection)
+			for _, collection := range workspace.Collections {
+				if collection.ID != collectionID && strings.EqualFold(collection.Name, req.Name) {
+					return &workspaceUpdateError{status: http.StatusConflict, message: "Collection with this name already exists"}
+				}
+			}
+			targetCollection.Name = req.Name
+			updated = true
 		}
-	}
 
-	if isLegacy {
-		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Cannot update read-only collections"})
-		return
-	}
+		if req.Description != "" || (req.Description == "" && len(req.Description) >= 0) {
+			targetCollection.Description = req.Description
+			updated = true
+		}
 
-	// Update fields if provided
-	updated := false
-	if req.Name != "" {
-		// Check if new name already exists (excluding current collection)
-		for _, collection := range workspace.Collections {
-			if collection.ID != collectionID && strings.EqualFold(collection.Name, req.Name) {
-				c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Collection with this name already exists"})
-				return
-			}
+		if req.Variables != nil {
+			targetCollection.Variables = req.Variables
+			updated = true
 		}
-		targetCollection.Name = req.Name
-		updated = true
-	}
 
-	if req.Description != "" || (req.Description == "" && len(req.Description) >= 0) {
-		targetCollection.Description = req.Description
-		updated = true
-	}
+		if req.PreRequestScript != nil {
+			targetCollection.PreRequestScript = *req.PreRequestScript
+			updated = true
+		}
 
-	if updated {
-		targetCollection.UpdatedAt = time.Now()
-		workspace.UpdatedAt = time.Now()
-	}
+		if req.PostResponseScript != nil {
+			targetCollection.PostResponseScript = *req.PostResponseScript
+			updated = true
+		}
 
-	if err := ecc.saveWorkspaceToFile(workspace); err != nil {
-		log.Printf("Error saving workspace: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update collection"})
+		if updated {
+			targetCollection.UpdatedAt = time.Now()
+			workspace.UpdatedAt = time.Now()
+		}
+		updatedCollection = *targetCollection
+		return nil
+	})
+	if err != nil {
+		respondWorkspaceUpdateError(c, err, "Failed to update collection")
 		return
 	}
 
 	c.JSON(http.StatusOK, models.Response{
 		Message: "Collection updated successfully",
 		Status:  constants.ResponseStatusSuccess,
-		Data:    *targetCollection,
+		Data:    updatedCollection,
 	})
 }
 
@@ -217,23 +192,8 @@ func (ecc *EnhancedCollectionController) SaveRequest(c *gin.Context) {
 		return
 	}
 
-	workspace, err := ecc.loadWorkspaceFromFile()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
-		return
-	}
-
-	// Find the collection
-	var targetCollection *models.Collection
-	for i := range workspace.Collections {
-		if workspace.Collections[i].ID == req.CollectionID {
-			targetCollection = &workspace.Collections[i]
-			break
-		}
-	}
-
-	if targetCollection == nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Collection not found"})
+	if err := validateRetryPolicy(req.Request.Retry); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -245,31 +205,45 @@ func (ecc *EnhancedCollectionController) SaveRequest(c *gin.Context) {
 
 	req.Request.UpdatedAt = time.Now()
 
-	// Set order if not provided
-	if req.Request.Order == 0 {
-		req.Request.Order = len(targetCollection.Requests) + 1
-	}
-
-	// Check if request already exists (update) or is new (create)
 	updated := false
-	for i, existingRequest := range targetCollection.Requests {
-		if existingRequest.ID == req.Request.ID {
-			targetCollection.Requests[i] = req.Request
-			updated = true
-			break
+	err := ecc.store.Update(func(workspace *models.Workspace) error {
+		// Find the collection
+		var targetCollection *models.Collection
+		for i := range workspace.Collections {
+			if workspace.Collections[i].ID == req.CollectionID {
+				targetCollection = &workspace.Collections[i]
+				break
+			}
 		}
-	}
 
-	if !updated {
-		targetCollection.Requests = append(targetCollection.Requests, req.Request)
-	}
+		if targetCollection == nil {
+			return &workspaceUpdateError{status: http.StatusNotFound, message: "Collection not found"}
+		}
 
-	targetCollection.UpdatedAt = time.Now()
-	workspace.UpdatedAt = time.Now()
+		// Set order if not provided
+		if req.Request.Order == 0 {
+			req.Request.Order = len(targetCollection.Requests) + 1
+		}
 
-	if err := ecc.saveWorkspaceToFile(workspace); err != nil {
-		log.Printf("Error saving workspace: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to save request"})
+		// Check if request already exists (update) or is new (create)
+		for i, existingRequest := range targetCollection.Requests {
+			if existingRequest.ID == req.Request.ID {
+				targetCollection.Requests[i] = req.Request
+				updated = true
+				break
+			}
+		}
+
+		if !updated {
+			targetCollection.Requests = append(targetCollection.Requests, req.Request)
+		}
+
+		targetCollection.UpdatedAt = time.Now()
+		workspace.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		respondWorkspaceUpdateError(c, err, "Failed to save request")
 		return
 	}
 
@@ -300,54 +274,70 @@ func (ecc *EnhancedCollectionController) UpdateRequest(c *gin.Context) {
 		return
 	}
 
-	workspace, err := ecc.loadWorkspaceFromFile()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
-		return
-	}
-
-	// Merge legacy collections for searching
-	legacyCollections := ecc.loadLegacyCollections()
-	allCollections := append(workspace.Collections, legacyCollections...)
-
-	// Find the request across all collections
-	var targetCollection *models.Collection
-	var requestIndex int = -1
-
-	for i := range allCollections {
-		for j, request := range allCollections[i].Requests {
-			if request.ID == requestID {
-				targetCollection = &allCollections[i]
-				requestIndex = j
+	var updatedRequest models.Request
+	err := ecc.store.Update(func(workspace *models.Workspace) error {
+		// Find the request across the workspace collections
+		var targetCollection *models.Collection
+		requestIndex := -1
+		for i := range workspace.Collections {
+			for j, request := range workspace.Collections[i].Requests {
+				if request.ID == requestID {
+					targetCollection = &workspace.Collections[i]
+					requestIndex = j
+					break
+				}
+			}
+			if targetCollection != nil {
 				break
 			}
 		}
-		if targetCollection != nil {
-			break
-		}
-	}
 
-	if targetCollection == nil || requestIndex == -1 {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Request not found"})
-		return
-	}
+		// Don't allow updating legacy (read-only) collections
+		readOnly := &workspaceUpdateError{status: http.StatusForbidden, message: "Cannot update requests in read-only collections"}
+		legacyCollections := ecc.loadLegacyCollections()
+		if targetCollection == nil {
+			for _, legacyCol := range legacyCollections {
+				for _, request := range legacyCol.Requests {
+					if request.ID == requestID {
+						return readOnly
+					}
+				}
+			}
+			return &workspaceUpdateError{status: http.StatusNotFound, message: "Request not found"}
+		}
+		for _, legacyCol := range legacyCollections {
+			if legacyCol.ID == targetCollection.ID {
+				return readOnly
+			}
+		}
 
-	// Don't allow updating legacy (read-only) collections
-	isLegacy := false
-	for _, legacyCol := range legacyCollections {
-		if legacyCol.ID == targetCollection.ID {
-			isLegacy = true
-			break
+		request, err := applyRequestUpdate(targetCollection.Requests[requestIndex], updateData)
+		if err != nil {
+			return err
 		}
-	}
 
-	if isLegacy {
-		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Cannot update requests in read-only collections"})
+		// Update the request in the collection
+		targetCollection.Requests[requestIndex] = request
+		targetCollection.UpdatedAt = time.Now()
+		workspace.UpdatedAt = time.Now()
+		updatedRequest = request
+		return nil
+	})
+	if err != nil {
+		respondWorkspaceUpdateError(c, err, "Failed to update request")
 		return
 	}
 
-	// Update the request (preserve the original ID, CreatedAt, and Order)
-	originalRequest := targetCollection.Requests[requestIndex]
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Request updated successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    updatedRequest,
+	})
+}
+
+// applyRequestUpdate applies the fields of a partial update to a copy of the request,
+// preserving its ID, CreatedAt and Order
+func applyRequestUpdate(originalRequest models.Request, updateData map[string]interface{}) (models.Request, error) {
 	updatedRequest := originalRequest // Start with the original request
 
 	// Update fields that are provided
@@ -413,28 +403,84 @@ func (ecc *EnhancedCollectionController) UpdateRequest(c *gin.Context) {
 					updatedRequest.GRPCConfig.Metadata = headers
 				}
 			}
+
+			if protocol, ok := grpcMap["protocol"]; ok {
+				if protocolStr, ok := protocol.(string); ok {
+					updatedRequest.GRPCConfig.Protocol = models.CallProtocol(protocolStr)
+				}
+			}
+
+			if connect, ok := grpcMap["connect"]; ok {
+				if connectMap, ok := connect.(map[string]interface{}); ok {
+					options := &models.ConnectOptions{}
+					if codec, ok := connectMap["codec"].(string); ok {
+						options.Codec = codec
+					}
+					if httpVersion, ok := connectMap["httpVersion"].(string); ok {
+						options.HTTPVersion = httpVersion
+					}
+					updatedRequest.GRPCConfig.Connect = options
+				} else {
+					updatedRequest.GRPCConfig.Connect = nil
+				}
+			}
+
+			if transcoding, ok := grpcMap["transcoding"]; ok {
+				if transcodingMap, ok := transcoding.(map[string]interface{}); ok {
+					options := &models.TranscodingOptions{}
+					if baseURL, ok := transcodingMap["baseUrl"].(string); ok {
+						options.BaseURL = baseURL
+					}
+					updatedRequest.GRPCConfig.Transcoding = options
+				} else {
+					updatedRequest.GRPCConfig.Transcoding = nil
+				}
+			}
 		}
 	}
 
-	// Always update the timestamp
-	updatedRequest.UpdatedAt = time.Now()
+	if assertions, ok := updateData["assertions"]; ok {
+		var parsed []models.Assertion
+		if err := decodeUpdateField(assertions, &parsed); err != nil {
+			return models.Request{}, &workspaceUpdateError{status: http.StatusBadRequest, message: fmt.Sprintf("Invalid assertions: %v", err)}
+		}
+		updatedRequest.Assertions = parsed
+	}
 
-	// Update the request in the collection
-	targetCollection.Requests[requestIndex] = updatedRequest
-	targetCollection.UpdatedAt = time.Now()
-	workspace.UpdatedAt = time.Now()
+	if extractions, ok := updateData["extractions"]; ok {
+		var parsed []models.ExtractionRule
+		if err := decodeUpdateField(extractions, &parsed); err != nil {
+			return models.Request{}, &workspaceUpdateError{status: http.StatusBadRequest, message: fmt.Sprintf("Invalid extractions: %v", err)}
+		}
+		updatedRequest.Extractions = parsed
+	}
 
-	if err := ecc.saveWorkspaceToFile(workspace); err != nil {
-		log.Printf("Error saving workspace: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update request"})
-		return
+	if retry, ok := updateData["retry"]; ok {
+		var parsed *models.RetryPolicy
+		if err := decodeUpdateField(retry, &parsed); err != nil {
+			return models.Request{}, &workspaceUpdateError{status: http.StatusBadRequest, message: fmt.Sprintf("Invalid retry policy: %v", err)}
+		}
+		if err := validateRetryPolicy(parsed); err != nil {
+			return models.Request{}, &workspaceUpdateError{status: http.StatusBadRequest, message: err.Error()}
+		}
+		updatedRequest.Retry = parsed
 	}
 
-	c.JSON(http.StatusOK, models.Response{
-		Message: "Request updated successfully",
-		Status:  constants.ResponseStatusSuccess,
-		Data:    updatedRequest,
-	})
+	if script, ok := updateData["preRequestScript"]; ok {
+		if scriptStr, ok := script.(string); ok {
+			updatedRequest.PreRequestScript = scriptStr
+		}
+	}
+
+	if script, ok := updateData["postResponseScript"]; ok {
+		if scriptStr, ok := script.(string); ok {
+			updatedRequest.PostResponseScript = scriptStr
+		}
+	}
+
+	// Always update the timestamp
+	updatedRequest.UpdatedAt = time.Now()
+	return updatedRequest, nil
 }
 
 // CreateEnvironment creates a new environment in a collection
@@ -445,86 +491,186 @@ func (ecc *EnhancedCollectionController) CreateEnvironment(c *gin.Context) {
 		return
 	}
 
-	workspace, err := ecc.loadWorkspaceFromFile()
+	var newEnvironment models.Environment
+	err := ecc.store.Update(func(workspace *models.Workspace) error {
+		// Find the collection
+		var targetCollection *models.Collection
+		for i := range workspace.Collections {
+			if workspace.Collections[i].ID == req.CollectionID {
+				targetCollection = &workspace.Collections[i]
+				break
+			}
+		}
+
+		if targetCollection == nil {
+			return &workspaceUpdateError{status: http.StatusNotFound, message: "Collection not found"}
+		}
+
+		// Create new environment
+		newEnvironment = models.Environment{
+			ID:          uuid.New().String(),
+			Name:        req.Name,
+			Description: req.Description,
+			Variables:   req.Variables,
+			Auth:        req.Auth,
+			Metadata:    req.Metadata,
+			IsActive:    len(targetCollection.Environments) == 0, // First environment is active by default
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+
+		targetCollection.Environments = append(targetCollection.Environments, newEnvironment)
+		targetCollection.UpdatedAt = time.Now()
+		workspace.UpdatedAt = time.Now()
+		return nil
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
+		respondWorkspaceUpdateError(c, err, "Failed to create environment")
 		return
 	}
 
-	// Find the collection
-	var targetCollection *models.Collection
-	for i := range workspace.Collections {
-		if workspace.Collections[i].ID == req.CollectionID {
-			targetCollection = &workspace.Collections[i]
-			break
-		}
-	}
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Environment created successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    newEnvironment,
+	})
+}
 
-	if targetCollection == nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Collection not found"})
+// UpdateEnvironment updates an existing environment. Activating an environment
+// deactivates the other environments of its collection.
+func (ecc *EnhancedCollectionController) UpdateEnvironment(c *gin.Context) {
+	environmentID := c.Param("id")
+	if environmentID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Environment ID is required"})
 		return
 	}
 
-	// Create new environment
-	newEnvironment := models.Environment{
-		ID:          uuid.New().String(),
-		Name:        req.Name,
-		Description: req.Description,
-		Variables:   req.Variables,
-		IsActive:    len(targetCollection.Environments) == 0, // First environment is active by default
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	var req models.UpdateEnvironmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
 	}
 
-	targetCollection.Environments = append(targetCollection.Environments, newEnvironment)
-	targetCollection.UpdatedAt = time.Now()
-	workspace.UpdatedAt = time.Now()
+	var updatedEnvironment models.Environment
+	err := ecc.store.Update(func(workspace *models.Workspace) error {
+		// Find the environment and its collection
+		var targetCollection *models.Collection
+		var targetEnvironment *models.Environment
+		for i := range workspace.Collections {
+			for j := range workspace.Collections[i].Environments {
+				if workspace.Collections[i].Environments[j].ID == environmentID {
+					targetCollection = &workspace.Collections[i]
+					targetEnvironment = &workspace.Collections[i].Environments[j]
+					break
+				}
+			}
+			if targetEnvironment != nil {
+				break
+			}
+		}
+
+		if targetEnvironment == nil {
+			return &workspaceUpdateError{status: http.StatusNotFound, message: "Environment not found"}
+		}
 
-	if err := ecc.saveWorkspaceToFile(workspace); err != nil {
-		log.Printf("Error saving workspace: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to create environment"})
+		// Update fields if provided
+		if req.Name != "" {
+			targetEnvironment.Name = req.Name
+		}
+		if req.Description != nil {
+			targetEnvironment.Description = *req.Description
+		}
+		if req.Variables != nil {
+			targetEnvironment.Variables = req.Variables
+		}
+		if req.Auth != nil {
+			targetEnvironment.Auth = req.Auth
+		}
+		if req.Metadata != nil {
+			targetEnvironment.Metadata = req.Metadata
+		}
+		if req.IsActive != nil {
+			if *req.IsActive {
+				for i := range targetCollection.Environments {
+					targetCollection.Environments[i].IsActive = false
+				}
+			}
+			targetEnvironment.IsActive = *req.IsActive
+		}
+
+		targetEnvironment.UpdatedAt = time.Now()
+		targetCollection.UpdatedAt = time.Now()
+		workspace.UpdatedAt = time.Now()
+		updatedEnvironment = *targetEnvironment
+		return nil
+	})
+	if err != nil {
+		respondWorkspaceUpdateError(c, err, "Failed to update environment")
 		return
 	}
 
 	c.JSON(http.StatusOK, models.Response{
-		Message: "Environment created successfully",
+		Message: "Environment updated successfully",
 		Status:  constants.ResponseStatusSuccess,
-		Data:    newEnvironment,
+		Data:    updatedEnvironment,
 	})
 }
 
-// Helper functions
-func (ecc *EnhancedCollectionController) loadWorkspaceFromFile() (*models.Workspace, error) {
-	if _, err := os.Stat(ecc.workspaceFile); os.IsNotExist(err) {
-		return &models.Workspace{
-			Collections: []models.Collection{},
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		}, nil
+// UpdateWorkspaceVariables replaces the workspace-level variables
+func (ecc *EnhancedCollectionController) UpdateWorkspaceVariables(c *gin.Context) {
+	var req models.UpdateWorkspaceVariablesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
 	}
 
-	content, err := ioutil.ReadFile(ecc.workspaceFile)
+	err := ecc.store.Update(func(workspace *models.Workspace) error {
+		workspace.Variables = req.Variables
+		return nil
+	})
 	if err != nil {
-		return nil, err
-	}
-
-	var workspace models.Workspace
-	if err := json.Unmarshal(content, &workspace); err != nil {
-		return nil, err
+		log.Printf("Error saving workspace: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update workspace variables"})
+		return
 	}
 
-	return &workspace, nil
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Workspace variables updated successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    req.Variables,
+	})
 }
 
-func (ecc *EnhancedCollectionController) saveWorkspaceToFile(workspace *models.Workspace) error {
-	workspace.UpdatedAt = time.Now()
+// Helper functions
 
-	content, err := json.MarshalIndent(workspace, "", "  ")
+// decodeUpdateField converts a value from a partial update map into its typed form
+func decodeUpdateField(value interface{}, target interface{}) error {
+	jsonBytes, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
+	return json.Unmarshal(jsonBytes, target)
+}
 
-	return ioutil.WriteFile(ecc.workspaceFile, content, 0644)
+// workspaceUpdateError stops a workspace update and is answered with its own HTTP status
+type workspaceUpdateError struct {
+	status  int
+	message string
+}
+
+func (e *workspaceUpdateError) Error() string {
+	return e.message
+}
+
+// respondWorkspaceUpdateError answers a failed workspace update, using failureMessage when saving failed
+func respondWorkspaceUpdateError(c *gin.Context, err error, failureMessage string) {
+	var updateErr *workspaceUpdateError
+	if errors.As(err, &updateErr) {
+		c.JSON(updateErr.status, models.ErrorResponse{Error: updateErr.message})
+		return
+	}
+	log.Printf("Error saving workspace: %v", err)
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: failureMessage})
 }
 
 // DeleteCollection deletes an entire collection
@@ -535,33 +681,27 @@ func (ecc *EnhancedCollectionController) DeleteCollection(c *gin.Context) {
 		return
 	}
 
-	workspace, err := ecc.loadWorkspaceFromFile()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
-		return
-	}
-
-	// Find and remove the collection
-	collectionIndex := -1
-	for i, collection := range workspace.Collections {
-		if collection.ID == collectionID {
-			collectionIndex = i
-			break
+	err := ecc.store.Update(func(workspace *models.Workspace) error {
+		// Find and remove the collection
+		collectionIndex := -1
+		for i, collection := range workspace.Collections {
+			if collection.ID == collectionID {
+				collectionIndex = i
+				break
+			}
 		}
-	}
 
-	if collectionIndex == -1 {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Collection not found"})
-		return
-	}
-
-	// Remove the collection
-	workspace.Collections = append(workspace.Collections[:collectionIndex], workspace.Collections[collectionIndex+1:]...)
-	workspace.UpdatedAt = time.Now()
+		if collectionIndex == -1 {
+			return &workspaceUpdateError{status: http.StatusNotFound, message: "Collection not found"}
+		}
 
-	if err := ecc.saveWorkspaceToFile(workspace); err != nil {
-		log.Printf("Error saving workspace: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to delete collection"})
+		// Remove the collection
+		workspace.Collections = append(workspace.Collections[:collectionIndex], workspace.Collections[collectionIndex+1:]...)
+		workspace.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		respondWorkspaceUpdateError(c, err, "Failed to delete collection")
 		return
 	}
 
@@ -580,54 +720,48 @@ func (ecc *EnhancedCollectionController) DeleteRequest(c *gin.Context) {
 		return
 	}
 
-	workspace, err := ecc.loadWorkspaceFromFile()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
-		return
-	}
-
-	// Merge legacy collections (sample/converted legacy data) so they are searchable
-	legacyCollections := ecc.loadLegacyCollections()
-	// append legacy collections that are not already present in workspace
-	existingIDs := make(map[string]bool)
-	for _, col := range workspace.Collections {
-		existingIDs[col.ID] = true
-	}
-	for _, lcol := range legacyCollections {
-		if !existingIDs[lcol.ID] {
-			workspace.Collections = append(workspace.Collections, lcol)
+	err := ecc.store.Update(func(workspace *models.Workspace) error {
+		// Merge legacy collections (sample/converted legacy data) so they are searchable
+		legacyCollections := ecc.loadLegacyCollections()
+		// append legacy collections that are not already present in workspace
+		existingIDs := make(map[string]bool)
+		for _, col := range workspace.Collections {
+			existingIDs[col.ID] = true
+		}
+		for _, lcol := range legacyCollections {
+			if !existingIDs[lcol.ID] {
+				workspace.Collections = append(workspace.Collections, lcol)
+			}
 		}
-	}
 
-	// Find the collection containing the request
-	var targetCollection *models.Collection
-	requestIndex := -1
-	for i := range workspace.Collections {
-		for j, req := range workspace.Collections[i].Requests {
-			if req.ID == requestID {
-				targetCollection = &workspace.Collections[i]
-				requestIndex = j
+		// Find the collection containing the request
+		var targetCollection *models.Collection
+		requestIndex := -1
+		for i := range workspace.Collections {
+			for j, req := range workspace.Collections[i].Requests {
+				if req.ID == requestID {
+					targetCollection = &workspace.Collections[i]
+					requestIndex = j
+					break
+				}
+			}
+			if targetCollection != nil {
 				break
 			}
 		}
-		if targetCollection != nil {
-			break
-		}
-	}
 
-	if targetCollection == nil || requestIndex == -1 {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Request not found"})
-		return
-	}
-
-	// Remove the request
-	targetCollection.Requests = append(targetCollection.Requests[:requestIndex], targetCollection.Requests[requestIndex+1:]...)
-	targetCollection.UpdatedAt = time.Now()
-	workspace.UpdatedAt = time.Now()
+		if targetCollection == nil || requestIndex == -1 {
+			return &workspaceUpdateError{status: http.StatusNotFound, message: "Request not found"}
+		}
 
-	if err := ecc.saveWorkspaceToFile(workspace); err != nil {
-		log.Printf("Error saving workspace: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to delete request"})
+		// Remove the request
+		targetCollection.Requests = append(targetCollection.Requests[:requestIndex], targetCollection.Requests[requestIndex+1:]...)
+		targetCollection.UpdatedAt = time.Now()
+		workspace.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		respondWorkspaceUpdateError(c, err, "Failed to delete request")
 		return
 	}
 
@@ -647,43 +781,37 @@ func (ecc *EnhancedCollectionController) UpdateOrder(c *gin.Context) {
 		return
 	}
 
-	workspace, err := ecc.loadWorkspaceFromFile()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
-		return
-	}
-
-	// Find the collection
-	var targetCollection *models.Collection
-	for i := range workspace.Collections {
-		if workspace.Collections[i].ID == collectionID {
-			targetCollection = &workspace.Collections[i]
-			break
+	err := ecc.store.Update(func(workspace *models.Workspace) error {
+		// Find the collection
+		var targetCollection *models.Collection
+		for i := range workspace.Collections {
+			if workspace.Collections[i].ID == collectionID {
+				targetCollection = &workspace.Collections[i]
+				break
+			}
 		}
-	}
 
-	if targetCollection == nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Collection not found"})
-		return
-	}
+		if targetCollection == nil {
+			return &workspaceUpdateError{status: http.StatusNotFound, message: "Collection not found"}
+		}
 
-	// Update orders for requests
-	for _, item := range req.Items {
-		for i := range targetCollection.Requests {
-			if targetCollection.Requests[i].ID == item.ID {
-				targetCollection.Requests[i].Order = item.Order
-				targetCollection.Requests[i].UpdatedAt = time.Now()
-				break
+		// Update orders for requests
+		for _, item := range req.Items {
+			for i := range targetCollection.Requests {
+				if targetCollection.Requests[i].ID == item.ID {
+					targetCollection.Requests[i].Order = item.Order
+					targetCollection.Requests[i].UpdatedAt = time.Now()
+					break
+				}
 			}
 		}
-	}
 
-	targetCollection.UpdatedAt = time.Now()
-	workspace.UpdatedAt = time.Now()
-
-	if err := ecc.saveWorkspaceToFile(workspace); err != nil {
-		log.Printf("Error saving workspace: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update order"})
+		targetCollection.UpdatedAt = time.Now()
+		workspace.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		respondWorkspaceUpdateError(c, err, "Failed to update order")
 		return
 	}
 
@@ -695,7 +823,7 @@ func (ecc *EnhancedCollectionController) UpdateOrder(c *gin.Context) {
 
 // ExportWorkspace exports the entire workspace
 func (ecc *EnhancedCollectionController) ExportWorkspace(c *gin.Context) {
-	workspace, err := ecc.loadWorkspaceFromFile()
+	workspace, err := ecc.store.Load()
 	if err != nil {
 		log.Printf("Error loading workspace: %v", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
@@ -719,7 +847,7 @@ func (ecc *EnhancedCollectionController) ImportWorkspace(c *gin.Context) {
 	ecc.regenerateWorkspaceIDs(&importedWorkspace)
 	importedWorkspace.UpdatedAt = time.Now()
 
-	if err := ecc.saveWorkspaceToFile(&importedWorkspace); err != nil {
+	if err := ecc.store.Save(&importedWorkspace); err != nil {
 		log.Printf("Error saving imported workspace: %v", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to import workspace"})
 		return
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"fmt"
+	"grpc-client/constants"
+	"grpc-client/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestCollectionRouter(t *testing.T) (*gin.Engine, *WorkspaceStore) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	gin.SetMode(gin.TestMode)
+	store := NewWorkspaceStore()
+	controller := NewEnhancedCollectionController(store)
+
+	router := gin.New()
+	router.POST("/collections", controller.CreateCollection)
+	router.POST("/environments", controller.CreateEnvironment)
+	router.PUT("/requests/:requestId", controller.UpdateRequest)
+	return router, store
+}
+
+func serveTestRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestConcurrentCollectionChangesAreAllSaved(t *testing.T) {
+	router, store := newTestCollectionRouter(t)
+	saveTestCollection(t, store, models.Collection{ID: "c1", Name: "base"})
+
+	const changes = 20
+	var wg sync.WaitGroup
+	for i := 0; i < changes; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			serveTestRequest(router, http.MethodPost, "/collections", fmt.Sprintf(`{"name": "collection %d"}`, i))
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			serveTestRequest(router, http.MethodPost, "/environments", fmt.Sprintf(`{"collectionId": "c1", "name": "env %d"}`, i))
+		}(i)
+	}
+	wg.Wait()
+
+	workspace, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(workspace.Collections); got != changes+1 {
+		t.Errorf("workspace holds %d collections, want %d", got, changes+1)
+	}
+	if got := len(workspace.Collections[0].Environments); got != changes {
+		t.Errorf("collection holds %d environments, want %d", got, changes)
+	}
+}
+
+func TestCollectionHandlerStatuses(t *testing.T) {
+	router, store := newTestCollectionRouter(t)
+	saveTestCollection(t, store, models.Collection{
+		ID:       "c1",
+		Name:     "base",
+		Requests: []models.Request{{ID: "r1", Name: "get"}},
+	})
+	sampleRequestID := constants.SampleData[0].Requests[0].ID
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{name: "create collection", method: http.MethodPost, path: "/collections", body: `{"name": "other"}`, want: http.StatusOK},
+		{name: "duplicate collection name", method: http.MethodPost, path: "/collections", body: `{"name": "BASE"}`, want: http.StatusConflict},
+		{name: "environment of unknown collection", method: http.MethodPost, path: "/environments", body: `{"collectionId": "missing", "name": "dev"}`, want: http.StatusNotFound},
+		{name: "update request", method: http.MethodPut, path: "/requests/r1", body: `{"name": "renamed"}`, want: http.StatusOK},
+		{name: "invalid assertions", method: http.MethodPut, path: "/requests/r1", body: `{"assertions": "no"}`, want: http.StatusBadRequest},
+		{name: "unknown request", method: http.MethodPut, path: "/requests/missing", body: `{"name": "x"}`, want: http.StatusNotFound},
+		{name: "sample request is read-only", method: http.MethodPut, path: "/requests/" + sampleRequestID, body: `{"name": "x"}`, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serveTestRequest(router, tt.method, tt.path, tt.body).Code; got != tt.want {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+
+	workspace, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := workspace.Collections[0].Requests[0].Name; got != "renamed" {
+		t.Errorf("saved request name = %q, want renamed", got)
+	}
+}
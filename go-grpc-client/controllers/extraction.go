@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"fmt"
+	"grpc-client/models"
+	"strconv"
+	"strings"
+)
+
+// Sources and scopes of extraction rules
+const (
+	extractFromBody    = "body"
+	extractFromHeader  = "header"
+	extractFromTrailer = "trailer"
+	extractFromStatus  = "status"
+
+	extractToRun         = "run"
+	extractToEnvironment = "environment"
+)
+
+// applyExtractions stores response values into run or environment variables. Environment
+// values are recorded as script changes so they are saved with the workspace.
+func applyExtractions(rules []models.ExtractionRule, result *models.CallResult, session *scriptSession) []models.ExtractionResult {
+	var results []models.ExtractionResult
+	for _, rule := range rules {
+		if rule.Variable == "" {
+			continue
+		}
+
+		extraction := models.ExtractionResult{
+			Variable: rule.Variable,
+			Scope:    rule.Scope,
+		}
+		if extraction.Scope == "" {
+			extraction.Scope = extractToRun
+		}
+
+		value, err := extractValue(rule, result)
+		if err != nil {
+			extraction.Error = err.Error()
+			results = append(results, extraction)
+			continue
+		}
+
+		switch extraction.Scope {
+		case extractToRun:
+			session.resolver.Scope(scopeRun)[rule.Variable] = value
+		case extractToEnvironment:
+			variables := session.resolver.Scope(scopeEnvironment)
+			if variables == nil {
+				extraction.Error = "no environment is selected for this run"
+				results = append(results, extraction)
+				continue
+			}
+			variables[rule.Variable] = value
+			session.environmentChanges[rule.Variable] = &value
+		default:
+			extraction.Error = fmt.Sprintf("unknown scope %q", extraction.Scope)
+			results = append(results, extraction)
+			continue
+		}
+
+		extraction.Value = value
+		results = append(results, extraction)
+	}
+	return results
+}
+
+func extractValue(rule models.ExtractionRule, result *models.CallResult) (string, error) {
+	switch rule.Source {
+	case "", extractFromBody:
+		path := rule.Path
+		if path == "" {
+			path = "$"
+		}
+		value, found, err := evaluateJSONPath(result.Body, path)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return "", fmt.Errorf("no value at %s", path)
+		}
+		return formatFieldValue(value), nil
+	case extractFromHeader, extractFromTrailer:
+		values := result.Headers
+		if rule.Source == extractFromTrailer {
+			values = result.Trailers
+		}
+		if headerValues := values[strings.ToLower(rule.Path)]; len(headerValues) > 0 {
+			return headerValues[0], nil
+		}
+		return "", fmt.Errorf("no %s %q in response", rule.Source, rule.Path)
+	case extractFromStatus:
+		return strconv.Itoa(result.StatusCode), nil
+	default:
+		return "", fmt.Errorf("unknown source %q", rule.Source)
+	}
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"grpc-client/models"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	gatewayEnvironmentHeader = "X-Environment"
+	gatewayCollectionHeader  = "X-Collection"
+	gatewayMetadataPrefix    = "Grpc-Metadata-"
+	gatewayTrailerPrefix     = "Grpc-Trailer-"
+)
+
+// grpcToHTTPStatus maps gRPC codes to HTTP statuses the same way grpc-gateway does
+var grpcToHTTPStatus = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// GatewayController exposes reflected gRPC methods as plain JSON over HTTP routes
+type GatewayController struct {
+	executor *CallExecutor
+	store    *WorkspaceStore
+}
+
+func NewGatewayController(executor *CallExecutor, store *WorkspaceStore) *GatewayController {
+	return &GatewayController{
+		executor: executor,
+		store:    store,
+	}
+}
+
+// Invoke handles /gateway/:host/:service/:method. The request message is read from the
+// JSON body, or from the query string for requests without a body. An environment can be
+// selected with the X-Environment header (or _environment query parameter) to apply its
+// auth and metadata.
+func (gwc *GatewayController) Invoke(c *gin.Context) {
+	host := c.Param("host")
+	service := c.Param("service")
+	method := c.Param("method")
+
+	message, err := gatewayRequestMessage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request body: %v", err)})
+		return
+	}
+
+	grpcRequest := models.GrpcRequest{
+		Host:     host,
+		Method:   service + "." + method,
+		Message:  message,
+		MetaData: make(map[string]string),
+	}
+
+	// Apply auth and metadata of the selected environment, with its variables substituted
+	if environmentRef := gatewayParam(c, gatewayEnvironmentHeader, "_environment"); environmentRef != "" {
+		resolver, environment, err := gwc.store.ResolverFor(gatewayParam(c, gatewayCollectionHeader, "_collection"), environmentRef, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		for _, header := range resolver.ResolveHeaders(environment.Metadata) {
+			if header.Enabled && header.Key != "" {
+				grpcRequest.MetaData[strings.ToLower(header.Key)] = header.Value
+			}
+		}
+		grpcRequest.Auth = resolver.ResolveAuth(environment.Auth)
+	}
+
+	// Caller supplied metadata takes precedence over the environment
+	for key, values := range c.Request.Header {
+		if strings.HasPrefix(key, gatewayMetadataPrefix) && len(values) > 0 {
+			grpcRequest.MetaData[strings.ToLower(strings.TrimPrefix(key, gatewayMetadataPrefix))] = values[0]
+		}
+	}
+	if authorization := c.GetHeader("Authorization"); authorization != "" {
+		grpcRequest.MetaData["authorization"] = authorization
+	}
+
+	log.Printf("Gateway call to %s for method %s", grpcRequest.Host, grpcRequest.Method)
+
+	result := gwc.executor.ExecuteGrpc(c.Request.Context(), grpcRequest, http.Header{})
+
+	for key, values := range result.Headers {
+		for _, value := range values {
+			c.Writer.Header().Add(gatewayMetadataPrefix+key, value)
+		}
+	}
+	for key, values := range result.Trailers {
+		for _, value := range values {
+			c.Writer.Header().Add(gatewayTrailerPrefix+key, value)
+		}
+	}
+
+	if !result.Succeeded() {
+		httpStatus, ok := grpcToHTTPStatus[codes.Code(result.StatusCode)]
+		if !ok || result.Status == "" {
+			httpStatus = http.StatusBadGateway
+		}
+		c.JSON(httpStatus, models.ErrorResponse{
+			Error:   result.Error,
+			Code:    result.Status,
+			Details: result.ErrorDetails,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result.Body)
+}
+
+func gatewayParam(c *gin.Context, header, query string) string {
+	if value := c.GetHeader(header); value != "" {
+		return value
+	}
+	return c.Query(query)
+}
+
+// gatewayRequestMessage reads the JSON body, falling back to query parameters
+func gatewayRequestMessage(c *gin.Context) (interface{}, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(strings.TrimSpace(string(body))) > 0 {
+		var message interface{}
+		if err := json.Unmarshal(body, &message); err != nil {
+			return nil, err
+		}
+		return message, nil
+	}
+
+	message := make(map[string]interface{})
+	for key, values := range c.Request.URL.Query() {
+		if strings.HasPrefix(key, "_") {
+			continue // reserved for gateway options
+		}
+		if len(values) == 1 {
+			message[key] = values[0]
+		} else {
+			list := make([]interface{}, len(values))
+			for i, value := range values {
+				list[i] = value
+			}
+			message[key] = list
+		}
+	}
+	return message, nil
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"grpc-client/models"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// testHealthServer answers health checks for a service named after a gRPC code with that code
+type testHealthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (s *testHealthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	grpc.SetHeader(ctx, metadata.Pairs("x-served-by", "test"))
+	if req.Service == "" {
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+	}
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(req.Service))); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil, status.Error(code, "checked "+req.Service)
+}
+
+// startTestGrpcServer serves the health service with reflection on a local port. It returns
+// the address and a counter of the connections the server accepted.
+func startTestGrpcServer(t *testing.T) (string, *int32) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingListener{Listener: listener}
+
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, &testHealthServer{})
+	reflection.Register(server)
+	go server.Serve(counting)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String(), &counting.accepted
+}
+
+type countingListener struct {
+	net.Listener
+	accepted int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		atomic.AddInt32(&l.accepted, 1)
+	}
+	return conn, err
+}
+
+func TestGrpcToHTTPStatusCoversAllCodes(t *testing.T) {
+	for code := codes.OK; code <= codes.Unauthenticated; code++ {
+		if _, ok := grpcToHTTPStatus[code]; !ok {
+			t.Errorf("no HTTP status for %s", code)
+		}
+	}
+}
+
+func TestGatewayInvokeStatusMapping(t *testing.T) {
+	address, _ := startTestGrpcServer(t)
+	t.Setenv("HOME", t.TempDir())
+	gin.SetMode(gin.TestMode)
+	controller := NewGatewayController(NewCallExecutor(), NewWorkspaceStore())
+	router := gin.New()
+	router.Any("/gateway/:host/:service/:method", controller.Invoke)
+
+	tests := []struct {
+		name     string
+		service  string
+		want     int
+		wantCode string
+	}{
+		{name: "OK", service: "", want: http.StatusOK},
+		{name: "not found", service: "NOT_FOUND", want: http.StatusNotFound, wantCode: "NOT_FOUND"},
+		{name: "invalid argument", service: "INVALID_ARGUMENT", want: http.StatusBadRequest, wantCode: "INVALID_ARGUMENT"},
+		{name: "failed precondition", service: "FAILED_PRECONDITION", want: http.StatusBadRequest, wantCode: "FAILED_PRECONDITION"},
+		{name: "already exists", service: "ALREADY_EXISTS", want: http.StatusConflict, wantCode: "ALREADY_EXISTS"},
+		{name: "permission denied", service: "PERMISSION_DENIED", want: http.StatusForbidden, wantCode: "PERMISSION_DENIED"},
+		{name: "unauthenticated", service: "UNAUTHENTICATED", want: http.StatusUnauthorized, wantCode: "UNAUTHENTICATED"},
+		{name: "resource exhausted", service: "RESOURCE_EXHAUSTED", want: http.StatusTooManyRequests, wantCode: "RESOURCE_EXHAUSTED"},
+		{name: "unavailable", service: "UNAVAILABLE", want: http.StatusServiceUnavailable, wantCode: "UNAVAILABLE"},
+		{name: "deadline exceeded", service: "DEADLINE_EXCEEDED", want: http.StatusGatewayTimeout, wantCode: "DEADLINE_EXCEEDED"},
+		{name: "canceled", service: "CANCELLED", want: 499, wantCode: "CANCELLED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			path := "/gateway/" + address + "/grpc.health.v1.Health/Check?service=" + tt.service
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", recorder.Code, tt.want, recorder.Body)
+			}
+			if got := recorder.Header().Get("Grpc-Metadata-x-served-by"); got != "test" {
+				t.Errorf("Grpc-Metadata-x-served-by = %q, want test", got)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var response models.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Code != tt.wantCode || response.Error == "" {
+				t.Errorf("error response = %+v, want code %s", response, tt.wantCode)
+			}
+		})
+	}
+
+	failures := []struct {
+		name string
+		path string
+		want int
+	}{
+		{name: "unreachable host", path: "/gateway/127.0.0.1:1/grpc.health.v1.Health/Check", want: http.StatusServiceUnavailable},
+		{name: "unknown method", path: "/gateway/" + address + "/grpc.health.v1.Health/Missing", want: http.StatusInternalServerError},
+	}
+	for _, tt := range failures {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if recorder.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, recorder.Code, tt.want)
+		}
+	}
+}
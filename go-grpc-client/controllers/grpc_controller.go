@@ -1,28 +1,32 @@
 package controllers
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"grpc-client/models"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jhump/protoreflect/desc"
-	"github.com/jhump/protoreflect/dynamic"
-	"github.com/jhump/protoreflect/grpcreflect"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/metadata"
-	reflectpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 )
 
-type GrpcController struct{}
+// callAttemptsHeader reports how many attempts a call sent with a retry policy took
+const callAttemptsHeader = "X-Call-Attempts"
 
-func NewGrpcController() *GrpcController {
-	return &GrpcController{}
+type GrpcController struct {
+	executor *CallExecutor
+	store    *WorkspaceStore
+	history  *HistoryStore
+}
+
+func NewGrpcController(executor *CallExecutor, store *WorkspaceStore, history *HistoryStore) *GrpcController {
+	return &GrpcController{
+		executor: executor,
+		store:    store,
+		history:  history,
+	}
 }
 
 func (gc *GrpcController) DefaultEndpoint(c *gin.Context) {
@@ -37,217 +41,139 @@ func (gc *GrpcController) MakeGrpcCall(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Making gRPC call to %s for method %s", grpcRequest.Host, grpcRequest.Method)
-
-	// Create gRPC connection
-	conn, err := gc.createConnection(grpcRequest.Host)
-	if err != nil {
-		log.Printf("Error creating connection: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: fmt.Sprintf("Failed to connect: %v", err)})
+	if !isSupportedProtocol(grpcRequest.Protocol) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Unsupported protocol: %s", grpcRequest.Protocol)})
 		return
 	}
-	defer conn.Close()
-
-	// Execute gRPC call
-	result, err := gc.executeGrpcCall(conn, grpcRequest, c.Request.Header)
-	if err != nil {
-		log.Printf("Error executing gRPC call: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: fmt.Sprintf("gRPC call failed: %v", err)})
+	if err := validateRetryPolicy(grpcRequest.Retry); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	log.Printf("gRPC call completed successfully")
-	c.JSON(http.StatusOK, result)
-}
-
-func (gc *GrpcController) createConnection(host string) (*grpc.ClientConn, error) {
-	return CreateFlexibleConnection(host)
-}
-
-func (gc *GrpcController) executeGrpcCall(conn *grpc.ClientConn, grpcRequest models.GrpcRequest, headers http.Header) (interface{}, error) {
-	// Get method descriptor using reflection
-	methodDesc, err := gc.getMethodDescriptor(conn, grpcRequest.Method)
+	submitted := grpcRequest
+	resolved, undefined, err := gc.resolveRequest(grpcRequest, false)
 	if err != nil {
-		return nil, fmt.Errorf("method descriptor error: %v", err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
 	}
-
-	// Parse request message
-	requestMsg, err := gc.parseRequestMessage(methodDesc.GetInputType(), grpcRequest.Message)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse request message: %v", err)
+	if len(undefined) > 0 {
+		log.Printf("Warning: undefined variables in request: %s", strings.Join(undefined, ", "))
 	}
+	grpcRequest = resolved
 
-	// Create metadata
-	md := gc.createMetadata(grpcRequest, headers)
-
-	// Create context with metadata
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	// Make the call
-	return gc.makeUnaryCall(ctx, conn, methodDesc, requestMsg)
-}
+	log.Printf("Making %s call to %s for method %s", protocolName(grpcRequest.Protocol), grpcRequest.Host, grpcRequest.Method)
 
-func (gc *GrpcController) getMethodDescriptor(conn *grpc.ClientConn, methodName string) (*desc.MethodDescriptor, error) {
-	// Parse method name: "addsvc.Add.Sum" -> service="addsvc.Add", method="Sum"
-	parts := strings.Split(methodName, ".")
-	if len(parts) < 2 {
-		return nil, fmt.Errorf("invalid method name format: %s", methodName)
+	result := gc.executor.ExecuteGrpc(c.Request.Context(), grpcRequest, c.Request.Header)
+	gc.history.Record(submitted, grpcRequest, result)
+	if len(result.Attempts) > 0 {
+		c.Header(callAttemptsHeader, strconv.Itoa(len(result.Attempts)))
 	}
-
-	serviceName := strings.Join(parts[:len(parts)-1], ".")
-	methodShortName := parts[len(parts)-1]
-
-	log.Printf("Looking for service: '%s', method: '%s'", serviceName, methodShortName)
-
-	// Create reflection client
-	refClient := grpcreflect.NewClient(context.Background(), reflectpb.NewServerReflectionClient(conn))
-	defer refClient.Reset()
-
-	// List all services
-	services, err := refClient.ListServices()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list services: %v", err)
+	if !result.Succeeded() {
+		log.Printf("Error executing call: %s", result.Error)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:    result.Error,
+			Code:     result.Status,
+			Details:  result.ErrorDetails,
+			Attempts: result.Attempts,
+		})
+		return
 	}
 
-	log.Printf("Available services: %v", services)
+	log.Printf("Call completed successfully in %dms", result.DurationMs)
+	c.JSON(http.StatusOK, result.Body)
+}
 
-	// Find matching service name
-	actualServiceName := gc.findMatchingServiceName(services, serviceName)
-	if actualServiceName == "" {
-		return nil, fmt.Errorf("service '%s' not found. Available services: %v", serviceName, services)
+// MakeBatchCall executes a list of gRPC requests with a concurrency limit and returns every
+// result, each with its own status, in request order. Requests are validated and resolved
+// before any call is made.
+func (gc *GrpcController) MakeBatchCall(c *gin.Context) {
+	var batchRequest models.BatchRequest
+	if err := c.ShouldBindJSON(&batchRequest); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
 	}
-
-	log.Printf("Using actual service name: '%s'", actualServiceName)
-
-	// Get service descriptor
-	serviceDesc, err := refClient.ResolveService(actualServiceName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve service '%s': %v", actualServiceName, err)
+	if len(batchRequest.Requests) > maxBatchRequests {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("A batch can hold at most %d requests", maxBatchRequests)})
+		return
 	}
 
-	// Find method in service
-	for _, method := range serviceDesc.GetMethods() {
-		if strings.EqualFold(method.GetName(), methodShortName) {
-			log.Printf("Found method: %s", method.GetFullyQualifiedName())
-			return method, nil
+	resolved := make([]models.GrpcRequest, len(batchRequest.Requests))
+	undefined := make([][]string, len(batchRequest.Requests))
+	for i, grpcRequest := range batchRequest.Requests {
+		if !isSupportedProtocol(grpcRequest.Protocol) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Request %d: unsupported protocol: %s", i, grpcRequest.Protocol)})
+			return
 		}
-	}
-
-	return nil, fmt.Errorf("method '%s' not found in service '%s'", methodShortName, actualServiceName)
-}
-
-func (gc *GrpcController) findMatchingServiceName(availableServices []string, requestedServiceName string) string {
-	// Try exact match first
-	for _, service := range availableServices {
-		if service == requestedServiceName {
-			return service
+		if err := validateRetryPolicy(grpcRequest.Retry); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Request %d: %v", i, err)})
+			return
+		}
+		var err error
+		resolved[i], undefined[i], err = gc.resolveRequest(grpcRequest, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Request %d: %v", i, err)})
+			return
 		}
 	}
 
-	// Try matching just the service name part (e.g., "Add" from "addsvc.Add")
-	shortServiceName := requestedServiceName
-	if idx := strings.LastIndex(requestedServiceName, "."); idx != -1 {
-		shortServiceName = requestedServiceName[idx+1:]
-	}
+	log.Printf("Executing batch of %d calls", len(resolved))
 
-	for _, service := range availableServices {
-		if service == shortServiceName {
-			return service
-		}
+	batch := models.BatchResult{
+		Total:     len(resolved),
+		Results:   make([]models.BatchItemResult, len(resolved)),
+		StartedAt: time.Now(),
 	}
-
-	// Try case-insensitive match
-	for _, service := range availableServices {
-		if strings.EqualFold(service, requestedServiceName) || strings.EqualFold(service, shortServiceName) {
-			return service
+	results := gc.executor.ExecuteBatch(c.Request.Context(), resolved, batchRequest.Concurrency, c.Request.Header)
+	calls := make([]historyCall, len(results))
+	for i, result := range results {
+		calls[i] = historyCall{submitted: batchRequest.Requests[i], resolved: resolved[i], result: result}
+		if result.Succeeded() {
+			batch.Succeeded++
+		} else {
+			batch.Failed++
 		}
-	}
-
-	// Try partial match (contains)
-	for _, service := range availableServices {
-		if strings.Contains(strings.ToLower(service), strings.ToLower(shortServiceName)) ||
-			strings.Contains(strings.ToLower(shortServiceName), strings.ToLower(service)) {
-			return service
+		batch.Results[i] = models.BatchItemResult{
+			Index:              i,
+			Host:               resolved[i].Host,
+			Method:             resolved[i].Method,
+			UndefinedVariables: undefined[i],
+			CallResult:         result,
 		}
 	}
+	gc.history.RecordAll(calls)
+	batch.DurationMs = time.Since(batch.StartedAt).Milliseconds()
 
-	return ""
+	c.JSON(http.StatusOK, batch)
 }
 
-func (gc *GrpcController) parseRequestMessage(msgDesc *desc.MessageDescriptor, message interface{}) (*dynamic.Message, error) {
-	if message == nil {
-		message = make(map[string]interface{})
+// ResolveGrpcRequest previews a request after variable substitution without sending it
+func (gc *GrpcController) ResolveGrpcRequest(c *gin.Context) {
+	var grpcRequest models.GrpcRequest
+	if err := c.ShouldBindJSON(&grpcRequest); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
 	}
 
-	// Convert message to JSON and back to ensure proper format
-	jsonBytes, err := json.Marshal(message)
+	resolved, undefined, err := gc.resolveRequest(grpcRequest, true)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal message: %v", err)
-	}
-
-	// Create dynamic message
-	msg := dynamic.NewMessage(msgDesc)
-	if err := msg.UnmarshalJSON(jsonBytes); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal message: %v", err)
-	}
-
-	return msg, nil
-}
-
-func (gc *GrpcController) createMetadata(grpcRequest models.GrpcRequest, headers http.Header) metadata.MD {
-	md := metadata.New(nil)
-
-	// Add metadata from request - now using simple flat object format
-	if grpcRequest.MetaData != nil {
-		for key, value := range grpcRequest.MetaData {
-			if value != "" { // Only add non-empty values
-				md.Append(strings.ToLower(key), value)
-			}
-		}
-	}
-
-	// Add authorization header if present and not already in metadata
-	if authHeader := headers.Get("Authorization"); authHeader != "" {
-		if _, exists := md["authorization"]; !exists {
-			md.Append("authorization", authHeader)
-		}
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
 	}
 
-	return md
+	c.JSON(http.StatusOK, models.ResolvedGrpcRequest{
+		Request:            resolved,
+		UndefinedVariables: undefined,
+	})
 }
 
-func (gc *GrpcController) makeUnaryCall(ctx context.Context, conn *grpc.ClientConn, methodDesc *desc.MethodDescriptor, request *dynamic.Message) (interface{}, error) {
-	log.Printf("Making gRPC unary call to method: %s", methodDesc.GetFullyQualifiedName())
-
-	// Create dynamic stub
-	stub := grpcreflect.NewClient(ctx, reflectpb.NewServerReflectionClient(conn))
-	defer stub.Reset()
-
-	// Prepare method invocation
-	fullMethodName := fmt.Sprintf("/%s/%s", methodDesc.GetService().GetFullyQualifiedName(), methodDesc.GetName())
-
-	// Create response message
-	responseMsg := dynamic.NewMessage(methodDesc.GetOutputType())
-
-	// Invoke method
-	err := conn.Invoke(ctx, fullMethodName, request, responseMsg)
-	if err != nil {
-		return nil, fmt.Errorf("gRPC call failed: %v", err)
-	}
-
-	// Convert response to JSON
-	jsonBytes, err := responseMsg.MarshalJSON()
+// resolveRequest substitutes {{name}} placeholders using the request, environment, collection and workspace variables.
+// A preview keeps {{$env NAME}} placeholders unexpanded.
+func (gc *GrpcController) resolveRequest(grpcRequest models.GrpcRequest, preview bool) (models.GrpcRequest, []string, error) {
+	resolver, _, err := gc.store.ResolverFor(grpcRequest.CollectionID, grpcRequest.EnvironmentID, grpcRequest.Variables)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %v", err)
-	}
-
-	// Parse JSON to interface{}
-	var result interface{}
-	if err := json.Unmarshal(jsonBytes, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response JSON: %v", err)
+		return grpcRequest, nil, err
 	}
-
-	return result, nil
+	resolver.keepEnvPlaceholders = preview
+	resolved := resolver.ResolveGrpcRequest(grpcRequest)
+	return resolved, resolver.Undefined(), nil
 }
@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"fmt"
+	"grpc-client/constants"
+	"grpc-client/models"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// HistoryController lists, replays and saves recorded gRPC calls
+type HistoryController struct {
+	history  *HistoryStore
+	executor *CallExecutor
+	store    *WorkspaceStore
+}
+
+func NewHistoryController(history *HistoryStore, executor *CallExecutor, store *WorkspaceStore) *HistoryController {
+	return &HistoryController{
+		history:  history,
+		executor: executor,
+		store:    store,
+	}
+}
+
+// ListHistory returns recorded calls, newest first. Query parameters: host, method,
+// status, from and to (RFC 3339), limit and offset.
+func (hc *HistoryController) ListHistory(c *gin.Context) {
+	filter, err := parseHistoryFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, hc.history.List(filter))
+}
+
+// GetHistoryStats aggregates the recorded calls matching the ListHistory filters per host
+// and method. The window query parameter (e.g. 15m, 1h, 24h) sets the length of the time
+// windows and defaults to one hour.
+func (hc *HistoryController) GetHistoryStats(c *gin.Context) {
+	filter, err := parseHistoryFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var window time.Duration
+	if value := c.Query("window"); value != "" {
+		window, err = time.ParseDuration(value)
+		if err != nil || window <= 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid window: %s", value)})
+			return
+		}
+	}
+
+	stats, err := hc.history.Stats(filter, window)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// parseHistoryFilter reads the history filters from the query string
+func parseHistoryFilter(c *gin.Context) (historyFilter, error) {
+	filter := historyFilter{
+		Host:   c.Query("host"),
+		Method: c.Query("method"),
+		Status: c.Query("status"),
+		Limit:  100,
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s time: %v", name, err)
+			}
+			*target = parsed
+		}
+	}
+	for name, target := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				return filter, fmt.Errorf("invalid %s: %s", name, value)
+			}
+			*target = parsed
+		}
+	}
+	return filter, nil
+}
+
+// GetHistoryEntry returns a recorded call with its request and response
+func (hc *HistoryController) GetHistoryEntry(c *gin.Context) {
+	entry, err := hc.history.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteHistoryEntry removes a recorded call
+func (hc *HistoryController) DeleteHistoryEntry(c *gin.Context) {
+	if err := hc.history.Delete(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Message: "History entry deleted successfully",
+		Status:  constants.ResponseStatusSuccess,
+	})
+}
+
+// ClearHistory removes all recorded calls
+func (hc *HistoryController) ClearHistory(c *gin.Context) {
+	hc.history.Clear()
+	c.JSON(http.StatusOK, models.Response{
+		Message: "History cleared successfully",
+		Status:  constants.ResponseStatusSuccess,
+	})
+}
+
+// ReplayHistoryEntry sends a recorded call again, resolving its variables with the current
+// values, and returns the new history entry
+func (hc *HistoryController) ReplayHistoryEntry(c *gin.Context) {
+	entry, err := hc.history.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var replay models.ReplayHistoryRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&replay); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+			return
+		}
+	}
+
+	grpcRequest := replayableRequest(*entry.Request)
+	for key, value := range replay.MetaData {
+		if grpcRequest.MetaData == nil {
+			grpcRequest.MetaData = make(map[string]string)
+		}
+		grpcRequest.MetaData[key] = value
+	}
+	if replay.Auth != nil {
+		grpcRequest.Auth = replay.Auth
+	}
+	if replay.EnvironmentID != "" {
+		grpcRequest.EnvironmentID = replay.EnvironmentID
+	}
+
+	resolver, _, err := hc.store.ResolverFor(grpcRequest.CollectionID, grpcRequest.EnvironmentID, grpcRequest.Variables)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	resolved := resolver.ResolveGrpcRequest(grpcRequest)
+
+	log.Printf("Replaying %s call to %s for method %s", protocolName(resolved.Protocol), resolved.Host, resolved.Method)
+
+	result := hc.executor.ExecuteGrpc(c.Request.Context(), resolved, http.Header{})
+	c.JSON(http.StatusOK, hc.history.Record(grpcRequest, resolved, result))
+}
+
+// SaveHistoryEntry saves a recorded call as a new gRPC request of a collection. Redacted
+// secrets are left out.
+func (hc *HistoryController) SaveHistoryEntry(c *gin.Context) {
+	entry, err := hc.history.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var save models.SaveHistoryRequest
+	if err := c.ShouldBindJSON(&save); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	request := historyRequest(replayableRequest(*entry.Request), save.Name)
+
+	found := false
+	err = hc.store.Update(func(workspace *models.Workspace) error {
+		for i := range workspace.Collections {
+			collection := &workspace.Collections[i]
+			if collection.ID == save.CollectionID {
+				request.Order = len(collection.Requests) + 1
+				collection.Requests = append(collection.Requests, request)
+				collection.UpdatedAt = time.Now()
+				found = true
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error saving workspace: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to save request"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Collection not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Request created successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    request,
+	})
+}
+
+// replayableRequest drops the redacted values of a recorded request
+func replayableRequest(grpcRequest models.GrpcRequest) models.GrpcRequest {
+	grpcRequest.MetaData = withoutRedactedValues(grpcRequest.MetaData)
+	grpcRequest.Variables = withoutRedactedValues(grpcRequest.Variables)
+	if grpcRequest.Auth != nil {
+		grpcRequest.Auth = &models.RequestAuth{
+			Type:   grpcRequest.Auth.Type,
+			Config: withoutRedactedValues(grpcRequest.Auth.Config),
+		}
+	}
+	return grpcRequest
+}
+
+// historyRequest converts a recorded call into a saved request
+func historyRequest(grpcRequest models.GrpcRequest, name string) models.Request {
+	service, method := "", grpcRequest.Method
+	if idx := strings.LastIndex(grpcRequest.Method, "."); idx != -1 {
+		service, method = grpcRequest.Method[:idx], grpcRequest.Method[idx+1:]
+	}
+	if name == "" {
+		name = method
+	}
+
+	keys := make([]string, 0, len(grpcRequest.MetaData))
+	for key := range grpcRequest.MetaData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	metadata := make([]models.RequestHeader, 0, len(keys))
+	for _, key := range keys {
+		metadata = append(metadata, models.RequestHeader{Key: key, Value: grpcRequest.MetaData[key], Enabled: true})
+	}
+
+	request := models.Request{
+		ID:   uuid.New().String(),
+		Name: name,
+		Type: models.RequestTypeGRPC,
+		Host: grpcRequest.Host,
+		GRPCConfig: &models.GRPCConfig{
+			Service:     service,
+			Method:      method,
+			Message:     grpcRequest.Message,
+			Metadata:    metadata,
+			Protocol:    grpcRequest.Protocol,
+			Connect:     grpcRequest.Connect,
+			Transcoding: grpcRequest.Transcoding,
+		},
+		Variables: grpcRequest.Variables,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if grpcRequest.Auth != nil {
+		request.Auth = *grpcRequest.Auth
+	}
+	return request
+}
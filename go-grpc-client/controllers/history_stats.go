@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"fmt"
+	"grpc-client/models"
+	"sort"
+	"time"
+)
+
+const (
+	defaultStatsWindow = time.Hour
+	maxStatsWindows    = 1000
+)
+
+// methodKey identifies the calls of one method on one host
+type methodKey struct {
+	host   string
+	method string
+}
+
+// Stats aggregates the entries matching the filter per host and method, with latency
+// statistics per time window. The range defaults to the oldest matching call until now;
+// windows are aligned to multiples of the window length.
+func (hs *HistoryStore) Stats(filter historyFilter, window time.Duration) (models.HistoryStats, error) {
+	if window <= 0 {
+		window = defaultStatsWindow
+	}
+
+	hs.mu.Lock()
+	var entries []models.HistoryEntry
+	for _, entry := range hs.entries {
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	hs.mu.Unlock()
+
+	stats := models.HistoryStats{
+		From:     filter.From,
+		To:       filter.To,
+		WindowMs: window.Milliseconds(),
+		Calls:    len(entries),
+		Methods:  []models.MethodStats{},
+	}
+	if stats.To.IsZero() {
+		stats.To = time.Now()
+	}
+	if stats.From.IsZero() {
+		stats.From = stats.To
+		for _, entry := range entries {
+			if entry.StartedAt.Before(stats.From) {
+				stats.From = entry.StartedAt
+			}
+		}
+	}
+
+	firstWindow := stats.From.Truncate(window)
+	windowCount := int(stats.To.Sub(firstWindow)/window) + 1
+	if windowCount > maxStatsWindows {
+		return stats, fmt.Errorf("the range spans %d windows; use a longer window or a shorter range (at most %d windows)", windowCount, maxStatsWindows)
+	}
+
+	grouped := make(map[methodKey][]models.HistoryEntry)
+	for _, entry := range entries {
+		key := methodKey{host: entry.Host, method: entry.Method}
+		grouped[key] = append(grouped[key], entry)
+	}
+
+	for key, methodEntries := range grouped {
+		stats.Methods = append(stats.Methods, methodStats(key, methodEntries, firstWindow, window, windowCount))
+	}
+	sort.Slice(stats.Methods, func(i, j int) bool {
+		a, b := stats.Methods[i], stats.Methods[j]
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		return a.Method < b.Method
+	})
+	return stats, nil
+}
+
+// methodStats computes the statistics of the calls of one method, given oldest first
+func methodStats(key methodKey, entries []models.HistoryEntry, firstWindow time.Time, window time.Duration, windowCount int) models.MethodStats {
+	stats := models.MethodStats{
+		Host:        key.host,
+		Method:      key.method,
+		Calls:       len(entries),
+		StatusCodes: make(map[string]int),
+		Windows:     make([]models.StatsWindow, windowCount),
+	}
+
+	latencies := make([]float64, 0, len(entries))
+	windowLatencies := make([][]float64, windowCount)
+	for i := range stats.Windows {
+		stats.Windows[i].Start = firstWindow.Add(time.Duration(i) * window)
+	}
+
+	for _, entry := range entries {
+		latency := float64(entry.DurationMs)
+		latencies = append(latencies, latency)
+
+		status := entry.Status
+		if status == "" {
+			status = "UNKNOWN"
+		}
+		stats.StatusCodes[status]++
+
+		succeeded := historyEntrySucceeded(entry)
+		if succeeded {
+			stats.Succeeded++
+		} else {
+			stats.Failed++
+			stats.LastError = &models.CallError{Status: entry.Status, Error: entry.Error, At: entry.StartedAt}
+		}
+		if entry.StartedAt.After(stats.LastCalledAt) {
+			stats.LastCalledAt = entry.StartedAt
+		}
+
+		index := int(entry.StartedAt.Sub(firstWindow) / window)
+		if index >= 0 && index < windowCount {
+			stats.Windows[index].Calls++
+			if !succeeded {
+				stats.Windows[index].Failed++
+			}
+			windowLatencies[index] = append(windowLatencies[index], latency)
+		}
+	}
+
+	stats.SuccessRate = roundMetric(float64(stats.Succeeded) / float64(stats.Calls))
+	sort.Float64s(latencies)
+	stats.Latency = summarizeLatencies(latencies)
+
+	for i, values := range windowLatencies {
+		if len(values) == 0 {
+			continue
+		}
+		sort.Float64s(values)
+		summary := summarizeLatencies(values)
+		stats.Windows[i].Latency = &summary
+	}
+	return stats
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"grpc-client/models"
+	"testing"
+	"time"
+)
+
+func TestHistoryStatsWindows(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	call := func(minutes int, method, status string, durationMs int64) models.HistoryEntry {
+		entry := models.HistoryEntry{
+			Host:       "localhost:50051",
+			Method:     method,
+			Protocol:   models.ProtocolGRPC,
+			Status:     status,
+			StartedAt:  base.Add(time.Duration(minutes) * time.Minute),
+			DurationMs: durationMs,
+		}
+		if status != "OK" {
+			entry.StatusCode = 14
+			entry.Error = "unavailable"
+		}
+		return entry
+	}
+	history := &HistoryStore{entries: []models.HistoryEntry{
+		call(5, "demo.Get", "OK", 10),
+		call(20, "demo.Get", "OK", 30),
+		call(50, "demo.Get", "UNAVAILABLE", 100),
+		call(70, "demo.Get", "OK", 20),
+		call(75, "demo.List", "OK", 5),
+	}}
+
+	stats, err := history.Stats(historyFilter{From: base, To: base.Add(90 * time.Minute)}, 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Calls != 5 || stats.WindowMs != 1800000 || len(stats.Methods) != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	get := stats.Methods[0]
+	if get.Method != "demo.Get" || get.Calls != 4 || get.Succeeded != 3 || get.Failed != 1 || get.SuccessRate != 0.75 {
+		t.Errorf("demo.Get stats = %+v", get)
+	}
+	if get.StatusCodes["OK"] != 3 || get.StatusCodes["UNAVAILABLE"] != 1 {
+		t.Errorf("status codes = %v", get.StatusCodes)
+	}
+	if get.LastError == nil || get.LastError.Status != "UNAVAILABLE" || !get.LastError.At.Equal(base.Add(50*time.Minute)) {
+		t.Errorf("last error = %+v", get.LastError)
+	}
+	if !get.LastCalledAt.Equal(base.Add(70 * time.Minute)) {
+		t.Errorf("last called at = %v", get.LastCalledAt)
+	}
+	if get.Latency.Min != 10 || get.Latency.Max != 100 || get.Latency.P50 != 20 {
+		t.Errorf("latency = %+v", get.Latency)
+	}
+
+	// Windows [10:00, 10:30), [10:30, 11:00), [11:00, 11:30) and [11:30, 12:00) up to the end of the range
+	wantWindows := []struct {
+		calls  int
+		failed int
+		max    float64
+	}{
+		{calls: 2, max: 30},
+		{calls: 1, failed: 1, max: 100},
+		{calls: 1, max: 20},
+		{calls: 0},
+	}
+	if len(get.Windows) != len(wantWindows) {
+		t.Fatalf("demo.Get has %d windows, want %d", len(get.Windows), len(wantWindows))
+	}
+	for i, want := range wantWindows {
+		window := get.Windows[i]
+		if !window.Start.Equal(base.Add(time.Duration(i) * 30 * time.Minute)) {
+			t.Errorf("window %d starts at %v", i, window.Start)
+		}
+		if window.Calls != want.calls || window.Failed != want.failed {
+			t.Errorf("window %d = %d calls, %d failed, want %d, %d", i, window.Calls, window.Failed, want.calls, want.failed)
+		}
+		if want.calls == 0 {
+			if window.Latency != nil {
+				t.Errorf("window %d without calls has latency %+v", i, window.Latency)
+			}
+		} else if window.Latency == nil || window.Latency.Max != want.max {
+			t.Errorf("window %d latency = %+v, want max %v", i, window.Latency, want.max)
+		}
+	}
+
+	list := stats.Methods[1]
+	if list.Method != "demo.List" || list.Calls != 1 || len(list.Windows) != 4 || list.Windows[2].Calls != 1 {
+		t.Errorf("demo.List stats = %+v", list)
+	}
+}
+
+func TestHistoryStatsRange(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 17, 0, 0, time.UTC)
+	history := &HistoryStore{entries: []models.HistoryEntry{
+		{Host: "a", Method: "m", Status: "OK", StartedAt: base},
+		{Host: "b", Method: "m", Status: "OK", StartedAt: base.Add(time.Minute)},
+		{Host: "b", Method: "m", Status: "OK", StartedAt: base.Add(2 * time.Minute)},
+	}}
+
+	// The range starts at the oldest matching call and windows are aligned to the window length
+	stats, err := history.Stats(historyFilter{Host: "b", To: base.Add(10 * time.Minute)}, 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.From.Equal(base.Add(time.Minute)) || stats.Calls != 2 || len(stats.Methods) != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	windows := stats.Methods[0].Windows
+	if len(windows) != 3 || !windows[0].Start.Equal(time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)) || windows[0].Calls != 2 {
+		t.Errorf("windows = %+v", windows)
+	}
+
+	// The default window is an hour
+	stats, err = history.Stats(historyFilter{To: base.Add(time.Hour)}, 0)
+	if err != nil || stats.WindowMs != time.Hour.Milliseconds() || len(stats.Methods[0].Windows) != 2 {
+		t.Errorf("default window stats = %+v, %v", stats, err)
+	}
+
+	if _, err := history.Stats(historyFilter{From: base, To: base.Add(24 * time.Hour)}, time.Second); err == nil {
+		t.Error("a range of more than maxStatsWindows windows should fail")
+	}
+
+	empty, err := (&HistoryStore{}).Stats(historyFilter{}, time.Minute)
+	if err != nil || empty.Calls != 0 || empty.Methods == nil {
+		t.Errorf("empty stats = %+v, %v", empty, err)
+	}
+}
@@ -0,0 +1,370 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"grpc-client/models"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxHistoryEntries = 500 // Older entries are dropped
+	redactedValue     = "[REDACTED]"
+
+	// historySaveDelay batches the changes made within it into one write of the history file
+	historySaveDelay = time.Second
+)
+
+// sensitiveNameParts marks metadata keys, auth settings and variables whose values are redacted
+var sensitiveNameParts = []string{"auth", "token", "secret", "password", "passwd", "cookie", "apikey", "api-key", "api_key", "credential", "session"}
+
+// historyFilter selects history entries; empty fields match everything
+type historyFilter struct {
+	Host   string    // Substring of the host, case-insensitive
+	Method string    // Substring of the method, case-insensitive
+	Status string    // Status name (NOT_FOUND), code (5), "success" or "error"
+	From   time.Time // Calls started at or after
+	To     time.Time // Calls started before
+	Limit  int
+	Offset int
+}
+
+// HistoryStore keeps the most recent gRPC calls in ~/.grpc-client/history.json. Changes are
+// saved in the background shortly after they are made, so calls do not wait for the file.
+type HistoryStore struct {
+	historyFile string
+	mu          sync.Mutex
+	entries     []models.HistoryEntry // Oldest first
+	saveTimer   *time.Timer           // Pending save, if any
+	saveMu      sync.Mutex            // Held while saving, so saves write in order
+}
+
+func NewHistoryStore(store *WorkspaceStore) *HistoryStore {
+	hs := &HistoryStore{
+		historyFile: filepath.Join(store.BaseFolderPath(), "history.json"),
+	}
+
+	content, err := ioutil.ReadFile(hs.historyFile)
+	if err == nil {
+		if err := json.Unmarshal(content, &hs.entries); err != nil {
+			log.Printf("Error reading call history: %v", err)
+		}
+	} else if !os.IsNotExist(err) {
+		log.Printf("Error reading call history: %v", err)
+	}
+	return hs
+}
+
+// historyCall is a call to add to the history. submitted is the request before variable
+// substitution and resolved the request that was sent.
+type historyCall struct {
+	submitted models.GrpcRequest
+	resolved  models.GrpcRequest
+	result    *models.CallResult
+}
+
+// Record adds a call to the history. submitted is the request before variable
+// substitution and resolved the request that was sent.
+func (hs *HistoryStore) Record(submitted, resolved models.GrpcRequest, result *models.CallResult) models.HistoryEntry {
+	return hs.RecordAll([]historyCall{{submitted: submitted, resolved: resolved, result: result}})[0]
+}
+
+// RecordAll adds several calls to the history at once
+func (hs *HistoryStore) RecordAll(calls []historyCall) []models.HistoryEntry {
+	entries := make([]models.HistoryEntry, len(calls))
+	for i, call := range calls {
+		entries[i] = newHistoryEntry(call)
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	hs.entries = append(hs.entries, entries...)
+	if len(hs.entries) > maxHistoryEntries {
+		hs.entries = append([]models.HistoryEntry{}, hs.entries[len(hs.entries)-maxHistoryEntries:]...)
+	}
+	hs.scheduleSave()
+	return entries
+}
+
+// newHistoryEntry builds the entry of a call with secrets redacted from the request metadata
+// and the response headers and trailers
+func newHistoryEntry(call historyCall) models.HistoryEntry {
+	redactedSubmitted := redactGrpcRequest(call.submitted)
+	redactedResolved := redactGrpcRequest(call.resolved)
+	result := call.result
+	protocol := result.Protocol
+	if protocol == "" {
+		protocol = models.ProtocolGRPC
+	}
+
+	response := *result
+	response.Headers = redactHeaderMap(result.Headers)
+	response.Trailers = redactHeaderMap(result.Trailers)
+
+	return models.HistoryEntry{
+		ID:         uuid.New().String(),
+		Host:       call.resolved.Host,
+		Method:     call.resolved.Method,
+		Protocol:   protocol,
+		Status:     result.Status,
+		StatusCode: result.StatusCode,
+		Error:      result.Error,
+		Request:    &redactedSubmitted,
+		Resolved:   &redactedResolved,
+		Response:   &response,
+		StartedAt:  result.StartedAt,
+		DurationMs: result.DurationMs,
+	}
+}
+
+// List returns the entries matching the filter, newest first, without requests and responses
+func (hs *HistoryStore) List(filter historyFilter) models.HistoryList {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	list := models.HistoryList{Entries: []models.HistoryEntry{}}
+	for i := len(hs.entries) - 1; i >= 0; i-- {
+		entry := hs.entries[i]
+		if !filter.matches(entry) {
+			continue
+		}
+		list.Total++
+		if list.Total <= filter.Offset || (filter.Limit > 0 && len(list.Entries) >= filter.Limit) {
+			continue
+		}
+		entry.Request = nil
+		entry.Resolved = nil
+		entry.Response = nil
+		list.Entries = append(list.Entries, entry)
+	}
+	return list
+}
+
+// Get returns an entry with its request and response
+func (hs *HistoryStore) Get(entryID string) (models.HistoryEntry, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	for _, entry := range hs.entries {
+		if entry.ID == entryID {
+			return entry, nil
+		}
+	}
+	return models.HistoryEntry{}, fmt.Errorf("history entry %q not found", entryID)
+}
+
+// Delete removes an entry
+func (hs *HistoryStore) Delete(entryID string) error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	for i, entry := range hs.entries {
+		if entry.ID == entryID {
+			hs.entries = append(hs.entries[:i], hs.entries[i+1:]...)
+			hs.scheduleSave()
+			return nil
+		}
+	}
+	return fmt.Errorf("history entry %q not found", entryID)
+}
+
+// Clear removes all entries
+func (hs *HistoryStore) Clear() {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	hs.entries = nil
+	hs.scheduleSave()
+}
+
+// scheduleSave saves the history after historySaveDelay unless a save is pending already.
+// The caller holds hs.mu.
+func (hs *HistoryStore) scheduleSave() {
+	if hs.saveTimer == nil {
+		hs.saveTimer = time.AfterFunc(historySaveDelay, hs.Flush)
+	}
+}
+
+// Flush saves the history now, replacing a pending save
+func (hs *HistoryStore) Flush() {
+	hs.saveMu.Lock()
+	defer hs.saveMu.Unlock()
+
+	hs.mu.Lock()
+	if hs.saveTimer != nil {
+		hs.saveTimer.Stop()
+		hs.saveTimer = nil
+	}
+	// Entries are never changed once recorded, so a copy of the list is enough
+	entries := append([]models.HistoryEntry{}, hs.entries...)
+	hs.mu.Unlock()
+
+	content, err := json.Marshal(entries)
+	if err == nil {
+		err = ioutil.WriteFile(hs.historyFile, content, 0644)
+	}
+	if err != nil {
+		log.Printf("Error saving call history: %v", err)
+	}
+}
+
+func (filter historyFilter) matches(entry models.HistoryEntry) bool {
+	if filter.Host != "" && !strings.Contains(strings.ToLower(entry.Host), strings.ToLower(filter.Host)) {
+		return false
+	}
+	if filter.Method != "" && !strings.Contains(strings.ToLower(entry.Method), strings.ToLower(filter.Method)) {
+		return false
+	}
+	if filter.Status != "" && !historyStatusMatches(entry, filter.Status) {
+		return false
+	}
+	if !filter.From.IsZero() && entry.StartedAt.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && !entry.StartedAt.Before(filter.To) {
+		return false
+	}
+	return true
+}
+
+func historyStatusMatches(entry models.HistoryEntry, status string) bool {
+	switch strings.ToLower(status) {
+	case "success":
+		return historyEntrySucceeded(entry)
+	case "error":
+		return !historyEntrySucceeded(entry)
+	}
+	if code, err := strconv.Atoi(status); err == nil {
+		return entry.Status != "" && entry.StatusCode == code
+	}
+	return strings.EqualFold(entry.Status, status)
+}
+
+// historyEntrySucceeded classifies a recorded call like CallResult.Succeeded: a 2xx status for
+// transcoded and REST calls, OK for gRPC and Connect calls
+func historyEntrySucceeded(entry models.HistoryEntry) bool {
+	result := models.CallResult{
+		Protocol:   entry.Protocol,
+		StatusCode: entry.StatusCode,
+		Error:      entry.Error,
+	}
+	return result.Succeeded()
+}
+
+// isSensitiveName reports whether a metadata key, auth setting or variable holds a secret
+func isSensitiveName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue hides a secret value. Values referring to variables are kept, since they do not contain the secret.
+func redactValue(value string) string {
+	if value == "" || strings.Contains(value, "{{") {
+		return value
+	}
+	return redactedValue
+}
+
+// redactGrpcRequest returns a copy of the request with secrets in metadata, auth and variables redacted
+func redactGrpcRequest(grpcRequest models.GrpcRequest) models.GrpcRequest {
+	redacted := grpcRequest
+	redacted.MetaData = redactStringMap(grpcRequest.MetaData)
+	redacted.Variables = redactStringMap(grpcRequest.Variables)
+	redacted.Auth = redactAuth(grpcRequest.Auth)
+	return redacted
+}
+
+// redactRestRequest returns a copy of the request with secrets in headers, auth and variables redacted
+func redactRestRequest(restRequest models.RestCallRequest) models.RestCallRequest {
+	redacted := restRequest
+	redacted.RESTConfig.Headers = make([]models.RequestHeader, len(restRequest.RESTConfig.Headers))
+	for i, header := range restRequest.RESTConfig.Headers {
+		if isSensitiveName(header.Key) {
+			header.Value = redactValue(header.Value)
+		}
+		redacted.RESTConfig.Headers[i] = header
+	}
+	redacted.Variables = redactStringMap(restRequest.Variables)
+	redacted.Auth = redactAuth(restRequest.Auth)
+	return redacted
+}
+
+// redactAuth redacts the secret settings of an auth configuration
+func redactAuth(auth *models.RequestAuth) *models.RequestAuth {
+	if auth == nil {
+		return nil
+	}
+	config := make(map[string]string, len(auth.Config))
+	for key, value := range auth.Config {
+		switch key {
+		case "username", "key", "in":
+			config[key] = value
+		default:
+			config[key] = redactValue(value)
+		}
+	}
+	return &models.RequestAuth{Type: auth.Type, Config: config}
+}
+
+func redactStringMap(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	redacted := make(map[string]string, len(values))
+	for key, value := range values {
+		if isSensitiveName(key) {
+			value = redactValue(value)
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// redactHeaderMap returns a copy of response headers or trailers with the values of
+// sensitive names redacted
+func redactHeaderMap(values map[string][]string) map[string][]string {
+	if values == nil {
+		return nil
+	}
+	redacted := make(map[string][]string, len(values))
+	for name, list := range values {
+		if isSensitiveName(name) {
+			masked := make([]string, len(list))
+			for i, value := range list {
+				masked[i] = redactValue(value)
+			}
+			list = masked
+		}
+		redacted[name] = list
+	}
+	return redacted
+}
+
+// withoutRedactedValues drops the entries of a string map whose values were redacted
+func withoutRedactedValues(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	kept := make(map[string]string, len(values))
+	for key, value := range values {
+		if value != redactedValue {
+			kept[key] = value
+		}
+	}
+	return kept
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"grpc-client/models"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestHistoryStatusMatches(t *testing.T) {
+	grpcOK := models.HistoryEntry{Protocol: models.ProtocolGRPC, Status: "OK"}
+	grpcNotFound := models.HistoryEntry{Protocol: models.ProtocolGRPC, Status: "NOT_FOUND", StatusCode: 5}
+	transcodedOK := models.HistoryEntry{Protocol: models.ProtocolTranscoding, Status: "200 OK", StatusCode: 200}
+	transcodedNotFound := models.HistoryEntry{Protocol: models.ProtocolTranscoding, Status: "404 Not Found", StatusCode: 404}
+	connectionFailed := models.HistoryEntry{Protocol: models.ProtocolTranscoding, Error: "connection refused"}
+
+	tests := []struct {
+		name   string
+		entry  models.HistoryEntry
+		status string
+		want   bool
+	}{
+		{name: "gRPC OK is a success", entry: grpcOK, status: "success", want: true},
+		{name: "gRPC error", entry: grpcNotFound, status: "error", want: true},
+		{name: "transcoded 200 is a success", entry: transcodedOK, status: "success", want: true},
+		{name: "transcoded 200 is not an error", entry: transcodedOK, status: "error", want: false},
+		{name: "transcoded 404 is an error", entry: transcodedNotFound, status: "ERROR", want: true},
+		{name: "call without response is an error", entry: connectionFailed, status: "error", want: true},
+		{name: "status code", entry: transcodedNotFound, status: "404", want: true},
+		{name: "status code 0 needs a status", entry: connectionFailed, status: "0", want: false},
+		{name: "status name", entry: grpcNotFound, status: "not_found", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := historyStatusMatches(tt.entry, tt.status); got != tt.want {
+				t.Errorf("historyStatusMatches(%q) = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHistoryEntryRedactsResponseHeaders(t *testing.T) {
+	result := &models.CallResult{
+		Protocol: models.ProtocolTranscoding,
+		Headers: map[string][]string{
+			"set-cookie":   {"session=abc", "theme=dark"},
+			"content-type": {"application/json"},
+		},
+		Trailers: map[string][]string{"x-auth-token": {"t1"}},
+	}
+	call := historyCall{
+		submitted: models.GrpcRequest{MetaData: map[string]string{"authorization": "{{token}}"}},
+		resolved:  models.GrpcRequest{Host: "localhost:50051", MetaData: map[string]string{"authorization": "Bearer t1"}},
+		result:    result,
+	}
+
+	entry := newHistoryEntry(call)
+
+	wantHeaders := map[string][]string{
+		"set-cookie":   {redactedValue, redactedValue},
+		"content-type": {"application/json"},
+	}
+	if !reflect.DeepEqual(entry.Response.Headers, wantHeaders) {
+		t.Errorf("response headers = %v, want %v", entry.Response.Headers, wantHeaders)
+	}
+	if got := entry.Response.Trailers["x-auth-token"]; !reflect.DeepEqual(got, []string{redactedValue}) {
+		t.Errorf("response trailer = %v, want redacted", got)
+	}
+	if got := result.Headers["set-cookie"][0]; got != "session=abc" {
+		t.Errorf("live response header = %q, want it unchanged", got)
+	}
+	if got := entry.Request.MetaData["authorization"]; got != "{{token}}" {
+		t.Errorf("submitted metadata = %q, want the placeholder kept", got)
+	}
+	if got := entry.Resolved.MetaData["authorization"]; got != redactedValue {
+		t.Errorf("resolved metadata = %q, want it redacted", got)
+	}
+}
+
+func TestHistoryStoreSavesInBackground(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store := NewWorkspaceStore()
+	history := NewHistoryStore(store)
+
+	result := &models.CallResult{Protocol: models.ProtocolGRPC, Status: "OK", Body: map[string]interface{}{"id": "1"}}
+	for i := 0; i < 3; i++ {
+		history.Record(models.GrpcRequest{Host: "localhost:50051"}, models.GrpcRequest{Host: "localhost:50051"}, result)
+	}
+	if _, err := os.Stat(history.historyFile); !os.IsNotExist(err) {
+		t.Fatalf("history file written while recording: %v", err)
+	}
+
+	history.mu.Lock()
+	pending := history.saveTimer != nil
+	history.mu.Unlock()
+	if !pending {
+		t.Fatal("no save scheduled after recording")
+	}
+
+	history.Flush()
+	reloaded := NewHistoryStore(store)
+	if got := reloaded.List(historyFilter{}).Total; got != 3 {
+		t.Errorf("saved history holds %d entries, want 3", got)
+	}
+
+	history.Clear()
+	history.Flush()
+	reloaded = NewHistoryStore(store)
+	if got := reloaded.List(historyFilter{}).Total; got != 0 {
+		t.Errorf("cleared history holds %d entries, want 0", got)
+	}
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"grpc-client/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jobKeepAliveInterval is how often an idle event stream sends a comment to keep the connection open
+const jobKeepAliveInterval = 15 * time.Second
+
+// JobController starts, tracks and cancels background jobs
+type JobController struct {
+	jobs     *JobManager
+	runner   *RequestRunner
+	executor *CallExecutor
+	notifier *NotificationDispatcher
+}
+
+func NewJobController(jobs *JobManager, runner *RequestRunner, executor *CallExecutor, notifier *NotificationDispatcher) *JobController {
+	return &JobController{
+		jobs:     jobs,
+		runner:   runner,
+		executor: executor,
+		notifier: notifier,
+	}
+}
+
+// StartJob validates the job and starts it in the background, returning the job right away
+func (jc *JobController) StartJob(c *gin.Context) {
+	var request models.StartJobRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	var job models.Job
+	switch request.Type {
+	case models.JobTypeCollectionRun:
+		var options models.CollectionRunOptions
+		if err := decodeJobOptions(request.Options, &options); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		collection, err := jc.runner.FindCollection(request.TargetID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		total := len(selectRunRequests(collection.Requests, options.RequestIDs, options.Tags)) * collectionRunIterations(options)
+		job = jc.jobs.Start(request, total, func(ctx context.Context, state *jobState) (interface{}, error) {
+			result, err := jc.runner.RunCollection(ctx, collection, options, func(item models.CollectionRunItem) {
+				state.advance("item", item, !item.Passed)
+			})
+			if err != nil {
+				return nil, err
+			}
+			jc.notifier.notifyCollectionRun(ctx, result)
+			return result, nil
+		})
+
+	case models.JobTypeWorkflowRun:
+		var options models.RunRequestOptions
+		if err := decodeJobOptions(request.Options, &options); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		workflow, err := jc.runner.FindWorkflow(request.TargetID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		// forEach steps can run more than once, so the total is not known in advance
+		job = jc.jobs.Start(request, 0, func(ctx context.Context, state *jobState) (interface{}, error) {
+			result, err := jc.runner.RunWorkflow(ctx, workflow, options, func(entry models.WorkflowTraceEntry) {
+				state.advance("step", entry, entry.State == models.WorkflowStepFailed)
+			})
+			if err != nil {
+				return nil, err
+			}
+			jc.notifier.notifyWorkflowRun(ctx, result, options.EnvironmentID)
+			return result, nil
+		})
+
+	case models.JobTypeBenchmark:
+		var options models.BenchmarkRequest
+		if err := decodeJobOptions(request.Options, &options); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err := validateBenchmarkOptions(options); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		grpcRequest, _, err := jc.runner.ResolveBenchmarkRequest(options)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		total := options.TotalRequests
+		if total == 0 && options.DurationMs == 0 {
+			total = defaultBenchmarkRequests
+		}
+		job = jc.jobs.Start(request, total, func(ctx context.Context, state *jobState) (interface{}, error) {
+			result, err := jc.executor.Benchmark(ctx, grpcRequest, options, state.setProgress)
+			if err != nil {
+				return nil, err
+			}
+			state.setProgress(result.Total, result.Failed)
+			return result, nil
+		})
+
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Unsupported job type: %s", request.Type)})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ListJobs returns all jobs without their results, newest first
+func (jc *JobController) ListJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, jc.jobs.List())
+}
+
+// GetJob returns a job with its progress and, once finished, its result
+func (jc *JobController) GetJob(c *gin.Context) {
+	job, err := jc.jobs.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// GetJobReport returns the result of a finished collection run job as a JUnit XML, HTML or
+// JSON summary report, selected with ?format= (default junit)
+func (jc *JobController) GetJobReport(c *gin.Context) {
+	job, err := jc.jobs.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if job.Type != models.JobTypeCollectionRun {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Reports are only available for collection run jobs"})
+		return
+	}
+	if job.Result == nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: fmt.Sprintf("Job has no result (status %s)", job.Status)})
+		return
+	}
+
+	format, err := lookupRunReportFormat(c.DefaultQuery("format", "junit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Jobs loaded from disk hold their result as decoded JSON
+	result, ok := job.Result.(*models.CollectionRunResult)
+	if !ok {
+		result = &models.CollectionRunResult{}
+		content, err := json.Marshal(job.Result)
+		if err == nil {
+			err = json.Unmarshal(content, result)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: fmt.Sprintf("Failed to read job result: %v", err)})
+			return
+		}
+	}
+	writeRunReport(c, result, format)
+}
+
+// CancelJob stops a running job
+func (jc *JobController) CancelJob(c *gin.Context) {
+	if _, err := jc.jobs.Get(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	job, err := jc.jobs.Cancel(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
+
+// DeleteJob removes a finished job
+func (jc *JobController) DeleteJob(c *gin.Context) {
+	if _, err := jc.jobs.Get(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := jc.jobs.Delete(c.Param("id")); err != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// StreamJobEvents streams the events of a job as server-sent events until the job has
+// finished. Events after the "after" query parameter (or Last-Event-ID header) are replayed
+// first, so a client can reconnect without missing events.
+func (jc *JobController) StreamJobEvents(c *gin.Context) {
+	state, notify, unsubscribe, err := jc.jobs.subscribe(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer unsubscribe()
+
+	after := 0
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		after, _ = strconv.Atoi(lastEventID)
+	}
+	if query := c.Query("after"); query != "" {
+		after, _ = strconv.Atoi(query)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	keepAlive := time.NewTicker(jobKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		events, finished := state.eventsAfter(after)
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, strings.ReplaceAll(string(data), "\n", ""))
+			after = event.Sequence
+		}
+		c.Writer.Flush()
+		if finished {
+			return
+		}
+
+		select {
+		case <-notify:
+		case <-keepAlive.C:
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// decodeJobOptions decodes the type-specific options of a job
+func decodeJobOptions(options json.RawMessage, target interface{}) error {
+	if len(options) == 0 || string(options) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(options, target); err != nil {
+		return fmt.Errorf("invalid options: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"grpc-client/models"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxJobEvents    = 1000 // Older events of a job are dropped
+	maxFinishedJobs = 100  // Older finished jobs that are not persisted are dropped
+)
+
+// jobFunc carries out a job, reporting progress through its state. The context is
+// cancelled when the job is cancelled.
+type jobFunc func(ctx context.Context, state *jobState) (interface{}, error)
+
+// jobState is a job together with its events and the channels of its subscribers
+type jobState struct {
+	mu           sync.Mutex
+	job          models.Job
+	events       []models.JobEvent
+	nextSequence int // Sequence of the last event
+	subscribers  map[chan struct{}]bool
+	cancel       context.CancelFunc
+	cancelled    bool
+}
+
+// persistedJob is the file format of a persisted job
+type persistedJob struct {
+	Job    models.Job        `json:"job"`
+	Events []models.JobEvent `json:"events"`
+}
+
+// JobManager runs collection runs, workflow runs and benchmarks in the background. Jobs
+// are kept in memory; jobs started with persist are also saved under ~/.grpc-client/jobs
+// when they finish and loaded again on startup.
+type JobManager struct {
+	folder string
+	mu     sync.Mutex
+	jobs   map[string]*jobState
+}
+
+func NewJobManager(store *WorkspaceStore) *JobManager {
+	jm := &JobManager{
+		folder: filepath.Join(store.BaseFolderPath(), "jobs"),
+		jobs:   make(map[string]*jobState),
+	}
+	jm.loadPersisted()
+	return jm
+}
+
+// Start runs fn in the background and returns the new job
+func (jm *JobManager) Start(request models.StartJobRequest, total int, fn jobFunc) models.Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	state := &jobState{
+		job: models.Job{
+			ID:        uuid.New().String(),
+			Type:      request.Type,
+			TargetID:  request.TargetID,
+			Status:    models.JobStatusRunning,
+			Progress:  models.JobProgress{Total: total},
+			Persist:   request.Persist,
+			CreatedAt: time.Now(),
+		},
+		subscribers: make(map[chan struct{}]bool),
+		cancel:      cancel,
+	}
+	state.emit("started", nil)
+
+	jm.mu.Lock()
+	jm.jobs[state.job.ID] = state
+	jm.pruneLocked()
+	jm.mu.Unlock()
+
+	log.Printf("Started %s job %s", request.Type, state.job.ID)
+
+	go func() {
+		defer cancel()
+		result, err := fn(ctx, state)
+		state.finish(result, err)
+		if request.Persist {
+			if err := jm.persist(state); err != nil {
+				log.Printf("Error persisting job %s: %v", state.job.ID, err)
+			}
+		}
+	}()
+
+	return state.snapshot(true)
+}
+
+// Get returns a job with its result
+func (jm *JobManager) Get(jobID string) (models.Job, error) {
+	state, err := jm.find(jobID)
+	if err != nil {
+		return models.Job{}, err
+	}
+	return state.snapshot(true), nil
+}
+
+// List returns all jobs without their results, newest first
+func (jm *JobManager) List() []models.Job {
+	jm.mu.Lock()
+	jobs := make([]models.Job, 0, len(jm.jobs))
+	for _, state := range jm.jobs {
+		jobs = append(jobs, state.snapshot(false))
+	}
+	jm.mu.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// Cancel stops a running job. The job finishes as cancelled once the run has stopped.
+func (jm *JobManager) Cancel(jobID string) (models.Job, error) {
+	state, err := jm.find(jobID)
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	state.mu.Lock()
+	if state.job.Status != models.JobStatusRunning {
+		state.mu.Unlock()
+		return models.Job{}, fmt.Errorf("job %q is not running", jobID)
+	}
+	state.cancelled = true
+	state.mu.Unlock()
+
+	state.cancel()
+	log.Printf("Cancelling job %s", jobID)
+	return state.snapshot(false), nil
+}
+
+// Delete removes a finished job from memory and disk
+func (jm *JobManager) Delete(jobID string) error {
+	state, err := jm.find(jobID)
+	if err != nil {
+		return err
+	}
+	if state.snapshot(false).Status == models.JobStatusRunning {
+		return fmt.Errorf("job %q is still running; cancel it first", jobID)
+	}
+
+	jm.mu.Lock()
+	delete(jm.jobs, jobID)
+	jm.mu.Unlock()
+
+	if err := os.Remove(jm.jobFile(jobID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// subscribe returns the job and a channel that is signalled whenever it has new events.
+// The returned function ends the subscription.
+func (jm *JobManager) subscribe(jobID string) (*jobState, <-chan struct{}, func(), error) {
+	state, err := jm.find(jobID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	notify := make(chan struct{}, 1)
+	state.mu.Lock()
+	state.subscribers[notify] = true
+	state.mu.Unlock()
+
+	unsubscribe := func() {
+		state.mu.Lock()
+		delete(state.subscribers, notify)
+		state.mu.Unlock()
+	}
+	return state, notify, unsubscribe, nil
+}
+
+func (jm *JobManager) find(jobID string) (*jobState, error) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	state, ok := jm.jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("job %q not found", jobID)
+	}
+	return state, nil
+}
+
+// pruneLocked drops the oldest finished jobs that are not persisted beyond maxFinishedJobs
+func (jm *JobManager) pruneLocked() {
+	var finished []models.Job
+	for _, state := range jm.jobs {
+		job := state.snapshot(false)
+		if job.Status != models.JobStatusRunning && !job.Persist {
+			finished = append(finished, job)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CreatedAt.Before(finished[j].CreatedAt)
+	})
+	for _, job := range finished[:len(finished)-maxFinishedJobs] {
+		delete(jm.jobs, job.ID)
+	}
+}
+
+func (jm *JobManager) jobFile(jobID string) string {
+	return filepath.Join(jm.folder, jobID+".json")
+}
+
+func (jm *JobManager) persist(state *jobState) error {
+	state.mu.Lock()
+	content, err := json.MarshalIndent(persistedJob{Job: state.job, Events: state.events}, "", "  ")
+	state.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(jm.folder, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(jm.jobFile(state.job.ID), content, 0644)
+}
+
+// loadPersisted loads the jobs saved by earlier runs of the application
+func (jm *JobManager) loadPersisted() {
+	files, err := ioutil.ReadDir(jm.folder)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading jobs folder: %v", err)
+		}
+		return
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(jm.folder, file.Name()))
+		if err != nil {
+			log.Printf("Error reading job file %s: %v", file.Name(), err)
+			continue
+		}
+		var persisted persistedJob
+		if err := json.Unmarshal(content, &persisted); err != nil || persisted.Job.ID == "" {
+			log.Printf("Skipping invalid job file %s", file.Name())
+			continue
+		}
+
+		state := &jobState{
+			job:         persisted.Job,
+			events:      persisted.Events,
+			subscribers: make(map[chan struct{}]bool),
+			cancel:      func() {},
+		}
+		if len(persisted.Events) > 0 {
+			state.nextSequence = persisted.Events[len(persisted.Events)-1].Sequence
+		}
+		jm.jobs[state.job.ID] = state
+	}
+}
+
+// emit records an event and wakes up the subscribers
+func (js *jobState) emit(eventType string, data interface{}) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.emitLocked(eventType, data)
+}
+
+func (js *jobState) emitLocked(eventType string, data interface{}) {
+	js.nextSequence++
+	js.events = append(js.events, models.JobEvent{
+		Sequence: js.nextSequence,
+		Type:     eventType,
+		Time:     time.Now(),
+		Data:     data,
+	})
+	if len(js.events) > maxJobEvents {
+		js.events = js.events[len(js.events)-maxJobEvents:]
+	}
+
+	for notify := range js.subscribers {
+		select {
+		case notify <- struct{}{}:
+		default: // Already signalled
+		}
+	}
+}
+
+// advance counts a finished unit of work and records it as an event
+func (js *jobState) advance(eventType string, data interface{}, failed bool) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.job.Progress.Completed++
+	if failed {
+		js.job.Progress.Failed++
+	}
+	js.emitLocked(eventType, data)
+}
+
+// setProgress replaces the progress counters and records a progress event
+func (js *jobState) setProgress(completed, failed int) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.job.Progress.Completed = completed
+	js.job.Progress.Failed = failed
+	js.emitLocked("progress", js.job.Progress)
+}
+
+// finish stores the outcome of the job and records the final event
+func (js *jobState) finish(result interface{}, err error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	now := time.Now()
+	js.job.FinishedAt = &now
+	js.job.DurationMs = now.Sub(js.job.CreatedAt).Milliseconds()
+	if err == nil {
+		js.job.Result = result
+	}
+
+	switch {
+	case js.cancelled:
+		js.job.Status = models.JobStatusCancelled
+	case err != nil:
+		js.job.Status = models.JobStatusFailed
+		js.job.Error = err.Error()
+	default:
+		js.job.Status = models.JobStatusCompleted
+	}
+
+	js.emitLocked("finished", map[string]interface{}{
+		"status": js.job.Status,
+		"error":  js.job.Error,
+	})
+	log.Printf("Job %s finished as %s in %dms", js.job.ID, js.job.Status, js.job.DurationMs)
+}
+
+// snapshot returns a copy of the job, optionally without its result
+func (js *jobState) snapshot(withResult bool) models.Job {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	job := js.job
+	if !withResult {
+		job.Result = nil
+	}
+	return job
+}
+
+// eventsAfter returns the events with a sequence above after, and whether the job has finished
+func (js *jobState) eventsAfter(after int) ([]models.JobEvent, bool) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	var events []models.JobEvent
+	for _, event := range js.events {
+		if event.Sequence > after {
+			events = append(events, event)
+		}
+	}
+	return events, js.job.Status != models.JobStatusRunning
+}
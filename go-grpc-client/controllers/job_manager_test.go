@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"grpc-client/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestJobManager(t *testing.T) (*JobManager, *WorkspaceStore) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	store := NewWorkspaceStore()
+	return NewJobManager(store), store
+}
+
+// waitForJob waits until a job has finished and returns it
+func waitForJob(t *testing.T, jobs *JobManager, jobID string) models.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := jobs.Get(jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != models.JobStatusRunning {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", jobID)
+	return models.Job{}
+}
+
+func TestJobManagerCancel(t *testing.T) {
+	jobs, _ := newTestJobManager(t)
+
+	started := make(chan struct{})
+	job := jobs.Start(models.StartJobRequest{Type: models.JobTypeBenchmark}, 10, func(ctx context.Context, state *jobState) (interface{}, error) {
+		state.advance("item", nil, false)
+		close(started)
+		<-ctx.Done()
+		return "partial", ctx.Err()
+	})
+	<-started
+
+	if err := jobs.Delete(job.ID); err == nil {
+		t.Error("deleting a running job should fail")
+	}
+	if _, err := jobs.Cancel(job.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	finished := waitForJob(t, jobs, job.ID)
+	if finished.Status != models.JobStatusCancelled || finished.Error != "" {
+		t.Errorf("status = %s, error = %q, want cancelled without error", finished.Status, finished.Error)
+	}
+	if finished.Progress.Completed != 1 || finished.Progress.Total != 10 || finished.FinishedAt == nil {
+		t.Errorf("job = %+v", finished)
+	}
+	if _, err := jobs.Cancel(job.ID); err == nil {
+		t.Error("cancelling a finished job should fail")
+	}
+	if _, err := jobs.Cancel("missing"); err == nil {
+		t.Error("cancelling an unknown job should fail")
+	}
+	if err := jobs.Delete(job.ID); err != nil {
+		t.Errorf("deleting a finished job: %v", err)
+	}
+	if _, err := jobs.Get(job.ID); err == nil {
+		t.Error("deleted job is still listed")
+	}
+}
+
+func TestJobManagerOutcomes(t *testing.T) {
+	jobs, store := newTestJobManager(t)
+
+	completed := jobs.Start(models.StartJobRequest{Type: models.JobTypeCollectionRun, TargetID: "c1", Persist: true}, 1, func(ctx context.Context, state *jobState) (interface{}, error) {
+		state.advance("item", map[string]interface{}{"n": 1}, true)
+		return map[string]interface{}{"passed": false}, nil
+	})
+	failed := jobs.Start(models.StartJobRequest{Type: models.JobTypeWorkflowRun}, 0, func(ctx context.Context, state *jobState) (interface{}, error) {
+		return nil, errors.New("workflow not found")
+	})
+
+	job := waitForJob(t, jobs, completed.ID)
+	if job.Status != models.JobStatusCompleted || job.Progress.Failed != 1 || job.Result == nil {
+		t.Errorf("completed job = %+v", job)
+	}
+	job = waitForJob(t, jobs, failed.ID)
+	if job.Status != models.JobStatusFailed || job.Error != "workflow not found" {
+		t.Errorf("failed job = %+v", job)
+	}
+
+	// Only the persisted job is loaded again
+	reloaded := NewJobManager(store)
+	if list := reloaded.List(); len(list) != 1 || list[0].ID != completed.ID || list[0].Result != nil {
+		t.Fatalf("reloaded jobs = %+v, want only the persisted job without its result", list)
+	}
+	state, err := reloaded.find(completed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, finished := state.eventsAfter(0)
+	if !finished || len(events) != 3 || events[2].Type != "finished" || state.nextSequence != 3 {
+		t.Errorf("reloaded events = %+v, finished = %v", events, finished)
+	}
+}
+
+func TestJobStateKeepsLatestEvents(t *testing.T) {
+	state := &jobState{subscribers: make(map[chan struct{}]bool)}
+	for i := 0; i < maxJobEvents+5; i++ {
+		state.emit("item", i)
+	}
+
+	events, _ := state.eventsAfter(0)
+	if len(events) != maxJobEvents || events[0].Sequence != 6 || events[len(events)-1].Sequence != maxJobEvents+5 {
+		t.Errorf("kept %d events from %d to %d", len(events), events[0].Sequence, events[len(events)-1].Sequence)
+	}
+	if events, _ := state.eventsAfter(maxJobEvents + 3); len(events) != 2 {
+		t.Errorf("events after %d = %d, want 2", maxJobEvents+3, len(events))
+	}
+}
+
+func TestStreamJobEvents(t *testing.T) {
+	jobs, _ := newTestJobManager(t)
+	gin.SetMode(gin.TestMode)
+	controller := NewJobController(jobs, nil, nil, nil)
+	router := gin.New()
+	router.GET("/jobs/:id/events", controller.StreamJobEvents)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	release := make(chan struct{})
+	job := jobs.Start(models.StartJobRequest{Type: models.JobTypeCollectionRun}, 2, func(ctx context.Context, state *jobState) (interface{}, error) {
+		state.advance("item", nil, false)
+		<-release
+		state.advance("item", nil, false)
+		return nil, nil
+	})
+
+	// A stream opened while the job runs replays the earlier events and follows the new ones
+	done := make(chan string)
+	go func() {
+		done <- readEventStream(t, server.URL+"/jobs/"+job.ID+"/events", "")
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	var body string
+	select {
+	case body = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event stream did not end when the job finished")
+	}
+	if got := eventIDs(body); got != "1 2 3 4" {
+		t.Errorf("streamed event ids = %q, want 1 2 3 4\n%s", got, body)
+	}
+	if !strings.Contains(body, "event: started\n") || !strings.Contains(body, "event: finished\n") {
+		t.Errorf("stream is missing the started or finished event:\n%s", body)
+	}
+
+	// Reconnecting replays only the events after the last one received
+	if got := eventIDs(readEventStream(t, server.URL+"/jobs/"+job.ID+"/events?after=2", "")); got != "3 4" {
+		t.Errorf("event ids after 2 = %q, want 3 4", got)
+	}
+	if got := eventIDs(readEventStream(t, server.URL+"/jobs/"+job.ID+"/events", "3")); got != "4" {
+		t.Errorf("event ids after Last-Event-ID 3 = %q, want 4", got)
+	}
+
+	resp, err := http.Get(server.URL + "/jobs/missing/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown job status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func readEventStream(t *testing.T, url, lastEventID string) string {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Error(err)
+		return ""
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Error(err)
+		return ""
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("content type = %q, want text/event-stream", got)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+// eventIDs returns the ids of the events in a stream, separated by spaces
+func eventIDs(stream string) string {
+	var ids []string
+	for _, line := range strings.Split(stream, "\n") {
+		if strings.HasPrefix(line, "id: ") {
+			ids = append(ids, strings.TrimPrefix(line, "id: "))
+		}
+	}
+	return strings.Join(ids, " ")
+}
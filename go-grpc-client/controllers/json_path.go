@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPathSegment is one step of a JSON path: an object key, an array index or a wildcard
+type jsonPathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseJSONPath parses paths such as "$.items[0].id", "items[*].name", "$['odd key']" or
+// "$.items[-1]". The leading "$" is optional, so jq-style paths (".items[].id") work too.
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+
+	var segments []jsonPathSegment
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			continue
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket in path %q", path)
+			}
+			inner := strings.TrimSpace(path[i+1 : i+end])
+			i += end + 1
+
+			switch {
+			case inner == "*" || inner == "":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, jsonPathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q in path", inner)
+				}
+				segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			}
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			key := path[i : i+end]
+			i += end
+			if key == "*" {
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			} else {
+				segments = append(segments, jsonPathSegment{key: key})
+			}
+		}
+	}
+	return segments, nil
+}
+
+// evaluateJSONPath returns the value at a JSON path. Paths with a wildcard return the list
+// of all matches and are found when at least one value matched.
+func evaluateJSONPath(document interface{}, path string) (interface{}, bool, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	matches := []interface{}{document}
+	hasWildcard := false
+	for _, segment := range segments {
+		var next []interface{}
+		for _, value := range matches {
+			next = append(next, applyJSONPathSegment(value, segment)...)
+		}
+		if segment.wildcard {
+			hasWildcard = true
+		}
+		matches = next
+	}
+
+	if hasWildcard {
+		if matches == nil {
+			matches = []interface{}{}
+		}
+		return matches, len(matches) > 0, nil
+	}
+	if len(matches) == 0 {
+		return nil, false, nil
+	}
+	return matches[0], true, nil
+}
+
+func applyJSONPathSegment(value interface{}, segment jsonPathSegment) []interface{} {
+	switch {
+	case segment.wildcard:
+		switch v := value.(type) {
+		case []interface{}:
+			return v
+		case map[string]interface{}:
+			values := make([]interface{}, 0, len(v))
+			for _, item := range v {
+				values = append(values, item)
+			}
+			return values
+		}
+	case segment.isIndex:
+		if list, ok := value.([]interface{}); ok {
+			index := segment.index
+			if index < 0 {
+				index += len(list)
+			}
+			if index >= 0 && index < len(list) {
+				return []interface{}{list[index]}
+			}
+		}
+	default:
+		if object, ok := value.(map[string]interface{}); ok {
+			if item, ok := object[segment.key]; ok {
+				return []interface{}{item}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    []jsonPathSegment
+		wantErr bool
+	}{
+		{name: "root", path: "$", want: nil},
+		{name: "dotted keys", path: "$.items.id", want: []jsonPathSegment{{key: "items"}, {key: "id"}}},
+		{name: "without root", path: "items[0].id", want: []jsonPathSegment{{key: "items"}, {index: 0, isIndex: true}, {key: "id"}}},
+		{name: "negative index", path: "$.items[-1]", want: []jsonPathSegment{{key: "items"}, {index: -1, isIndex: true}}},
+		{name: "wildcard index", path: "$.items[*].name", want: []jsonPathSegment{{key: "items"}, {wildcard: true}, {key: "name"}}},
+		{name: "wildcard key", path: "$.*", want: []jsonPathSegment{{wildcard: true}}},
+		{name: "single quoted key", path: "$['odd key'].value", want: []jsonPathSegment{{key: "odd key"}, {key: "value"}}},
+		{name: "double quoted key", path: `$["a.b"]`, want: []jsonPathSegment{{key: "a.b"}}},
+		{name: "jq style", path: ".items[].id", want: []jsonPathSegment{{key: "items"}, {wildcard: true}, {key: "id"}}},
+		{name: "surrounding spaces", path: "  $.id  ", want: []jsonPathSegment{{key: "id"}}},
+		{name: "unclosed bracket", path: "$.items[0", wantErr: true},
+		{name: "invalid index", path: "$.items[first]", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJSONPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJSONPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseJSONPath(%q) = %+v, want %+v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateJSONPath(t *testing.T) {
+	var document interface{}
+	err := json.Unmarshal([]byte(`{
+		"id": "42",
+		"odd key": {"value": true},
+		"items": [{"id": 1, "name": "a"}, {"id": 2, "name": "b"}, {"id": 3}],
+		"empty": []
+	}`), &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		want      interface{}
+		wantFound bool
+	}{
+		{name: "root", path: "$", want: document, wantFound: true},
+		{name: "key", path: "$.id", want: "42", wantFound: true},
+		{name: "index", path: "$.items[1].name", want: "b", wantFound: true},
+		{name: "negative index", path: "$.items[-1].id", want: float64(3), wantFound: true},
+		{name: "negative index out of range", path: "$.items[-4]", wantFound: false},
+		{name: "index out of range", path: "$.items[3]", wantFound: false},
+		{name: "quoted key", path: "$['odd key'].value", want: true, wantFound: true},
+		{name: "missing key", path: "$.missing", wantFound: false},
+		{name: "index on object", path: "$.id[0]", wantFound: false},
+		{name: "wildcard", path: "$.items[*].id", want: []interface{}{float64(1), float64(2), float64(3)}, wantFound: true},
+		{name: "wildcard skips missing fields", path: "$.items[*].name", want: []interface{}{"a", "b"}, wantFound: true},
+		{name: "jq style wildcard", path: ".items[].id", want: []interface{}{float64(1), float64(2), float64(3)}, wantFound: true},
+		{name: "wildcard without matches", path: "$.empty[*]", want: []interface{}{}, wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := evaluateJSONPath(document, tt.path)
+			if err != nil {
+				t.Fatalf("evaluateJSONPath(%q) error = %v", tt.path, err)
+			}
+			if found != tt.wantFound {
+				t.Fatalf("evaluateJSONPath(%q) found = %v, want %v", tt.path, found, tt.wantFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("evaluateJSONPath(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
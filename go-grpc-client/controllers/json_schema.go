@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// validateJSONSchema checks a decoded JSON value against a JSON schema and returns the violations.
+// The common validation keywords are supported: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength, pattern, minimum and maximum.
+func validateJSONSchema(value interface{}, schema interface{}) []string {
+	var violations []string
+	validateJSONSchemaAt("$", value, schema, &violations)
+	return violations
+}
+
+func validateJSONSchemaAt(path string, value interface{}, schema interface{}, violations *[]string) {
+	rules, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+	addViolation := func(format string, args ...interface{}) {
+		*violations = append(*violations, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if expected, ok := rules["type"]; ok && !matchesSchemaType(value, expected) {
+		addViolation("expected type %v, got %s", expected, jsonTypeName(value))
+		return
+	}
+
+	if enum, ok := rules["enum"].([]interface{}); ok {
+		found := false
+		for _, item := range enum {
+			if valuesEqual(value, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			addViolation("value %v is not one of %v", value, enum)
+		}
+	}
+	if constant, ok := rules["const"]; ok && !valuesEqual(value, constant) {
+		addViolation("expected %v, got %v", constant, value)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := rules["required"].([]interface{}); ok {
+			for _, name := range required {
+				if key, ok := name.(string); ok {
+					if _, present := v[key]; !present {
+						addViolation("missing required property %q", key)
+					}
+				}
+			}
+		}
+
+		properties, _ := rules["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if propertySchema, ok := properties[key]; ok {
+				validateJSONSchemaAt(path+"."+key, v[key], propertySchema, violations)
+				continue
+			}
+			switch additional := rules["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					addViolation("unexpected property %q", key)
+				}
+			case map[string]interface{}:
+				validateJSONSchemaAt(path+"."+key, v[key], additional, violations)
+			}
+		}
+	case []interface{}:
+		if min, ok := schemaNumber(rules, "minItems"); ok && float64(len(v)) < min {
+			addViolation("expected at least %v items, got %d", min, len(v))
+		}
+		if max, ok := schemaNumber(rules, "maxItems"); ok && float64(len(v)) > max {
+			addViolation("expected at most %v items, got %d", max, len(v))
+		}
+		if items, ok := rules["items"]; ok {
+			for i, item := range v {
+				validateJSONSchemaAt(fmt.Sprintf("%s[%d]", path, i), item, items, violations)
+			}
+		}
+	case string:
+		length := float64(len([]rune(v)))
+		if min, ok := schemaNumber(rules, "minLength"); ok && length < min {
+			addViolation("expected at least %v characters, got %v", min, length)
+		}
+		if max, ok := schemaNumber(rules, "maxLength"); ok && length > max {
+			addViolation("expected at most %v characters, got %v", max, length)
+		}
+		if pattern, ok := rules["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err != nil {
+				addViolation("invalid pattern %q: %v", pattern, err)
+			} else if !re.MatchString(v) {
+				addViolation("value %q does not match pattern %q", v, pattern)
+			}
+		}
+	case float64:
+		if min, ok := schemaNumber(rules, "minimum"); ok && v < min {
+			addViolation("value %v is less than minimum %v", v, min)
+		}
+		if max, ok := schemaNumber(rules, "maximum"); ok && v > max {
+			addViolation("value %v is greater than maximum %v", v, max)
+		}
+	}
+}
+
+func matchesSchemaType(value interface{}, expected interface{}) bool {
+	switch types := expected.(type) {
+	case string:
+		return matchesSingleSchemaType(value, types)
+	case []interface{}:
+		for _, item := range types {
+			if name, ok := item.(string); ok && matchesSingleSchemaType(value, name) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func matchesSingleSchemaType(value interface{}, typeName string) bool {
+	actual := jsonTypeName(value)
+	switch strings.ToLower(typeName) {
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "number":
+		return actual == "number"
+	default:
+		return actual == strings.ToLower(typeName)
+	}
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func schemaNumber(rules map[string]interface{}, keyword string) (float64, bool) {
+	number, ok := rules[keyword].(float64)
+	return number, ok
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		schema string
+		want   []string
+	}{
+		{name: "no rules", value: `{"a": 1}`, schema: `{}`},
+		{name: "type matches", value: `"text"`, schema: `{"type": "string"}`},
+		{name: "type mismatch", value: `1`, schema: `{"type": "string"}`, want: []string{"$: expected type string, got number"}},
+		{name: "one of several types", value: `null`, schema: `{"type": ["string", "null"]}`},
+		{name: "integer", value: `3`, schema: `{"type": "integer"}`},
+		{name: "integer with fraction", value: `3.5`, schema: `{"type": "integer"}`, want: []string{"$: expected type integer, got number"}},
+		{name: "enum", value: `"b"`, schema: `{"enum": ["a", "b"]}`},
+		{name: "enum mismatch", value: `"c"`, schema: `{"enum": ["a", "b"]}`, want: []string{"$: value c is not one of [a b]"}},
+		{name: "const", value: `5`, schema: `{"const": 6}`, want: []string{"$: expected 6, got 5"}},
+		{
+			name:   "required and nested properties",
+			value:  `{"id": "1", "item": {"count": "x"}}`,
+			schema: `{"required": ["id", "name"], "properties": {"item": {"properties": {"count": {"type": "number"}}}}}`,
+			want:   []string{`$: missing required property "name"`, "$.item.count: expected type number, got string"},
+		},
+		{
+			name:   "no additional properties",
+			value:  `{"id": "1", "extra": true}`,
+			schema: `{"properties": {"id": {"type": "string"}}, "additionalProperties": false}`,
+			want:   []string{`$: unexpected property "extra"`},
+		},
+		{
+			name:   "additional properties schema",
+			value:  `{"a": 1, "b": "two"}`,
+			schema: `{"additionalProperties": {"type": "number"}}`,
+			want:   []string{"$.b: expected type number, got string"},
+		},
+		{
+			name:   "array items and length",
+			value:  `[1, "two"]`,
+			schema: `{"minItems": 3, "items": {"type": "number"}}`,
+			want:   []string{"$: expected at least 3 items, got 2", "$[1]: expected type number, got string"},
+		},
+		{name: "max items", value: `[1, 2]`, schema: `{"maxItems": 1}`, want: []string{"$: expected at most 1 items, got 2"}},
+		{name: "string length counts runes", value: `"héé"`, schema: `{"minLength": 3, "maxLength": 3}`},
+		{name: "string too long", value: `"abcd"`, schema: `{"maxLength": 3}`, want: []string{"$: expected at most 3 characters, got 4"}},
+		{name: "pattern", value: `"abc"`, schema: `{"pattern": "^[0-9]+$"}`, want: []string{`$: value "abc" does not match pattern "^[0-9]+$"`}},
+		{name: "invalid pattern", value: `"abc"`, schema: `{"pattern": "("}`, want: []string{"$: invalid pattern \"(\": error parsing regexp: missing closing ): `(`"}},
+		{name: "minimum and maximum", value: `[0, 11]`, schema: `{"items": {"minimum": 1, "maximum": 10}}`, want: []string{"$[0]: value 0 is less than minimum 1", "$[1]: value 11 is greater than maximum 10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value, schema interface{}
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.schema), &schema); err != nil {
+				t.Fatal(err)
+			}
+			if got := validateJSONSchema(value, schema); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateJSONSchema() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"fmt"
+	"grpc-client/constants"
+	"grpc-client/models"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MonitorController manages monitors stored in the workspace and reports their status
+type MonitorController struct {
+	scheduler *MonitorScheduler
+	runner    *RequestRunner
+	store     *WorkspaceStore
+}
+
+func NewMonitorController(scheduler *MonitorScheduler, runner *RequestRunner, store *WorkspaceStore) *MonitorController {
+	return &MonitorController{
+		scheduler: scheduler,
+		runner:    runner,
+		store:     store,
+	}
+}
+
+// ListMonitors returns all monitors of the workspace
+func (mc *MonitorController) ListMonitors(c *gin.Context) {
+	workspace, err := mc.store.Load()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
+		return
+	}
+
+	monitors := workspace.Monitors
+	if monitors == nil {
+		monitors = []models.Monitor{}
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Monitors loaded successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    monitors,
+	})
+}
+
+// CreateMonitor validates, stores and schedules a new monitor
+func (mc *MonitorController) CreateMonitor(c *gin.Context) {
+	var monitor models.Monitor
+	if err := c.ShouldBindJSON(&monitor); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if monitor.Name == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Monitor name is required"})
+		return
+	}
+	if err := mc.validateMonitor(&monitor); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// IDs are always generated, since they name the file holding the monitor's checks
+	monitor.ID = uuid.New().String()
+	monitor.CreatedAt = time.Now()
+	monitor.UpdatedAt = time.Now()
+
+	err := mc.store.Update(func(workspace *models.Workspace) error {
+		workspace.Monitors = append(workspace.Monitors, monitor)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error saving monitor: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: fmt.Sprintf("Failed to create monitor: %v", err)})
+		return
+	}
+	mc.reload()
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Monitor created successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    monitor,
+	})
+}
+
+// UpdateMonitor replaces the definition of an existing monitor and reschedules it
+func (mc *MonitorController) UpdateMonitor(c *gin.Context) {
+	monitorID := c.Param("id")
+
+	var monitor models.Monitor
+	if err := c.ShouldBindJSON(&monitor); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if err := mc.validateMonitor(&monitor); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	found := false
+	err := mc.store.Update(func(workspace *models.Workspace) error {
+		for i := range workspace.Monitors {
+			if workspace.Monitors[i].ID == monitorID {
+				monitor.ID = monitorID
+				monitor.CreatedAt = workspace.Monitors[i].CreatedAt
+				monitor.UpdatedAt = time.Now()
+				if monitor.Name == "" {
+					monitor.Name = workspace.Monitors[i].Name
+				}
+				workspace.Monitors[i] = monitor
+				found = true
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error saving monitor: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update monitor"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Monitor not found"})
+		return
+	}
+	mc.reload()
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Monitor updated successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    monitor,
+	})
+}
+
+// DeleteMonitor stops and removes a monitor along with its recorded checks
+func (mc *MonitorController) DeleteMonitor(c *gin.Context) {
+	monitorID := c.Param("id")
+
+	found := false
+	err := mc.store.Update(func(workspace *models.Workspace) error {
+		for i := range workspace.Monitors {
+			if workspace.Monitors[i].ID == monitorID {
+				workspace.Monitors = append(workspace.Monitors[:i], workspace.Monitors[i+1:]...)
+				found = true
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error saving workspace: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to delete monitor"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Monitor not found"})
+		return
+	}
+	mc.reload()
+	mc.scheduler.Forget(monitorID)
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Monitor deleted successfully",
+		Status:  constants.ResponseStatusSuccess,
+	})
+}
+
+// MonitorStatuses returns the current state, uptime and latency of every monitor
+func (mc *MonitorController) MonitorStatuses(c *gin.Context) {
+	workspace, err := mc.store.Load()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
+		return
+	}
+
+	statuses := make([]models.MonitorStatus, 0, len(workspace.Monitors))
+	for _, monitor := range workspace.Monitors {
+		statuses = append(statuses, mc.scheduler.Status(monitor))
+	}
+	c.JSON(http.StatusOK, statuses)
+}
+
+// MonitorChecks returns the recorded checks of a monitor, newest first; limit defaults to 100
+func (mc *MonitorController) MonitorChecks(c *gin.Context) {
+	monitor, err := mc.findMonitor(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	limit := 100
+	if value := c.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid limit: %s", value)})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, mc.scheduler.Checks(monitor.ID, limit))
+}
+
+// RunMonitor runs a check of a monitor right away and returns it
+func (mc *MonitorController) RunMonitor(c *gin.Context) {
+	monitor, err := mc.findMonitor(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, mc.scheduler.RunNow(c.Request.Context(), *monitor))
+}
+
+// validateMonitor checks the schedule of a monitor and that its target exists
+func (mc *MonitorController) validateMonitor(monitor *models.Monitor) error {
+	if err := validateMonitor(monitor); err != nil {
+		return err
+	}
+	if monitor.RequestID != "" {
+		_, _, err := mc.runner.FindRequest(monitor.RequestID)
+		return err
+	}
+	_, err := mc.runner.FindCollection(monitor.CollectionID)
+	return err
+}
+
+func (mc *MonitorController) findMonitor(monitorID string) (*models.Monitor, error) {
+	workspace, err := mc.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range workspace.Monitors {
+		if workspace.Monitors[i].ID == monitorID {
+			return &workspace.Monitors[i], nil
+		}
+	}
+	return nil, fmt.Errorf("monitor %q not found", monitorID)
+}
+
+func (mc *MonitorController) reload() {
+	if err := mc.scheduler.Reload(); err != nil {
+		log.Printf("Error rescheduling monitors: %v", err)
+	}
+}
@@ -0,0 +1,428 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"grpc-client/models"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	minMonitorInterval  = 5 * time.Second
+	maxMonitorChecks    = 500 // Older checks of a monitor are dropped
+	monitorCheckTimeout = 5 * time.Minute
+)
+
+// monitorIDPattern matches monitor IDs that are safe to use as file names
+var monitorIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// scheduledMonitor is an enabled monitor with the goroutine that runs it
+type scheduledMonitor struct {
+	monitor   models.Monitor
+	cancel    context.CancelFunc
+	nextRunAt time.Time
+}
+
+// MonitorScheduler runs the enabled monitors of the workspace on their schedules and keeps
+// the recent checks of every monitor under ~/.grpc-client/monitors. The notifiers are told
+// when a monitor goes down or recovers.
+type MonitorScheduler struct {
+	runner    *RequestRunner
+	store     *WorkspaceStore
+	notifier  *NotificationDispatcher
+	folder    string
+	mu        sync.Mutex
+	scheduled map[string]*scheduledMonitor
+	checks    map[string][]models.MonitorCheck // Oldest first
+}
+
+func NewMonitorScheduler(runner *RequestRunner, store *WorkspaceStore, notifier *NotificationDispatcher) *MonitorScheduler {
+	return &MonitorScheduler{
+		runner:    runner,
+		store:     store,
+		notifier:  notifier,
+		folder:    filepath.Join(store.BaseFolderPath(), "monitors"),
+		scheduled: make(map[string]*scheduledMonitor),
+		checks:    make(map[string][]models.MonitorCheck),
+	}
+}
+
+// Start loads the recorded checks and schedules the enabled monitors
+func (ms *MonitorScheduler) Start() error {
+	ms.loadChecks()
+	return ms.Reload()
+}
+
+// Reload brings the scheduled monitors in line with the workspace: new and changed
+// monitors are (re)scheduled, removed and disabled ones stopped
+func (ms *MonitorScheduler) Reload() error {
+	workspace, err := ms.store.Load()
+	if err != nil {
+		return err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	enabled := make(map[string]models.Monitor)
+	for _, monitor := range workspace.Monitors {
+		if monitor.Enabled {
+			enabled[monitor.ID] = monitor
+		}
+	}
+
+	for id, scheduled := range ms.scheduled {
+		if monitor, ok := enabled[id]; !ok || !monitor.UpdatedAt.Equal(scheduled.monitor.UpdatedAt) {
+			scheduled.cancel()
+			delete(ms.scheduled, id)
+		}
+	}
+	for id, monitor := range enabled {
+		if _, ok := ms.scheduled[id]; ok {
+			continue
+		}
+		schedule, err := monitorSchedule(monitor)
+		if err != nil {
+			log.Printf("Not scheduling monitor %s: %v", monitor.Name, err)
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		scheduled := &scheduledMonitor{monitor: monitor, cancel: cancel}
+		ms.scheduled[id] = scheduled
+		go ms.loop(ctx, scheduled, schedule)
+	}
+	return nil
+}
+
+// Forget drops the recorded checks of a deleted monitor
+func (ms *MonitorScheduler) Forget(monitorID string) {
+	ms.mu.Lock()
+	delete(ms.checks, monitorID)
+	ms.mu.Unlock()
+
+	checksFile, err := ms.checksFile(monitorID)
+	if err == nil {
+		err = os.Remove(checksFile)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("Error removing checks of monitor %s: %v", monitorID, err)
+	}
+}
+
+// RunNow runs a check of the monitor right away, outside its schedule
+func (ms *MonitorScheduler) RunNow(ctx context.Context, monitor models.Monitor) models.MonitorCheck {
+	check := ms.check(ctx, monitor)
+	ms.record(monitor, check)
+	return check
+}
+
+// Status summarizes the state and recorded checks of a monitor
+func (ms *MonitorScheduler) Status(monitor models.Monitor) models.MonitorStatus {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	checks := ms.checks[monitor.ID]
+	status := models.MonitorStatus{
+		MonitorID: monitor.ID,
+		Name:      monitor.Name,
+		State:     models.MonitorStatePending,
+		Checks:    len(checks),
+	}
+	if scheduled, ok := ms.scheduled[monitor.ID]; ok && !scheduled.nextRunAt.IsZero() {
+		nextRunAt := scheduled.nextRunAt
+		status.NextRunAt = &nextRunAt
+	}
+
+	if len(checks) > 0 {
+		lastCheck := checks[len(checks)-1]
+		status.LastCheck = &lastCheck
+		status.State = models.MonitorStateDown
+		if lastCheck.Passed {
+			status.State = models.MonitorStateUp
+		}
+
+		latencies := make([]float64, 0, len(checks))
+		for _, check := range checks {
+			latencies = append(latencies, float64(check.DurationMs))
+			if !check.Passed {
+				status.Failures++
+			}
+		}
+		for i := len(checks) - 1; i >= 0 && !checks[i].Passed; i-- {
+			status.ConsecutiveFailures++
+		}
+		status.Uptime = roundMetric(float64(len(checks)-status.Failures) / float64(len(checks)))
+		sort.Float64s(latencies)
+		status.Latency = summarizeLatencies(latencies)
+	}
+	if !monitor.Enabled {
+		status.State = models.MonitorStatePaused
+	}
+	return status
+}
+
+// Checks returns the recorded checks of a monitor, newest first
+func (ms *MonitorScheduler) Checks(monitorID string, limit int) []models.MonitorCheck {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	recorded := ms.checks[monitorID]
+	checks := make([]models.MonitorCheck, 0, len(recorded))
+	for i := len(recorded) - 1; i >= 0 && (limit <= 0 || len(checks) < limit); i-- {
+		checks = append(checks, recorded[i])
+	}
+	return checks
+}
+
+// loop runs the monitor at each scheduled time until ctx is cancelled
+func (ms *MonitorScheduler) loop(ctx context.Context, scheduled *scheduledMonitor, schedule cron.Schedule) {
+	log.Printf("Scheduled monitor %s", scheduled.monitor.Name)
+
+	for {
+		nextRunAt := schedule.Next(time.Now())
+		ms.mu.Lock()
+		scheduled.nextRunAt = nextRunAt
+		ms.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(nextRunAt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			log.Printf("Stopped monitor %s", scheduled.monitor.Name)
+			return
+		}
+
+		check := ms.check(ctx, scheduled.monitor)
+		if ctx.Err() != nil {
+			continue // Stopped during the check; the check is incomplete
+		}
+		ms.record(scheduled.monitor, check)
+	}
+}
+
+// check runs the monitor's saved request or collection once
+func (ms *MonitorScheduler) check(ctx context.Context, monitor models.Monitor) models.MonitorCheck {
+	ctx, cancel := context.WithTimeout(ctx, monitorCheckTimeout)
+	defer cancel()
+
+	check := models.MonitorCheck{StartedAt: time.Now()}
+	variables := make(map[string]string, len(monitor.Variables))
+	for key, value := range monitor.Variables {
+		variables[key] = value
+	}
+
+	if monitor.RequestID != "" {
+		check.Total = 1
+		request, collection, err := ms.runner.FindRequest(monitor.RequestID)
+		if err == nil {
+			var run *models.RequestRunResult
+			run, err = ms.runner.RunRequest(ctx, request, collection, models.RunRequestOptions{
+				EnvironmentID: monitor.EnvironmentID,
+				Variables:     variables,
+			})
+			if err == nil {
+				check.Passed = run.Passed
+				check.Status = run.Status
+				check.DurationMs = run.DurationMs
+				if !run.Passed {
+					check.Failed = 1
+					check.Error = runFailureReason(run)
+				}
+				return check
+			}
+		}
+		check.Failed = 1
+		check.Error = err.Error()
+		check.DurationMs = time.Since(check.StartedAt).Milliseconds()
+		return check
+	}
+
+	collection, err := ms.runner.FindCollection(monitor.CollectionID)
+	if err == nil {
+		var result *models.CollectionRunResult
+		result, err = ms.runner.RunCollection(ctx, collection, models.CollectionRunOptions{
+			EnvironmentID: monitor.EnvironmentID,
+			Variables:     variables,
+		}, nil)
+		if err == nil {
+			check.Passed = result.Failed == 0 && !result.Stopped
+			check.Total = result.Total
+			check.Failed = result.Failed
+			check.DurationMs = result.DurationMs
+			for _, item := range result.Results {
+				if !item.Passed {
+					check.Error = fmt.Sprintf("%s: %s", item.RequestName, collectionItemFailure(item))
+					break
+				}
+			}
+			if result.Stopped && check.Error == "" {
+				check.Error = "collection run stopped before completing"
+			}
+			return check
+		}
+	}
+	check.Error = err.Error()
+	check.DurationMs = time.Since(check.StartedAt).Milliseconds()
+	return check
+}
+
+// record stores a check, saves the monitor's checks and notifies a change between passing
+// and failing. The first check of a monitor is notified only when it fails. The file is
+// written under the lock, so checks finishing together cannot save an older list last.
+func (ms *MonitorScheduler) record(monitor models.Monitor, check models.MonitorCheck) {
+	ms.mu.Lock()
+	previous := ms.checks[monitor.ID]
+	changed := check.Passed != (len(previous) == 0 || previous[len(previous)-1].Passed)
+	checks := append(previous, check)
+	if len(checks) > maxMonitorChecks {
+		checks = append([]models.MonitorCheck{}, checks[len(checks)-maxMonitorChecks:]...)
+	}
+	ms.checks[monitor.ID] = checks
+	if err := ms.saveChecks(monitor.ID, checks); err != nil {
+		log.Printf("Error saving checks of monitor %s: %v", monitor.Name, err)
+	}
+	ms.mu.Unlock()
+
+	if check.Passed {
+		log.Printf("Monitor %s passed in %dms", monitor.Name, check.DurationMs)
+	} else {
+		log.Printf("Monitor %s failed: %s", monitor.Name, check.Error)
+	}
+	if changed {
+		ms.notifier.Notify(monitorNotification(monitor, ms.monitorCollectionID(monitor), check))
+	}
+}
+
+// saveChecks writes the checks of a monitor to its file; the caller holds ms.mu
+func (ms *MonitorScheduler) saveChecks(monitorID string, checks []models.MonitorCheck) error {
+	checksFile, err := ms.checksFile(monitorID)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(checks)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(ms.folder, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(checksFile, content, 0644)
+}
+
+// monitorCollectionID returns the collection a monitor runs, or the collection of its request
+func (ms *MonitorScheduler) monitorCollectionID(monitor models.Monitor) string {
+	if monitor.CollectionID != "" {
+		return monitor.CollectionID
+	}
+	if _, collection, err := ms.runner.FindRequest(monitor.RequestID); err == nil {
+		return collection.ID
+	}
+	return ""
+}
+
+// checksFile returns the file holding the checks of a monitor. IDs that could leave the
+// monitors folder are rejected.
+func (ms *MonitorScheduler) checksFile(monitorID string) (string, error) {
+	if !monitorIDPattern.MatchString(monitorID) {
+		return "", fmt.Errorf("invalid monitor ID %q", monitorID)
+	}
+	return filepath.Join(ms.folder, monitorID+".json"), nil
+}
+
+// loadChecks loads the checks recorded before the last restart
+func (ms *MonitorScheduler) loadChecks() {
+	files, err := ioutil.ReadDir(ms.folder)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading monitors folder: %v", err)
+		}
+		return
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, file := range files {
+		monitorID := strings.TrimSuffix(file.Name(), ".json")
+		if file.IsDir() || monitorID == file.Name() || !monitorIDPattern.MatchString(monitorID) {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(ms.folder, file.Name()))
+		if err != nil {
+			log.Printf("Error reading monitor checks %s: %v", file.Name(), err)
+			continue
+		}
+		var checks []models.MonitorCheck
+		if err := json.Unmarshal(content, &checks); err != nil {
+			log.Printf("Skipping invalid monitor checks %s", file.Name())
+			continue
+		}
+		ms.checks[monitorID] = checks
+	}
+}
+
+// monitorSchedule parses the interval or cron expression of a monitor
+func monitorSchedule(monitor models.Monitor) (cron.Schedule, error) {
+	switch {
+	case monitor.IntervalSeconds != 0 && monitor.Cron != "":
+		return nil, fmt.Errorf("set either intervalSeconds or cron, not both")
+	case monitor.IntervalSeconds != 0:
+		interval := time.Duration(monitor.IntervalSeconds) * time.Second
+		if interval < minMonitorInterval {
+			return nil, fmt.Errorf("intervalSeconds must be at least %d", int(minMonitorInterval.Seconds()))
+		}
+		return cron.Every(interval), nil
+	case monitor.Cron != "":
+		schedule, err := cron.ParseStandard(monitor.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression: %v", err)
+		}
+		return schedule, nil
+	}
+	return nil, fmt.Errorf("either intervalSeconds or cron is required")
+}
+
+// validateMonitor checks the target and schedule of a monitor
+func validateMonitor(monitor *models.Monitor) error {
+	if (monitor.RequestID == "") == (monitor.CollectionID == "") {
+		return fmt.Errorf("set either requestId or collectionId")
+	}
+	_, err := monitorSchedule(*monitor)
+	return err
+}
+
+// collectionItemFailure describes why a request of a collection run did not pass
+func collectionItemFailure(item models.CollectionRunItem) string {
+	if item.Error != "" {
+		return item.Error
+	}
+	if item.ScriptError != "" {
+		return item.ScriptError
+	}
+	for _, assertion := range item.Assertions {
+		if !assertion.Passed {
+			return fmt.Sprintf("assertion %s failed: %s", assertion.Assertion.Type, assertion.Message)
+		}
+	}
+	for _, test := range item.ScriptTests {
+		if !test.Passed {
+			return fmt.Sprintf("test %q failed: %s", test.Name, test.Error)
+		}
+	}
+	if item.Snapshot != nil && !item.Snapshot.Matched {
+		return snapshotFailure(item.Snapshot)
+	}
+	return fmt.Sprintf("call returned %s", item.Status)
+}
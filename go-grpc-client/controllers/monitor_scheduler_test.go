@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"encoding/json"
+	"grpc-client/models"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestMonitorChecksFile(t *testing.T) {
+	scheduler := &MonitorScheduler{folder: "/data/monitors"}
+
+	tests := []struct {
+		name      string
+		monitorID string
+		want      string
+		wantErr   bool
+	}{
+		{name: "uuid", monitorID: "0f8fad5b-d9cb-469f-a165-70867728950e", want: "/data/monitors/0f8fad5b-d9cb-469f-a165-70867728950e.json"},
+		{name: "name with underscore", monitorID: "nightly_check", want: "/data/monitors/nightly_check.json"},
+		{name: "parent folder", monitorID: "../history", wantErr: true},
+		{name: "nested path", monitorID: "../../../tmp/x", wantErr: true},
+		{name: "absolute path", monitorID: "/tmp/x", wantErr: true},
+		{name: "dot", monitorID: ".", wantErr: true},
+		{name: "empty", monitorID: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scheduler.checksFile(tt.monitorID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checksFile(%q) error = %v, wantErr %v", tt.monitorID, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("checksFile(%q) = %q, want %q", tt.monitorID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitorRecordStaysInFolder(t *testing.T) {
+	base := t.TempDir()
+	scheduler := &MonitorScheduler{
+		folder: filepath.Join(base, "monitors"),
+		checks: make(map[string][]models.MonitorCheck),
+	}
+	historyFile := filepath.Join(base, "history.json")
+	if err := ioutil.WriteFile(historyFile, []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := models.MonitorCheck{Passed: true}
+	scheduler.record(models.Monitor{ID: "../history", Name: "bad"}, check)
+	scheduler.Forget("../history")
+	if content, err := ioutil.ReadFile(historyFile); err != nil || string(content) != "[]" {
+		t.Errorf("file outside the monitors folder changed: %q, %v", content, err)
+	}
+
+	scheduler.record(models.Monitor{ID: "good", Name: "good"}, check)
+	if _, err := os.Stat(filepath.Join(scheduler.folder, "good.json")); err != nil {
+		t.Errorf("checks of a valid monitor were not saved: %v", err)
+	}
+
+	reloaded := &MonitorScheduler{folder: scheduler.folder, checks: make(map[string][]models.MonitorCheck)}
+	if err := ioutil.WriteFile(filepath.Join(scheduler.folder, "bad id.json"), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reloaded.loadChecks()
+	if len(reloaded.checks) != 1 || len(reloaded.checks["good"]) != 1 {
+		t.Errorf("loadChecks() = %v, want only the checks of monitor good", reloaded.checks)
+	}
+}
+
+func TestMonitorRecordSavesLatestChecks(t *testing.T) {
+	scheduler := &MonitorScheduler{
+		folder: filepath.Join(t.TempDir(), "monitors"),
+		checks: make(map[string][]models.MonitorCheck),
+	}
+	monitor := models.Monitor{ID: "concurrent", Name: "concurrent"}
+
+	const checks = 50
+	var wg sync.WaitGroup
+	for i := 0; i < checks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scheduler.record(monitor, models.MonitorCheck{Passed: true})
+		}()
+	}
+	wg.Wait()
+
+	content, err := ioutil.ReadFile(filepath.Join(scheduler.folder, "concurrent.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved []models.MonitorCheck
+	if err := json.Unmarshal(content, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != checks {
+		t.Errorf("saved %d checks, want %d", len(saved), checks)
+	}
+}
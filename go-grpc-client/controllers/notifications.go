@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"grpc-client/models"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	notificationTimeout   = 30 * time.Second
+	maxNotificationOutput = 4096 // Longer webhook responses and command output are truncated
+
+	// notifierCommandsEnv lists the executables command notifiers may run, separated like PATH.
+	// Command notifiers are disabled when it is not set.
+	notifierCommandsEnv = "GRPC_CLIENT_NOTIFIER_COMMANDS"
+)
+
+// NotificationDispatcher sends notifications about failed runs to the enabled notifiers of
+// the workspace
+type NotificationDispatcher struct {
+	store  *WorkspaceStore
+	client *http.Client
+}
+
+func NewNotificationDispatcher(store *WorkspaceStore) *NotificationDispatcher {
+	return &NotificationDispatcher{
+		store:  store,
+		client: &http.Client{Timeout: notificationTimeout},
+	}
+}
+
+// Notify sends the notification in the background to every enabled notifier whose filters
+// match it. Delivery failures are logged.
+func (nd *NotificationDispatcher) Notify(notification models.Notification) {
+	workspace, err := nd.store.Load()
+	if err != nil {
+		log.Printf("Error loading notifiers: %v", err)
+		return
+	}
+	if notification.Time.IsZero() {
+		notification.Time = time.Now()
+	}
+
+	for _, notifier := range workspace.Notifiers {
+		if !notifierMatches(notifier, notification) {
+			continue
+		}
+		go func(notifier models.Notifier) {
+			ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+			defer cancel()
+
+			delivery := nd.Deliver(ctx, notifier, notification)
+			if !delivery.Delivered {
+				log.Printf("Error notifying %s of %s: %s", notifier.Name, notification.Event, delivery.Error)
+				return
+			}
+			log.Printf("Notified %s of %s", notifier.Name, notification.Event)
+		}(notifier)
+	}
+}
+
+// Deliver sends a notification to a notifier and waits for the outcome
+func (nd *NotificationDispatcher) Deliver(ctx context.Context, notifier models.Notifier, notification models.Notification) models.NotificationDelivery {
+	startTime := time.Now()
+	delivery := models.NotificationDelivery{NotifierID: notifier.ID}
+
+	payload, err := json.Marshal(notification)
+	if err == nil {
+		switch notifier.Type {
+		case models.NotifierTypeWebhook:
+			err = nd.postWebhook(ctx, notifier, payload, &delivery)
+		case models.NotifierTypeCommand:
+			err = runNotifierCommand(ctx, notifier, notification, payload, &delivery)
+		default:
+			err = fmt.Errorf("unsupported notifier type: %s", notifier.Type)
+		}
+	}
+
+	delivery.DurationMs = time.Since(startTime).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	delivery.Delivered = true
+	return delivery
+}
+
+// notifyRequestRun notifies a failed run of a saved request, unless the run was cancelled
+func (nd *NotificationDispatcher) notifyRequestRun(ctx context.Context, run *models.RequestRunResult, collection *models.Collection) {
+	if ctx.Err() == nil && !run.Passed {
+		nd.Notify(requestRunNotification(run, collection))
+	}
+}
+
+// notifyCollectionRun notifies a collection run with failing requests, unless the run was cancelled
+func (nd *NotificationDispatcher) notifyCollectionRun(ctx context.Context, result *models.CollectionRunResult) {
+	if ctx.Err() == nil && result.Failed > 0 {
+		nd.Notify(collectionRunNotification(result))
+	}
+}
+
+// notifyWorkflowRun notifies a failed workflow run, unless the run was cancelled
+func (nd *NotificationDispatcher) notifyWorkflowRun(ctx context.Context, result *models.WorkflowRunResult, environmentID string) {
+	if ctx.Err() == nil && !result.Passed {
+		nd.Notify(workflowRunNotification(result, environmentID))
+	}
+}
+
+func (nd *NotificationDispatcher) postWebhook(ctx context.Context, notifier models.Notifier, payload []byte, delivery *models.NotificationDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notifier.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "grpc-client")
+	for key, value := range notifier.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := nd.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxNotificationOutput))
+	delivery.StatusCode = resp.StatusCode
+	delivery.Output = string(body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// runNotifierCommand runs the notifier's command with the notification as JSON on stdin. The
+// event and title are also passed in the GRPC_CLIENT_EVENT and GRPC_CLIENT_TITLE variables.
+func runNotifierCommand(ctx context.Context, notifier models.Notifier, notification models.Notification, payload []byte, delivery *models.NotificationDelivery) error {
+	// Checked again here, since notifiers can also come from an imported or edited workspace
+	if err := checkNotifierCommand(notifier.Command); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, notifier.Command, notifier.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"GRPC_CLIENT_EVENT="+string(notification.Event),
+		"GRPC_CLIENT_TITLE="+notification.Title,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if len(output) > maxNotificationOutput {
+		output = output[:maxNotificationOutput]
+	}
+	delivery.Output = string(output)
+	if err != nil {
+		return fmt.Errorf("command failed: %v", err)
+	}
+	return nil
+}
+
+// notifierMatches reports whether an enabled notifier subscribes to the notification
+func notifierMatches(notifier models.Notifier, notification models.Notification) bool {
+	if !notifier.Enabled {
+		return false
+	}
+	if len(notifier.Events) > 0 && !containsEvent(notifier.Events, notification.Event) {
+		return false
+	}
+	if len(notifier.CollectionIDs) > 0 && !containsString(notifier.CollectionIDs, notification.CollectionID) {
+		return false
+	}
+	if len(notifier.EnvironmentIDs) > 0 && !containsString(notifier.EnvironmentIDs, notification.EnvironmentID) {
+		return false
+	}
+	return true
+}
+
+func containsEvent(events []models.NotificationEvent, event models.NotificationEvent) bool {
+	for _, candidate := range events {
+		if candidate == event {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validateNotifier checks the type, target and events of a notifier
+func validateNotifier(notifier models.Notifier) error {
+	switch notifier.Type {
+	case models.NotifierTypeWebhook:
+		parsed, err := url.Parse(notifier.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("webhook notifiers need an http or https url")
+		}
+	case models.NotifierTypeCommand:
+		if strings.TrimSpace(notifier.Command) == "" {
+			return fmt.Errorf("command notifiers need a command")
+		}
+		if err := checkNotifierCommand(notifier.Command); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("type must be %q or %q", models.NotifierTypeWebhook, models.NotifierTypeCommand)
+	}
+
+	for _, event := range notifier.Events {
+		switch event {
+		case models.NotificationEventRequestRun, models.NotificationEventCollectionRun, models.NotificationEventWorkflowRun,
+			models.NotificationEventMonitorDown, models.NotificationEventMonitorRecovered, models.NotificationEventTest:
+		default:
+			return fmt.Errorf("unknown event: %s", event)
+		}
+	}
+	return nil
+}
+
+// checkNotifierCommand allows only the absolute executable paths the operator listed in
+// GRPC_CLIENT_NOTIFIER_COMMANDS, so commands cannot be chosen through the API alone
+func checkNotifierCommand(command string) error {
+	allowed := os.Getenv(notifierCommandsEnv)
+	if strings.TrimSpace(allowed) == "" {
+		return fmt.Errorf("command notifiers are disabled; list the allowed executables in %s", notifierCommandsEnv)
+	}
+	if filepath.IsAbs(command) {
+		for _, executable := range filepath.SplitList(allowed) {
+			executable = strings.TrimSpace(executable)
+			if filepath.IsAbs(executable) && filepath.Clean(executable) == filepath.Clean(command) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("command %q is not allowed; list its absolute path in %s", command, notifierCommandsEnv)
+}
+
+// requestRunNotification reports a failed run of a saved request
+func requestRunNotification(run *models.RequestRunResult, collection *models.Collection) models.Notification {
+	return models.Notification{
+		Event:         models.NotificationEventRequestRun,
+		Title:         fmt.Sprintf("Request %s failed", run.RequestName),
+		Message:       runFailureReason(run),
+		TargetID:      run.RequestID,
+		TargetName:    run.RequestName,
+		CollectionID:  collection.ID,
+		EnvironmentID: run.EnvironmentID,
+		Total:         1,
+		Failed:        1,
+		Result:        run,
+		Time:          time.Now(),
+	}
+}
+
+// collectionRunNotification reports a collection run with failing requests
+func collectionRunNotification(result *models.CollectionRunResult) models.Notification {
+	notification := models.Notification{
+		Event:         models.NotificationEventCollectionRun,
+		Title:         fmt.Sprintf("Collection %s: %d of %d requests failed", result.CollectionName, result.Failed, result.Total),
+		TargetID:      result.CollectionID,
+		TargetName:    result.CollectionName,
+		CollectionID:  result.CollectionID,
+		EnvironmentID: result.EnvironmentID,
+		Total:         result.Total,
+		Failed:        result.Failed,
+		Result:        result,
+		Time:          time.Now(),
+	}
+	for _, item := range result.Results {
+		if !item.Passed {
+			notification.Message = fmt.Sprintf("%s: %s", item.RequestName, collectionItemFailure(item))
+			break
+		}
+	}
+	return notification
+}
+
+// workflowRunNotification reports a failed workflow run
+func workflowRunNotification(result *models.WorkflowRunResult, environmentID string) models.Notification {
+	notification := models.Notification{
+		Event:         models.NotificationEventWorkflowRun,
+		Title:         fmt.Sprintf("Workflow %s failed", result.WorkflowName),
+		TargetID:      result.WorkflowID,
+		TargetName:    result.WorkflowName,
+		EnvironmentID: environmentID,
+		Total:         len(result.Steps),
+		Result:        result,
+		Time:          time.Now(),
+	}
+	for _, step := range result.Steps {
+		if step.State == models.WorkflowStepFailed {
+			notification.Failed++
+			if notification.Message == "" {
+				notification.Message = fmt.Sprintf("step %s: %s", step.StepID, step.Reason)
+			}
+		}
+	}
+	return notification
+}
+
+// monitorNotification reports a monitor going down or recovering
+func monitorNotification(monitor models.Monitor, collectionID string, check models.MonitorCheck) models.Notification {
+	notification := models.Notification{
+		Event:         models.NotificationEventMonitorRecovered,
+		Title:         fmt.Sprintf("Monitor %s recovered", monitor.Name),
+		Message:       fmt.Sprintf("check passed in %dms", check.DurationMs),
+		TargetID:      monitor.ID,
+		TargetName:    monitor.Name,
+		CollectionID:  collectionID,
+		EnvironmentID: monitor.EnvironmentID,
+		Total:         check.Total,
+		Failed:        check.Failed,
+		Result:        check,
+		Time:          time.Now(),
+	}
+	if !check.Passed {
+		notification.Event = models.NotificationEventMonitorDown
+		notification.Title = fmt.Sprintf("Monitor %s is down", monitor.Name)
+		notification.Message = check.Error
+	}
+	return notification
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckNotifierCommand(t *testing.T) {
+	allowed := strings.Join([]string{"/usr/local/bin/notify", "relative/notify", " /opt/hooks/../bin/alert "}, string(filepath.ListSeparator))
+
+	tests := []struct {
+		name    string
+		allowed string
+		command string
+		wantErr bool
+	}{
+		{name: "disabled without allowlist", allowed: "", command: "/usr/local/bin/notify", wantErr: true},
+		{name: "listed executable", allowed: allowed, command: "/usr/local/bin/notify", wantErr: false},
+		{name: "listed executable with unclean path", allowed: allowed, command: "/opt/bin/alert", wantErr: false},
+		{name: "unlisted executable", allowed: allowed, command: "/bin/sh", wantErr: true},
+		{name: "relative command", allowed: allowed, command: "notify", wantErr: true},
+		{name: "relative allowlist entry", allowed: allowed, command: "relative/notify", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(notifierCommandsEnv, tt.allowed)
+			if err := checkNotifierCommand(tt.command); (err != nil) != tt.wantErr {
+				t.Errorf("checkNotifierCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+			}
+		})
+	}
+}
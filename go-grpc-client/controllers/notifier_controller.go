@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"fmt"
+	"grpc-client/constants"
+	"grpc-client/models"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// NotifierController manages the webhook and command notifiers stored in the workspace
+type NotifierController struct {
+	dispatcher *NotificationDispatcher
+	store      *WorkspaceStore
+}
+
+func NewNotifierController(dispatcher *NotificationDispatcher, store *WorkspaceStore) *NotifierController {
+	return &NotifierController{
+		dispatcher: dispatcher,
+		store:      store,
+	}
+}
+
+// ListNotifiers returns all notifiers of the workspace
+func (nc *NotifierController) ListNotifiers(c *gin.Context) {
+	workspace, err := nc.store.Load()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
+		return
+	}
+
+	notifiers := workspace.Notifiers
+	if notifiers == nil {
+		notifiers = []models.Notifier{}
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Notifiers loaded successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    notifiers,
+	})
+}
+
+// CreateNotifier validates and stores a new notifier
+func (nc *NotifierController) CreateNotifier(c *gin.Context) {
+	var notifier models.Notifier
+	if err := c.ShouldBindJSON(&notifier); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if notifier.Name == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Notifier name is required"})
+		return
+	}
+	if err := validateNotifier(notifier); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	notifier.ID = uuid.New().String()
+	notifier.CreatedAt = time.Now()
+	notifier.UpdatedAt = time.Now()
+
+	err := nc.store.Update(func(workspace *models.Workspace) error {
+		workspace.Notifiers = append(workspace.Notifiers, notifier)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error saving notifier: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: fmt.Sprintf("Failed to create notifier: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Notifier created successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    notifier,
+	})
+}
+
+// UpdateNotifier replaces the definition of an existing notifier
+func (nc *NotifierController) UpdateNotifier(c *gin.Context) {
+	notifierID := c.Param("id")
+
+	var notifier models.Notifier
+	if err := c.ShouldBindJSON(&notifier); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if err := validateNotifier(notifier); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	found := false
+	err := nc.store.Update(func(workspace *models.Workspace) error {
+		for i := range workspace.Notifiers {
+			if workspace.Notifiers[i].ID == notifierID {
+				notifier.ID = notifierID
+				notifier.CreatedAt = workspace.Notifiers[i].CreatedAt
+				notifier.UpdatedAt = time.Now()
+				if notifier.Name == "" {
+					notifier.Name = workspace.Notifiers[i].Name
+				}
+				workspace.Notifiers[i] = notifier
+				found = true
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error saving notifier: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update notifier"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Notifier not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Notifier updated successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    notifier,
+	})
+}
+
+// DeleteNotifier removes a notifier
+func (nc *NotifierController) DeleteNotifier(c *gin.Context) {
+	notifierID := c.Param("id")
+
+	found := false
+	err := nc.store.Update(func(workspace *models.Workspace) error {
+		for i := range workspace.Notifiers {
+			if workspace.Notifiers[i].ID == notifierID {
+				workspace.Notifiers = append(workspace.Notifiers[:i], workspace.Notifiers[i+1:]...)
+				found = true
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error saving workspace: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to delete notifier"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Notifier not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Notifier deleted successfully",
+		Status:  constants.ResponseStatusSuccess,
+	})
+}
+
+// TestNotifier sends a test notification to a notifier, whether or not it is enabled, and
+// returns the delivery outcome
+func (nc *NotifierController) TestNotifier(c *gin.Context) {
+	workspace, err := nc.store.Load()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
+		return
+	}
+
+	for _, notifier := range workspace.Notifiers {
+		if notifier.ID != c.Param("id") {
+			continue
+		}
+		delivery := nc.dispatcher.Deliver(c.Request.Context(), notifier, models.Notification{
+			Event:      models.NotificationEventTest,
+			Title:      fmt.Sprintf("Test notification from %s", notifier.Name),
+			Message:    "This is a test notification.",
+			TargetID:   notifier.ID,
+			TargetName: notifier.Name,
+			Time:       time.Now(),
+		})
+		c.JSON(http.StatusOK, delivery)
+		return
+	}
+	c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Notifier not found"})
+}
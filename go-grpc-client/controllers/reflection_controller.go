@@ -170,11 +170,16 @@ func (rc *ReflectionController) getServiceDetails(refClient *grpcreflect.Client,
 	methods := make([]map[string]interface{}, 0)
 	for _, method := range serviceDesc.GetMethods() {
 		methodInfo := map[string]interface{}{
-			"name":        method.GetName(),
-			"fullName":    method.GetFullyQualifiedName(),
-			"inputType":   method.GetInputType().GetFullyQualifiedName(),
-			"outputType":  method.GetOutputType().GetFullyQualifiedName(),
-			"isStreaming": method.IsClientStreaming() || method.IsServerStreaming(),
+			"name":          method.GetName(),
+			"fullName":      method.GetFullyQualifiedName(),
+			"inputType":     method.GetInputType().GetFullyQualifiedName(),
+			"outputType":    method.GetOutputType().GetFullyQualifiedName(),
+			"isStreaming":   method.IsClientStreaming() || method.IsServerStreaming(),
+			"streamingMode": streamingMode(method),
+			"deprecated":    method.GetMethodOptions().GetDeprecated(),
+		}
+		if httpRules := getHTTPRules(method); len(httpRules) > 0 {
+			methodInfo["httpRules"] = httpRules
 		}
 		methods = append(methods, methodInfo)
 	}
@@ -224,18 +229,62 @@ func (rc *ReflectionController) getServiceFunctionDetails(conn *grpc.ClientConn,
 
 	// Get input and output message details
 	inputDetails := rc.getMessageDetails(methodDesc.GetInputType())
-	//outputDetails := rc.getMessageDetails(methodDesc.GetOutputType())
+	outputDetails := rc.getMessageDetails(methodDesc.GetOutputType())
+
+	details := map[string]interface{}{
+		"serviceName":     serviceName,
+		"methodName":      methodDesc.GetName(),
+		"fullName":        methodDesc.GetFullyQualifiedName(),
+		"inputType":       methodDesc.GetInputType().GetFullyQualifiedName(),
+		"outputType":      methodDesc.GetOutputType().GetFullyQualifiedName(),
+		"inputDetails":    inputDetails,
+		"outputDetails":   outputDetails,
+		"isStreaming":     methodDesc.IsClientStreaming() || methodDesc.IsServerStreaming(),
+		"clientStreaming": methodDesc.IsClientStreaming(),
+		"serverStreaming": methodDesc.IsServerStreaming(),
+		"bidiStreaming":   methodDesc.IsClientStreaming() && methodDesc.IsServerStreaming(),
+		"streamingMode":   streamingMode(methodDesc),
+		"options":         methodOptions(methodDesc),
+		"comments":        descriptorComments(methodDesc),
+		"serviceComments": descriptorComments(serviceDesc),
+	}
+	if httpRules := getHTTPRules(methodDesc); len(httpRules) > 0 {
+		details["httpRules"] = httpRules
+	}
+
+	return details, nil
+}
+
+// streamingMode names how a method streams: unary, clientStreaming, serverStreaming or bidiStreaming
+func streamingMode(method *desc.MethodDescriptor) string {
+	switch {
+	case method.IsClientStreaming() && method.IsServerStreaming():
+		return "bidiStreaming"
+	case method.IsClientStreaming():
+		return "clientStreaming"
+	case method.IsServerStreaming():
+		return "serverStreaming"
+	}
+	return "unary"
+}
 
+// methodOptions returns the idempotency level and deprecation of a method
+func methodOptions(method *desc.MethodDescriptor) map[string]interface{} {
+	options := method.GetMethodOptions()
 	return map[string]interface{}{
-		"serviceName":   serviceName,
-		"methodName":    methodDesc.GetName(),
-		"fullName":      methodDesc.GetFullyQualifiedName(),
-		"inputType":     methodDesc.GetInputType().GetFullyQualifiedName(),
-		"outputType":    methodDesc.GetOutputType().GetFullyQualifiedName(),
-		"inputDetails":  inputDetails,
-		//"outputDetails": outputDetails,
-		"isStreaming":   methodDesc.IsClientStreaming() || methodDesc.IsServerStreaming(),
-	}, nil
+		"idempotencyLevel": options.GetIdempotencyLevel().String(),
+		"deprecated":       options.GetDeprecated(),
+	}
+}
+
+// descriptorComments returns the leading and trailing comments of a service or method. They
+// are empty when the server's reflection data has no source info.
+func descriptorComments(descriptor desc.Descriptor) map[string]interface{} {
+	sourceInfo := descriptor.GetSourceInfo()
+	return map[string]interface{}{
+		"leading":  strings.TrimSpace(sourceInfo.GetLeadingComments()),
+		"trailing": strings.TrimSpace(sourceInfo.GetTrailingComments()),
+	}
 }
 
 func (rc *ReflectionController) getMessageDetails(msgDesc *desc.MessageDescriptor) map[string]interface{} {
@@ -0,0 +1,549 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"grpc-client/models"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RequestRunner executes requests saved in the workspace
+type RequestRunner struct {
+	executor *CallExecutor
+	store    *WorkspaceStore
+}
+
+func NewRequestRunner(executor *CallExecutor, store *WorkspaceStore) *RequestRunner {
+	return &RequestRunner{
+		executor: executor,
+		store:    store,
+	}
+}
+
+// FindRequest finds a saved request and the collection it belongs to
+func (rr *RequestRunner) FindRequest(requestID string) (*models.Request, *models.Collection, error) {
+	collections, err := rr.store.AllCollections()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range collections {
+		for j := range collections[i].Requests {
+			if collections[i].Requests[j].ID == requestID {
+				return &collections[i].Requests[j], &collections[i], nil
+			}
+		}
+	}
+
+	return nil, nil, fmt.Errorf("request %q not found", requestID)
+}
+
+// RunRequest resolves a saved request against its environment and executes it, running the
+// collection and request scripts around the call. Errors selecting the environment are
+// returned; call and script failures are reported in the result.
+func (rr *RequestRunner) RunRequest(ctx context.Context, request *models.Request, collection *models.Collection, options models.RunRequestOptions) (*models.RequestRunResult, error) {
+	resolver, environment, err := rr.store.ResolverFor(collection.ID, options.EnvironmentID, request.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run-time variables override all other scopes; scripts write to them with pm.variables.set
+	resolver.pushScope(scopeRun, options.Variables)
+
+	run := &models.RequestRunResult{
+		RequestID:   request.ID,
+		RequestName: request.Name,
+	}
+	if environment != nil {
+		run.EnvironmentID = environment.ID
+	}
+
+	session := newScriptSession(resolver)
+	var protocol models.CallProtocol
+	var execute func() *models.CallResult
+
+	switch request.Type {
+	case models.RequestTypeREST:
+		if request.RESTConfig == nil {
+			return nil, fmt.Errorf("request %q has no REST configuration", request.ID)
+		}
+		restRequest := buildRestCallRequest(request, environment)
+		session.request = restRequestView(restRequest)
+		protocol = models.ProtocolREST
+		execute = func() *models.CallResult {
+			applyRestRequestView(session.request, &restRequest)
+			restRequest = resolver.ResolveRestRequest(restRequest)
+			run.Request = redactRestRequest(restRequest)
+			log.Printf("Running saved request %s (%s %s)", request.Name, restRequest.RESTConfig.Method, restRequest.RESTConfig.URL)
+			return rr.executor.ExecuteRest(ctx, restRequest)
+		}
+	default:
+		if request.GRPCConfig == nil {
+			return nil, fmt.Errorf("request %q has no gRPC configuration", request.ID)
+		}
+		grpcRequest := buildGrpcRequest(request, environment)
+		session.request = grpcRequestView(grpcRequest)
+		protocol = grpcRequest.Protocol
+		if protocol == "" {
+			protocol = models.ProtocolGRPC
+		}
+		execute = func() *models.CallResult {
+			applyGrpcRequestView(session.request, &grpcRequest)
+			grpcRequest = resolver.ResolveGrpcRequest(grpcRequest)
+			run.Request = redactGrpcRequest(grpcRequest)
+			log.Printf("Running saved request %s (%s on %s)", request.Name, grpcRequest.Method, grpcRequest.Host)
+			return rr.executor.ExecuteGrpc(ctx, grpcRequest, http.Header{})
+		}
+	}
+
+	if err := session.runScripts("pre-request", collection.PreRequestScript, request.PreRequestScript); err != nil {
+		run.ScriptError = fmt.Sprintf("Pre-request script failed: %v", err)
+		run.CallResult = &models.CallResult{
+			Protocol:  protocol,
+			Error:     run.ScriptError,
+			StartedAt: time.Now(),
+		}
+	} else {
+		run.CallResult = execute()
+		run.Extracted = applyExtractions(request.Extractions, run.CallResult, session)
+		session.response = run.CallResult
+		if err := session.runScripts("post-response", collection.PostResponseScript, request.PostResponseScript); err != nil {
+			run.ScriptError = fmt.Sprintf("Post-response script failed: %v", err)
+		}
+	}
+
+	if err := rr.persistScriptChanges(session, collection.ID, environment); err != nil {
+		log.Printf("Error saving variables set by scripts: %v", err)
+	}
+
+	run.ScriptTests = session.tests
+	run.ScriptLogs = session.logs
+	run.Assertions = evaluateAssertions(request.Assertions, run.CallResult)
+	if request.Snapshot != nil {
+		run.Snapshot = compareSnapshot(request.Snapshot, run.CallResult)
+	}
+	run.Passed = assertionsPassed(run.CallResult, request.Assertions, run.Assertions) &&
+		session.testsPassed() && run.ScriptError == "" && (run.Snapshot == nil || run.Snapshot.Matched)
+
+	run.UndefinedVariables = resolver.Undefined()
+	if len(run.UndefinedVariables) > 0 {
+		log.Printf("Warning: undefined variables in request %s: %s", request.Name, strings.Join(run.UndefinedVariables, ", "))
+	}
+	return run, nil
+}
+
+// runFailureReason describes why a run did not pass
+func runFailureReason(run *models.RequestRunResult) string {
+	if run.Error != "" {
+		return run.Error
+	}
+	if run.ScriptError != "" {
+		return run.ScriptError
+	}
+	for _, assertion := range run.Assertions {
+		if !assertion.Passed {
+			return fmt.Sprintf("assertion %s failed: %s", assertion.Assertion.Type, assertion.Message)
+		}
+	}
+	for _, test := range run.ScriptTests {
+		if !test.Passed {
+			return fmt.Sprintf("test %q failed: %s", test.Name, test.Error)
+		}
+	}
+	if run.Snapshot != nil && !run.Snapshot.Matched {
+		return snapshotFailure(run.Snapshot)
+	}
+	if run.CallResult != nil && !run.Succeeded() {
+		return fmt.Sprintf("call returned %s", run.Status)
+	}
+	return ""
+}
+
+// persistScriptChanges saves environment and collection variables set by scripts.
+// Changes to read-only sample collections only last for the run.
+func (rr *RequestRunner) persistScriptChanges(session *scriptSession, collectionID string, environment *models.Environment) error {
+	if len(session.environmentChanges) == 0 && len(session.collectionChanges) == 0 {
+		return nil
+	}
+
+	return rr.store.Update(func(workspace *models.Workspace) error {
+		for i := range workspace.Collections {
+			collection := &workspace.Collections[i]
+			if collection.ID == collectionID {
+				collection.Variables = applyVariableChanges(collection.Variables, session.collectionChanges)
+			}
+			if environment == nil {
+				continue
+			}
+			for j := range collection.Environments {
+				if collection.Environments[j].ID == environment.ID {
+					collection.Environments[j].Variables = applyVariableChanges(collection.Environments[j].Variables, session.environmentChanges)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func applyVariableChanges(variables map[string]string, changes map[string]*string) map[string]string {
+	if len(changes) == 0 {
+		return variables
+	}
+	if variables == nil {
+		variables = make(map[string]string)
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(variables, name)
+		} else {
+			variables[name] = *value
+		}
+	}
+	return variables
+}
+
+// ResolveGrpcCall builds a saved gRPC request with its variables substituted, without
+// running scripts. It returns the call and the names of undefined variables.
+func (rr *RequestRunner) ResolveGrpcCall(request *models.Request, collection *models.Collection, options models.RunRequestOptions) (models.GrpcRequest, []string, error) {
+	if request.Type == models.RequestTypeREST || request.GRPCConfig == nil {
+		return models.GrpcRequest{}, nil, fmt.Errorf("request %q is not a gRPC request", request.ID)
+	}
+
+	resolver, environment, err := rr.store.ResolverFor(collection.ID, options.EnvironmentID, request.Variables)
+	if err != nil {
+		return models.GrpcRequest{}, nil, err
+	}
+	resolver.pushScope(scopeRun, options.Variables)
+
+	resolved := resolver.ResolveGrpcRequest(buildGrpcRequest(request, environment))
+	return resolved, resolver.Undefined(), nil
+}
+
+// ResolveBenchmarkRequest returns the call of a benchmark: the inline request resolved like
+// a /grpc/call request, or the saved request resolved against its environment
+func (rr *RequestRunner) ResolveBenchmarkRequest(benchmarkRequest models.BenchmarkRequest) (models.GrpcRequest, []string, error) {
+	if benchmarkRequest.Request != nil {
+		resolver, _, err := rr.store.ResolverFor(benchmarkRequest.Request.CollectionID, benchmarkRequest.Request.EnvironmentID, benchmarkRequest.Request.Variables)
+		if err != nil {
+			return models.GrpcRequest{}, nil, err
+		}
+		return resolver.ResolveGrpcRequest(*benchmarkRequest.Request), resolver.Undefined(), nil
+	}
+
+	if benchmarkRequest.RequestID == "" {
+		return models.GrpcRequest{}, nil, fmt.Errorf("either request or requestId is required")
+	}
+	request, collection, err := rr.FindRequest(benchmarkRequest.RequestID)
+	if err != nil {
+		return models.GrpcRequest{}, nil, err
+	}
+	return rr.ResolveGrpcCall(request, collection, models.RunRequestOptions{
+		EnvironmentID: benchmarkRequest.EnvironmentID,
+		Variables:     benchmarkRequest.Variables,
+	})
+}
+
+// buildGrpcRequest converts a saved gRPC request into a call. Enabled environment metadata
+// is applied first so the request's own enabled metadata can override it.
+func buildGrpcRequest(request *models.Request, environment *models.Environment) models.GrpcRequest {
+	config := request.GRPCConfig
+
+	metaData := make(map[string]string)
+	if environment != nil {
+		for _, header := range environment.Metadata {
+			if header.Enabled && header.Key != "" {
+				metaData[strings.ToLower(header.Key)] = header.Value
+			}
+		}
+	}
+	for _, header := range config.Metadata {
+		if header.Enabled && header.Key != "" {
+			metaData[strings.ToLower(header.Key)] = header.Value
+		}
+	}
+
+	method := config.Method
+	if config.Service != "" {
+		method = config.Service + "." + config.Method
+	}
+
+	return models.GrpcRequest{
+		Host:        request.Host,
+		Method:      method,
+		Message:     config.Message,
+		MetaData:    metaData,
+		Protocol:    config.Protocol,
+		Connect:     config.Connect,
+		Transcoding: config.Transcoding,
+		Auth:        requestAuth(request, environment),
+		Retry:       request.Retry,
+	}
+}
+
+// buildRestCallRequest converts a saved REST request into a call. Enabled environment
+// metadata is sent as headers unless the request sets the same header.
+func buildRestCallRequest(request *models.Request, environment *models.Environment) models.RestCallRequest {
+	config := *request.RESTConfig
+	if environment != nil && len(environment.Metadata) > 0 {
+		requestHeaders := make(map[string]bool)
+		for _, header := range config.Headers {
+			if header.Enabled {
+				requestHeaders[strings.ToLower(header.Key)] = true
+			}
+		}
+
+		var headers []models.RequestHeader
+		for _, header := range environment.Metadata {
+			if header.Enabled && !requestHeaders[strings.ToLower(header.Key)] {
+				headers = append(headers, header)
+			}
+		}
+		config.Headers = append(headers, config.Headers...)
+	}
+
+	return models.RestCallRequest{
+		Host:       request.Host,
+		RESTConfig: config,
+		Auth:       requestAuth(request, environment),
+		Retry:      request.Retry,
+	}
+}
+
+// requestAuth returns the request's own auth, falling back to the environment's when the request has none
+func requestAuth(request *models.Request, environment *models.Environment) *models.RequestAuth {
+	if request.Auth.Type != "" && request.Auth.Type != AuthTypeNone {
+		auth := request.Auth
+		return &auth
+	}
+	if environment != nil {
+		return environment.Auth
+	}
+	return nil
+}
+
+// bindDataRow replaces the columns of the previous data row in the run variables with those of
+// the current row. A column missing from the current row gets back its value from base, the
+// variables given for the run, or is removed, so rows never inherit values from earlier rows.
+func bindDataRow(variables, base, previous, current map[string]string) {
+	for column := range previous {
+		if value, ok := base[column]; ok {
+			variables[column] = value
+		} else {
+			delete(variables, column)
+		}
+	}
+	for column, value := range current {
+		variables[column] = value
+	}
+}
+
+// FindCollection finds a workspace or sample collection by ID
+func (rr *RequestRunner) FindCollection(collectionID string) (*models.Collection, error) {
+	collections, err := rr.store.AllCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range collections {
+		if collections[i].ID == collectionID {
+			return &collections[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("collection %q not found", collectionID)
+}
+
+// RunCollection runs the selected requests of a collection sequentially by order. progress,
+// if set, is called after each request. Cancelling ctx stops the run after the current request.
+func (rr *RequestRunner) RunCollection(ctx context.Context, collection *models.Collection, options models.CollectionRunOptions, progress func(item models.CollectionRunItem)) (*models.CollectionRunResult, error) {
+	_, environment, err := rr.store.ResolverFor(collection.ID, options.EnvironmentID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := selectRunRequests(collection.Requests, options.RequestIDs, options.Tags)
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("collection %q has no requests to run", collection.Name)
+	}
+
+	iterations := collectionRunIterations(options)
+	delay := time.Duration(options.DelayMs) * time.Millisecond
+
+	// Run variables are shared by all requests of the run, so scripts can pass values along
+	runVariables := make(map[string]string, len(options.Variables))
+	for key, value := range options.Variables {
+		runVariables[key] = value
+	}
+	options.Variables = runVariables
+	baseVariables := make(map[string]string, len(runVariables))
+	for key, value := range runVariables {
+		baseVariables[key] = value
+	}
+	var previousRow map[string]string
+
+	runResult := &models.CollectionRunResult{
+		CollectionID:     collection.ID,
+		CollectionName:   collection.Name,
+		Iterations:       iterations,
+		Results:          []models.CollectionRunItem{},
+		IterationResults: []models.CollectionIterationResult{},
+		StartedAt:        time.Now(),
+	}
+	if environment != nil {
+		runResult.EnvironmentID = environment.ID
+	}
+
+	log.Printf("Running %d requests of collection %s (%d iterations)", len(requests), collection.Name, iterations)
+
+run:
+	for iteration := 1; iteration <= iterations; iteration++ {
+		iterationStarted := time.Now()
+		runResult.IterationResults = append(runResult.IterationResults, models.CollectionIterationResult{
+			Iteration: iteration,
+			Passed:    true,
+		})
+		iterationResult := &runResult.IterationResults[len(runResult.IterationResults)-1]
+
+		// Bind the columns of this iteration's data row in place of the previous row's
+		if len(options.Data) > 0 {
+			iterationResult.Data = make(map[string]string, len(options.Data[iteration-1]))
+			for column, value := range options.Data[iteration-1] {
+				iterationResult.Data[column] = formatFieldValue(value)
+			}
+			bindDataRow(runVariables, baseVariables, previousRow, iterationResult.Data)
+			previousRow = iterationResult.Data
+		}
+
+		for i := range requests {
+			if ctx.Err() != nil {
+				runResult.Stopped = true
+				break run
+			}
+
+			// Pause between requests, but not before the first one
+			if delay > 0 && len(runResult.Results) > 0 {
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					runResult.Stopped = true
+					break run
+				}
+			}
+
+			item := rr.runCollectionItem(ctx, &requests[i], collection, options, iteration)
+			runResult.Results = append(runResult.Results, item)
+			runResult.Total++
+			iterationResult.Total++
+			if item.Passed {
+				runResult.Passed++
+			} else {
+				runResult.Failed++
+				iterationResult.Failed++
+				iterationResult.Passed = false
+			}
+			iterationResult.DurationMs = time.Since(iterationStarted).Milliseconds()
+			if progress != nil {
+				progress(item)
+			}
+
+			if !item.Passed && options.StopOnFailure {
+				runResult.Stopped = true
+				break run
+			}
+		}
+	}
+
+	runResult.DurationMs = time.Since(runResult.StartedAt).Milliseconds()
+	log.Printf("Collection %s finished: %d passed, %d failed", collection.Name, runResult.Passed, runResult.Failed)
+	return runResult, nil
+}
+
+// collectionRunIterations returns how often a collection run repeats its requests
+func collectionRunIterations(options models.CollectionRunOptions) int {
+	if len(options.Data) > 0 {
+		return len(options.Data)
+	}
+	if options.Iterations < 1 {
+		return 1
+	}
+	return options.Iterations
+}
+
+func (rr *RequestRunner) runCollectionItem(ctx context.Context, request *models.Request, collection *models.Collection, options models.CollectionRunOptions, iteration int) models.CollectionRunItem {
+	item := models.CollectionRunItem{
+		Iteration:   iteration,
+		RequestID:   request.ID,
+		RequestName: request.Name,
+		StartedAt:   time.Now(),
+	}
+
+	result, err := rr.RunRequest(ctx, request, collection, models.RunRequestOptions{
+		EnvironmentID: options.EnvironmentID,
+		Variables:     options.Variables,
+	})
+	if err != nil {
+		item.Error = err.Error()
+		item.DurationMs = time.Since(item.StartedAt).Milliseconds()
+		return item
+	}
+
+	item.Protocol = result.Protocol
+	item.Status = result.Status
+	item.StatusCode = result.StatusCode
+	item.Passed = result.Passed
+	item.Error = result.Error
+	item.Attempts = len(result.Attempts)
+	item.UndefinedVariables = result.UndefinedVariables
+	item.Assertions = result.Assertions
+	item.ScriptTests = result.ScriptTests
+	item.ScriptError = result.ScriptError
+	item.Snapshot = result.Snapshot
+	if options.IncludeDetails {
+		item.Request = result.Request
+		item.Response = result.CallResult
+	}
+	item.StartedAt = result.StartedAt
+	item.DurationMs = result.DurationMs
+	return item
+}
+
+// selectRunRequests returns the requests to run sorted by order, limited to the given IDs and tags when set
+func selectRunRequests(requests []models.Request, requestIDs []string, tags []string) []models.Request {
+	wantedIDs := make(map[string]bool, len(requestIDs))
+	for _, id := range requestIDs {
+		wantedIDs[id] = true
+	}
+	wantedTags := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		wantedTags[strings.ToLower(tag)] = true
+	}
+
+	var selected []models.Request
+	for _, request := range requests {
+		if len(wantedIDs) > 0 && !wantedIDs[request.ID] {
+			continue
+		}
+		if len(wantedTags) > 0 && !hasAnyTag(request.Tags, wantedTags) {
+			continue
+		}
+		selected = append(selected, request)
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].Order < selected[j].Order
+	})
+	return selected
+}
+
+func hasAnyTag(tags []string, wanted map[string]bool) bool {
+	for _, tag := range tags {
+		if wanted[strings.ToLower(tag)] {
+			return true
+		}
+	}
+	return false
+}
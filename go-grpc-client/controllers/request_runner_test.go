@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"grpc-client/models"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestBindDataRow(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     map[string]string
+		previous map[string]string
+		current  map[string]string
+		want     map[string]string
+	}{
+		{
+			name:    "first row",
+			base:    map[string]string{"host": "a"},
+			current: map[string]string{"id": "1"},
+			want:    map[string]string{"host": "a", "id": "1"},
+		},
+		{
+			name:     "column missing from the next row is removed",
+			previous: map[string]string{"id": "1", "name": "x"},
+			current:  map[string]string{"name": "y"},
+			want:     map[string]string{"name": "y"},
+		},
+		{
+			name:     "column missing from the next row gets back its run value",
+			base:     map[string]string{"id": "default"},
+			previous: map[string]string{"id": "1"},
+			current:  map[string]string{},
+			want:     map[string]string{"id": "default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variables := make(map[string]string)
+			for key, value := range tt.base {
+				variables[key] = value
+			}
+			for key, value := range tt.previous {
+				variables[key] = value
+			}
+			bindDataRow(variables, tt.base, tt.previous, tt.current)
+			if !reflect.DeepEqual(variables, tt.want) {
+				t.Errorf("bindDataRow() = %v, want %v", variables, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCollectionDataRowsWithDifferentKeys(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	runner, _ := newTestRunner(t)
+	collection := &models.Collection{
+		ID:   "c1",
+		Name: "data",
+		Requests: []models.Request{{
+			ID:         "r1",
+			Name:       "get item",
+			Type:       models.RequestTypeREST,
+			Host:       server.URL,
+			RESTConfig: &models.RESTConfig{Method: "GET", URL: "/items/{{id}}/{{name}}"},
+		}},
+	}
+	saveTestCollection(t, runner.store, *collection)
+
+	options := models.CollectionRunOptions{
+		Variables: map[string]string{"name": "default"},
+		Data: []map[string]interface{}{
+			{"id": float64(1), "name": "first"},
+			{"name": "second"},
+			{"id": float64(3)},
+		},
+	}
+	result, err := runner.RunCollection(context.Background(), collection, options, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantPaths := []string{"/items/1/first", "/items/{{id}}/second", "/items/3/default"}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("requested paths = %q, want %q", paths, wantPaths)
+	}
+	if got := result.Results[1].UndefinedVariables; !reflect.DeepEqual(got, []string{"id"}) {
+		t.Errorf("undefined variables of the second row = %q, want [id]", got)
+	}
+}
+
+// newTestRunner creates a request runner with a workspace in a temporary home folder
+func newTestRunner(t *testing.T) (*RequestRunner, *WorkspaceStore) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	store := NewWorkspaceStore()
+	return NewRequestRunner(NewCallExecutor(), store), store
+}
+
+// saveTestCollection adds a collection to the workspace
+func saveTestCollection(t *testing.T, store *WorkspaceStore, collection models.Collection) {
+	t.Helper()
+	err := store.Update(func(workspace *models.Workspace) error {
+		workspace.Collections = append(workspace.Collections, collection)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
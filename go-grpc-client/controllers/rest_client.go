@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"grpc-client/models"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var restHTTPClient = &http.Client{}
+
+// ExecuteRest executes a REST request described by a RESTConfig, retrying it as the
+// request's retry policy allows
+func (ce *CallExecutor) ExecuteRest(ctx context.Context, restRequest models.RestCallRequest) *models.CallResult {
+	return executeWithRetry(ctx, restRequest.Retry, func() *models.CallResult {
+		return ce.executeRestAttempt(ctx, restRequest)
+	})
+}
+
+// executeRestAttempt makes a single attempt of a REST call
+func (ce *CallExecutor) executeRestAttempt(ctx context.Context, restRequest models.RestCallRequest) *models.CallResult {
+	result := &models.CallResult{
+		Protocol:  models.ProtocolREST,
+		StartedAt: time.Now(),
+	}
+	defer func() {
+		result.DurationMs = time.Since(result.StartedAt).Milliseconds()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, defaultCallTimeout)
+	defer cancel()
+
+	req, err := buildRestHTTPRequest(ctx, restRequest)
+	if err != nil {
+		result.Error = fmt.Sprintf("Invalid request: %v", err)
+		return result
+	}
+
+	log.Printf("Making REST call: %s %s", req.Method, req.URL.String())
+
+	resp, err := restHTTPClient.Do(req)
+	if err != nil {
+		result.Error = fmt.Sprintf("REST call failed: %v", err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to read response: %v", err)
+		return result
+	}
+
+	result.Status = resp.Status
+	result.StatusCode = resp.StatusCode
+	result.Headers = lowerCaseHeaders(resp.Header)
+	if len(resp.Trailer) > 0 {
+		result.Trailers = lowerCaseHeaders(resp.Trailer)
+	}
+	result.Body = decodeRestBody(resp.Header.Get("Content-Type"), body)
+
+	return result
+}
+
+func buildRestHTTPRequest(ctx context.Context, restRequest models.RestCallRequest) (*http.Request, error) {
+	config := restRequest.RESTConfig
+
+	method := strings.ToUpper(config.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	requestURL, err := resolveRestURL(restRequest.Host, config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add enabled query parameters and API keys sent as query parameters
+	query := requestURL.Query()
+	for _, param := range config.Params {
+		if param.Enabled && param.Key != "" {
+			query.Add(param.Key, param.Value)
+		}
+	}
+	if restRequest.Auth != nil {
+		for key, value := range authQueryParams(*restRequest.Auth) {
+			query.Set(key, value)
+		}
+	}
+	requestURL.RawQuery = query.Encode()
+
+	var body io.Reader
+	contentType := ""
+	switch payload := config.Body.(type) {
+	case nil:
+	case string:
+		if payload != "" {
+			body = strings.NewReader(payload)
+		}
+	default:
+		jsonBytes, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal body: %v", err)
+		}
+		body = bytes.NewReader(jsonBytes)
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, header := range config.Headers {
+		if header.Enabled && header.Key != "" {
+			req.Header.Add(header.Key, header.Value)
+		}
+	}
+	if restRequest.Auth != nil {
+		for key, value := range authHeaders(*restRequest.Auth) {
+			if req.Header.Get(key) == "" {
+				req.Header.Set(key, value)
+			}
+		}
+	}
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req, nil
+}
+
+// resolveRestURL resolves a request URL, which may be relative to the request host
+func resolveRestURL(host, rawURL string) (*url.URL, error) {
+	if strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://") {
+		return url.Parse(rawURL)
+	}
+
+	if host == "" {
+		if rawURL == "" {
+			return nil, fmt.Errorf("url is required")
+		}
+		// A bare "host:port/path" URL
+		return httpBaseURL(rawURL)
+	}
+
+	baseURL, err := httpBaseURL(host)
+	if err != nil {
+		return nil, err
+	}
+	if rawURL == "" {
+		return baseURL, nil
+	}
+	if !strings.HasPrefix(rawURL, "/") {
+		rawURL = "/" + rawURL
+	}
+	return url.Parse(strings.TrimSuffix(baseURL.String(), "/") + rawURL)
+}
+
+// decodeRestBody returns JSON bodies as parsed values and anything else as text
+func decodeRestBody(contentType string, body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var parsed interface{}
+	if strings.Contains(contentType, "json") || json.Valid(body) {
+		if err := json.Unmarshal(body, &parsed); err == nil {
+			return parsed
+		}
+	}
+	return string(body)
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"context"
+	"grpc-client/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveRestURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{name: "absolute URL ignores host", host: "other:80", url: "https://api.example.com/v1", want: "https://api.example.com/v1"},
+		{name: "relative to host", host: "localhost:8080", url: "/items", want: "http://localhost:8080/items"},
+		{name: "relative without slash", host: "http://localhost:8080/", url: "items", want: "http://localhost:8080/items"},
+		{name: "host only", host: "localhost:8080", want: "http://localhost:8080"},
+		{name: "bare host and path", url: "localhost:8080/items", want: "http://localhost:8080/items"},
+		{name: "nothing to call", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveRestURL(tt.host, tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveRestURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("resolveRestURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRestHTTPRequest(t *testing.T) {
+	request := models.RestCallRequest{
+		Host: "localhost:8080",
+		RESTConfig: models.RESTConfig{
+			Method: "post",
+			URL:    "/items?sort=name",
+			Headers: []models.RequestHeader{
+				{Key: "X-Team", Value: "core", Enabled: true},
+				{Key: "X-Disabled", Value: "1", Enabled: false},
+			},
+			Params: []models.RequestHeader{
+				{Key: "page", Value: "2", Enabled: true},
+				{Key: "debug", Value: "1", Enabled: false},
+			},
+			Body: map[string]interface{}{"name": "widget"},
+		},
+		Auth: &models.RequestAuth{Type: AuthTypeAPIKey, Config: map[string]string{"in": "query", "key": "api_key", "value": "k1"}},
+	}
+
+	req, err := buildRestHTTPRequest(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != http.MethodPost {
+		t.Errorf("method = %s, want POST", req.Method)
+	}
+	if got := req.URL.String(); got != "http://localhost:8080/items?api_key=k1&page=2&sort=name" {
+		t.Errorf("url = %s", got)
+	}
+	if req.Header.Get("X-Team") != "core" || req.Header.Get("X-Disabled") != "" {
+		t.Errorf("headers = %v", req.Header)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("content type = %q, want application/json", got)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"name":"widget"}` {
+		t.Errorf("body = %s", body)
+	}
+
+	// Text bodies are sent as they are and headers set by the request win over auth headers
+	request.RESTConfig = models.RESTConfig{
+		URL:     "/items",
+		Body:    "plain text",
+		Headers: []models.RequestHeader{{Key: "Authorization", Value: "Custom x", Enabled: true}},
+	}
+	request.Auth = &models.RequestAuth{Type: AuthTypeBearer, Config: map[string]string{"token": "t1"}}
+	req, err = buildRestHTTPRequest(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(req.Body)
+	if req.Method != http.MethodGet || string(body) != "plain text" || req.Header.Get("Content-Type") != "" {
+		t.Errorf("text request = %s %q, content type %q", req.Method, body, req.Header.Get("Content-Type"))
+	}
+	if got := req.Header.Get("Authorization"); got != "Custom x" {
+		t.Errorf("authorization = %q, want the request header", got)
+	}
+}
+
+func TestDecodeRestBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        interface{}
+	}{
+		{name: "empty", body: "", want: nil},
+		{name: "JSON", contentType: "application/json; charset=utf-8", body: `{"id": 1}`, want: map[string]interface{}{"id": float64(1)}},
+		{name: "JSON without content type", contentType: "text/plain", body: `[1, 2]`, want: []interface{}{float64(1), float64(2)}},
+		{name: "invalid JSON", contentType: "application/json", body: `{"id"`, want: `{"id"`},
+		{name: "text", contentType: "text/html", body: "<p>hi</p>", want: "<p>hi</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeRestBody(tt.contentType, []byte(tt.body)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeRestBody() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "42")
+		switch r.URL.Path {
+		case "/items/1":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id": "1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+		}
+	}))
+	defer server.Close()
+
+	executor := NewCallExecutor()
+
+	result := executor.ExecuteRest(context.Background(), models.RestCallRequest{Host: server.URL, RESTConfig: models.RESTConfig{URL: "/items/1"}})
+	if !result.Succeeded() || result.Protocol != models.ProtocolREST {
+		t.Fatalf("result = %+v, want a successful REST call", result)
+	}
+	if result.Status != "200 OK" || !reflect.DeepEqual(result.Body, map[string]interface{}{"id": "1"}) {
+		t.Errorf("status = %q, body = %v", result.Status, result.Body)
+	}
+	if got := result.Headers["x-request-id"]; !reflect.DeepEqual(got, []string{"42"}) {
+		t.Errorf("headers = %v, want lower-cased x-request-id", result.Headers)
+	}
+
+	result = executor.ExecuteRest(context.Background(), models.RestCallRequest{Host: server.URL, RESTConfig: models.RESTConfig{URL: "/missing"}})
+	if result.Succeeded() || result.StatusCode != http.StatusNotFound || result.Body != "not found" || result.Error != "" {
+		t.Errorf("result = %+v, want a 404 response without a call error", result)
+	}
+
+	result = executor.ExecuteRest(context.Background(), models.RestCallRequest{})
+	if !strings.HasPrefix(result.Error, "Invalid request") {
+		t.Errorf("error = %q, want an invalid request", result.Error)
+	}
+}
+
+func TestExecuteRestRetriesUnavailable(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	result := NewCallExecutor().ExecuteRest(context.Background(), models.RestCallRequest{
+		Host:       server.URL,
+		RESTConfig: models.RESTConfig{URL: "/"},
+		Retry:      &models.RetryPolicy{MaxAttempts: 3, InitialBackoffMs: 1},
+	})
+	if !result.Succeeded() || calls != 3 || len(result.Attempts) != 3 {
+		t.Errorf("calls = %d, attempts = %d, result = %+v, want success on the third attempt", calls, len(result.Attempts), result)
+	}
+}
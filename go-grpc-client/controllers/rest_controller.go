@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"fmt"
+	"grpc-client/models"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RestController struct {
+	executor *CallExecutor
+	store    *WorkspaceStore
+}
+
+func NewRestController(executor *CallExecutor, store *WorkspaceStore) *RestController {
+	return &RestController{
+		executor: executor,
+		store:    store,
+	}
+}
+
+func (rsc *RestController) DefaultEndpoint(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"message": "Default endpoint for rest"})
+}
+
+// MakeRestCall executes a REST request and returns the response envelope
+func (rsc *RestController) MakeRestCall(c *gin.Context) {
+	var restRequest models.RestCallRequest
+	if err := c.ShouldBindJSON(&restRequest); err != nil {
+		log.Printf("Error binding JSON: %v", err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if err := validateRetryPolicy(restRequest.Retry); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	resolved, undefined, err := rsc.resolveRequest(restRequest, false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if len(undefined) > 0 {
+		log.Printf("Warning: undefined variables in request: %s", strings.Join(undefined, ", "))
+	}
+	restRequest = resolved
+
+	result := rsc.executor.ExecuteRest(c.Request.Context(), restRequest)
+	if result.Error != "" {
+		log.Printf("Error executing REST call: %s", result.Error)
+		c.JSON(http.StatusBadGateway, result)
+		return
+	}
+
+	log.Printf("REST call completed with status %s in %dms", result.Status, result.DurationMs)
+	c.JSON(http.StatusOK, result)
+}
+
+// ResolveRestRequest previews a request after variable substitution without sending it
+func (rsc *RestController) ResolveRestRequest(c *gin.Context) {
+	var restRequest models.RestCallRequest
+	if err := c.ShouldBindJSON(&restRequest); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	resolved, undefined, err := rsc.resolveRequest(restRequest, true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResolvedRestRequest{
+		Request:            resolved,
+		UndefinedVariables: undefined,
+	})
+}
+
+// resolveRequest substitutes {{name}} placeholders using the request, environment, collection and workspace variables.
+// A preview keeps {{$env NAME}} placeholders unexpanded.
+func (rsc *RestController) resolveRequest(restRequest models.RestCallRequest, preview bool) (models.RestCallRequest, []string, error) {
+	resolver, _, err := rsc.store.ResolverFor(restRequest.CollectionID, restRequest.EnvironmentID, restRequest.Variables)
+	if err != nil {
+		return restRequest, nil, err
+	}
+	resolver.keepEnvPlaceholders = preview
+	resolved := resolver.ResolveRestRequest(restRequest)
+	return resolved, resolver.Undefined(), nil
+}
@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"grpc-client/models"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	maxRetryAttempts         = 10
+	defaultInitialBackoff    = 100 * time.Millisecond
+	defaultMaxBackoff        = 5 * time.Second
+	defaultBackoffMultiplier = 2.0
+	defaultRetryJitter       = 0.2
+
+	// maxServerRetryDelay caps the delay a server can ask for with RetryInfo or Retry-After
+	maxServerRetryDelay = time.Minute
+
+	retryInfoDetailType = "google.rpc.RetryInfo"
+)
+
+// validateRetryPolicy checks the attempts, delays and statuses of a retry policy
+func validateRetryPolicy(policy *models.RetryPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.MaxAttempts < 1 || policy.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("retry maxAttempts must be between 1 and %d", maxRetryAttempts)
+	}
+	if policy.InitialBackoffMs < 0 || policy.MaxBackoffMs < 0 {
+		return fmt.Errorf("retry backoff must not be negative")
+	}
+	if policy.BackoffMultiplier != 0 && policy.BackoffMultiplier < 1 {
+		return fmt.Errorf("retry backoffMultiplier must be at least 1")
+	}
+	if policy.Jitter != nil && (*policy.Jitter < 0 || *policy.Jitter > 1) {
+		return fmt.Errorf("retry jitter must be between 0 and 1")
+	}
+	for _, status := range policy.RetryableStatuses {
+		switch status.(type) {
+		case string, float64:
+		default:
+			return fmt.Errorf("retryable status %v must be a status name or code", status)
+		}
+	}
+	return nil
+}
+
+// executeWithRetry runs a call until it succeeds, fails with a status the policy does not
+// retry, runs out of attempts or the context ends. The last attempt's result is returned
+// with every attempt recorded in Attempts; without a policy the call runs once.
+func executeWithRetry(ctx context.Context, policy *models.RetryPolicy, execute func() *models.CallResult) *models.CallResult {
+	if policy == nil || policy.MaxAttempts <= 1 {
+		return execute()
+	}
+
+	var attempts []models.CallAttempt
+	for attempt := 1; ; attempt++ {
+		result := execute()
+		record := models.CallAttempt{
+			Attempt:    attempt,
+			Status:     result.Status,
+			StatusCode: result.StatusCode,
+			Error:      result.Error,
+			StartedAt:  result.StartedAt,
+			DurationMs: result.DurationMs,
+		}
+
+		if attempt >= policy.MaxAttempts || !retryableResult(policy, result) || ctx.Err() != nil {
+			result.Attempts = append(attempts, record)
+			return result
+		}
+
+		delay, source := retryDelay(policy, result, attempt)
+		record.RetryDelayMs = delay.Milliseconds()
+		record.DelaySource = source
+		attempts = append(attempts, record)
+		log.Printf("Attempt %d failed with %s, retrying in %v", attempt, retryStatusLabel(result), delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			result.Attempts = attempts
+			return result
+		}
+	}
+}
+
+// retryableResult reports whether a failed call has a status the policy retries. REST calls
+// that got no response at all are retried like UNAVAILABLE gRPC calls.
+func retryableResult(policy *models.RetryPolicy, result *models.CallResult) bool {
+	if result.Succeeded() {
+		return false
+	}
+
+	restCall := result.Protocol == models.ProtocolREST || result.Protocol == models.ProtocolTranscoding
+	if restCall && result.Status == "" {
+		return true
+	}
+
+	statuses := policy.RetryableStatuses
+	if len(statuses) == 0 {
+		if restCall {
+			return result.StatusCode == 503
+		}
+		return result.StatusCode == int(codes.Unavailable)
+	}
+	for _, status := range statuses {
+		if statusMatches(result, formatFieldValue(status)) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay returns the wait before the next attempt and where it came from. A delay asked
+// for by the server wins over the backoff unless the policy ignores it.
+func retryDelay(policy *models.RetryPolicy, result *models.CallResult, attempt int) (time.Duration, string) {
+	if !policy.IgnoreRetryInfo {
+		if delay, ok := serverRetryInfoDelay(result); ok {
+			return delay, "retryInfo"
+		}
+		if delay, ok := retryAfterDelay(result); ok {
+			return delay, "retryAfter"
+		}
+	}
+
+	initial := defaultInitialBackoff
+	if policy.InitialBackoffMs > 0 {
+		initial = time.Duration(policy.InitialBackoffMs) * time.Millisecond
+	}
+	maxBackoff := defaultMaxBackoff
+	if policy.MaxBackoffMs > 0 {
+		maxBackoff = time.Duration(policy.MaxBackoffMs) * time.Millisecond
+	}
+	multiplier := defaultBackoffMultiplier
+	if policy.BackoffMultiplier > 0 {
+		multiplier = policy.BackoffMultiplier
+	}
+	jitter := defaultRetryJitter
+	if policy.Jitter != nil {
+		jitter = *policy.Jitter
+	}
+
+	backoff := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if backoff > float64(maxBackoff) {
+		backoff = float64(maxBackoff)
+	}
+	// Spread the delay over [backoff*(1-jitter), backoff*(1+jitter)]
+	backoff *= 1 + jitter*(2*rand.Float64()-1)
+	return time.Duration(backoff), "backoff"
+}
+
+// serverRetryInfoDelay reads the delay of a google.rpc.RetryInfo error detail
+func serverRetryInfoDelay(result *models.CallResult) (time.Duration, bool) {
+	for _, detail := range result.ErrorDetails {
+		decoded, ok := detail.(map[string]interface{})
+		if !ok || decoded["type"] != retryInfoDetailType {
+			continue
+		}
+		value, _ := decoded["value"].(map[string]interface{})
+		retryDelay, _ := value["retryDelay"].(string)
+		delay, err := time.ParseDuration(retryDelay)
+		if err != nil || delay < 0 {
+			continue
+		}
+		return capServerRetryDelay(delay), true
+	}
+	return 0, false
+}
+
+// retryAfterDelay reads a Retry-After header given in seconds
+func retryAfterDelay(result *models.CallResult) (time.Duration, bool) {
+	values := result.Headers["retry-after"]
+	if len(values) == 0 {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(strings.TrimSpace(values[0]))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return capServerRetryDelay(time.Duration(seconds) * time.Second), true
+}
+
+func capServerRetryDelay(delay time.Duration) time.Duration {
+	if delay > maxServerRetryDelay {
+		return maxServerRetryDelay
+	}
+	return delay
+}
+
+func retryStatusLabel(result *models.CallResult) string {
+	if result.Status == "" {
+		return "no response"
+	}
+	return result.Status
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"grpc-client/models"
+	"testing"
+	"time"
+)
+
+func TestRetryableResult(t *testing.T) {
+	unavailable := &models.CallResult{Protocol: models.ProtocolGRPC, Status: "UNAVAILABLE", StatusCode: 14, Error: "unavailable"}
+	notFound := &models.CallResult{Protocol: models.ProtocolGRPC, Status: "NOT_FOUND", StatusCode: 5, Error: "not found"}
+	serviceUnavailable := &models.CallResult{Protocol: models.ProtocolTranscoding, Status: "503 Service Unavailable", StatusCode: 503}
+	noResponse := &models.CallResult{Protocol: models.ProtocolREST, Error: "connection refused"}
+	succeeded := &models.CallResult{Protocol: models.ProtocolGRPC, Status: "OK"}
+
+	tests := []struct {
+		name     string
+		statuses []interface{}
+		result   *models.CallResult
+		want     bool
+	}{
+		{name: "success is not retried", result: succeeded, want: false},
+		{name: "gRPC default retries UNAVAILABLE", result: unavailable, want: true},
+		{name: "gRPC default skips other statuses", result: notFound, want: false},
+		{name: "HTTP default retries 503", result: serviceUnavailable, want: true},
+		{name: "HTTP without response", result: noResponse, want: true},
+		{name: "status name", statuses: []interface{}{"NOT_FOUND"}, result: notFound, want: true},
+		{name: "status code", statuses: []interface{}{float64(5)}, result: notFound, want: true},
+		{name: "listed statuses replace the default", statuses: []interface{}{"NOT_FOUND"}, result: unavailable, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &models.RetryPolicy{MaxAttempts: 3, RetryableStatuses: tt.statuses}
+			if got := retryableResult(policy, tt.result); got != tt.want {
+				t.Errorf("retryableResult() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	noJitter := 0.0
+	retryInfo := &models.CallResult{ErrorDetails: []interface{}{
+		map[string]interface{}{"type": retryInfoDetailType, "value": map[string]interface{}{"retryDelay": "300ms"}},
+	}}
+	retryAfter := &models.CallResult{Headers: map[string][]string{"retry-after": {"2"}}}
+	longRetryAfter := &models.CallResult{Headers: map[string][]string{"retry-after": {"3600"}}}
+
+	tests := []struct {
+		name       string
+		policy     models.RetryPolicy
+		result     *models.CallResult
+		attempt    int
+		wantDelay  time.Duration
+		wantSource string
+	}{
+		{name: "default backoff", result: &models.CallResult{}, attempt: 1, wantDelay: defaultInitialBackoff, wantSource: "backoff"},
+		{name: "backoff grows", policy: models.RetryPolicy{InitialBackoffMs: 100, BackoffMultiplier: 3}, result: &models.CallResult{}, attempt: 3, wantDelay: 900 * time.Millisecond, wantSource: "backoff"},
+		{name: "backoff is capped", policy: models.RetryPolicy{InitialBackoffMs: 100, MaxBackoffMs: 250}, result: &models.CallResult{}, attempt: 5, wantDelay: 250 * time.Millisecond, wantSource: "backoff"},
+		{name: "retry info", result: retryInfo, attempt: 1, wantDelay: 300 * time.Millisecond, wantSource: "retryInfo"},
+		{name: "retry after", result: retryAfter, attempt: 1, wantDelay: 2 * time.Second, wantSource: "retryAfter"},
+		{name: "retry after is capped", result: longRetryAfter, attempt: 1, wantDelay: maxServerRetryDelay, wantSource: "retryAfter"},
+		{name: "server delay ignored", policy: models.RetryPolicy{IgnoreRetryInfo: true}, result: retryInfo, attempt: 1, wantDelay: defaultInitialBackoff, wantSource: "backoff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.policy
+			policy.Jitter = &noJitter
+			delay, source := retryDelay(&policy, tt.result, tt.attempt)
+			if delay != tt.wantDelay || source != tt.wantSource {
+				t.Errorf("retryDelay() = %v, %q, want %v, %q", delay, source, tt.wantDelay, tt.wantSource)
+			}
+		})
+	}
+}
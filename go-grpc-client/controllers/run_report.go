@@ -0,0 +1,355 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"grpc-client/models"
+	"html/template"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runReportFormat describes a downloadable report of a collection run
+type runReportFormat struct {
+	contentType string
+	extension   string
+	render      func(result *models.CollectionRunResult) ([]byte, error)
+}
+
+// runReportFormats are the report formats selected with ?format=
+var runReportFormats = map[string]runReportFormat{
+	"junit": {contentType: "application/xml; charset=utf-8", extension: "xml", render: junitReport},
+	"html":  {contentType: "text/html; charset=utf-8", extension: "html", render: htmlReport},
+	"json":  {contentType: "application/json; charset=utf-8", extension: "json", render: jsonSummaryReport},
+}
+
+var reportFileNameInvalid = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// lookupRunReportFormat returns the report format with the given name
+func lookupRunReportFormat(name string) (runReportFormat, error) {
+	format, ok := runReportFormats[strings.ToLower(name)]
+	if !ok {
+		return format, fmt.Errorf("unsupported report format %q: use junit, html or json", name)
+	}
+	return format, nil
+}
+
+// reportFileName builds the download name of a report, e.g. "Smoke-tests-20240501-120000.xml"
+func reportFileName(result *models.CollectionRunResult, format runReportFormat) string {
+	name := strings.Trim(reportFileNameInvalid.ReplaceAllString(result.CollectionName, "-"), "-")
+	if name == "" {
+		name = "collection-run"
+	}
+	return fmt.Sprintf("%s-%s.%s", name, result.StartedAt.Format("20060102-150405"), format.extension)
+}
+
+// JUnit XML elements, as read by CI systems
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+// junitReport renders a run as JUnit XML with one test suite per iteration and one test case
+// per request. Requests that could not be sent are errors; all other non-passing requests
+// are failures.
+func junitReport(result *models.CollectionRunResult) ([]byte, error) {
+	suites := junitTestSuites{
+		Name: result.CollectionName,
+		Time: junitSeconds(result.DurationMs),
+	}
+
+	for _, iteration := range result.IterationResults {
+		suite := junitTestSuite{
+			Name:      result.CollectionName,
+			Time:      junitSeconds(iteration.DurationMs),
+			Timestamp: result.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+		}
+		if len(result.IterationResults) > 1 {
+			suite.Name = fmt.Sprintf("%s (iteration %d)", result.CollectionName, iteration.Iteration)
+		}
+		if result.EnvironmentID != "" {
+			suite.Properties = append(suite.Properties, junitProperty{Name: "environment", Value: result.EnvironmentID})
+		}
+		for _, column := range sortedKeys(iteration.Data) {
+			suite.Properties = append(suite.Properties, junitProperty{Name: "data." + column, Value: iteration.Data[column]})
+		}
+
+		for _, item := range result.Results {
+			if item.Iteration != iteration.Iteration {
+				continue
+			}
+			testCase := junitTestCase{
+				Name:      item.RequestName,
+				ClassName: result.CollectionName,
+				Time:      junitSeconds(item.DurationMs),
+			}
+			if item.Status != "" {
+				testCase.SystemOut = fmt.Sprintf("status: %s", item.Status)
+			}
+			if !item.Passed {
+				problem := &junitProblem{
+					Message: collectionItemFailure(item),
+					Type:    junitProblemType(item),
+					Details: strings.Join(itemFailureDetails(item), "\n"),
+				}
+				if problem.Type == "CallError" {
+					testCase.Error = problem
+					suite.Errors++
+				} else {
+					testCase.Failure = problem
+					suite.Failures++
+				}
+			}
+			suite.Cases = append(suite.Cases, testCase)
+			suite.Tests++
+		}
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	content, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(content, '\n')...), nil
+}
+
+// junitProblemType classifies why a request did not pass
+func junitProblemType(item models.CollectionRunItem) string {
+	switch {
+	case item.Status == "" && item.Error != "":
+		return "CallError"
+	case item.ScriptError != "":
+		return "ScriptError"
+	}
+	for _, assertion := range item.Assertions {
+		if !assertion.Passed {
+			return "AssertionFailure"
+		}
+	}
+	for _, test := range item.ScriptTests {
+		if !test.Passed {
+			return "TestFailure"
+		}
+	}
+	if item.Snapshot != nil && !item.Snapshot.Matched {
+		return "SnapshotMismatch"
+	}
+	return "UnexpectedStatus"
+}
+
+// itemFailureDetails lists every reason a request did not pass
+func itemFailureDetails(item models.CollectionRunItem) []string {
+	var details []string
+	if item.Error != "" {
+		details = append(details, item.Error)
+	}
+	if item.ScriptError != "" {
+		details = append(details, item.ScriptError)
+	}
+	for _, assertion := range item.Assertions {
+		if !assertion.Passed {
+			details = append(details, fmt.Sprintf("assertion %s failed: %s", assertion.Assertion.Type, assertion.Message))
+		}
+	}
+	for _, test := range item.ScriptTests {
+		if !test.Passed {
+			details = append(details, fmt.Sprintf("test %q failed: %s", test.Name, test.Error))
+		}
+	}
+	if item.Snapshot != nil {
+		for _, difference := range item.Snapshot.Differences {
+			details = append(details, "snapshot: "+describeDifference(difference))
+		}
+	}
+	if len(item.UndefinedVariables) > 0 {
+		details = append(details, "undefined variables: "+strings.Join(item.UndefinedVariables, ", "))
+	}
+	if len(details) == 0 {
+		details = append(details, fmt.Sprintf("call returned %s", item.Status))
+	}
+	return details
+}
+
+// sortedKeys returns the keys of a string map in order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func junitSeconds(durationMs int64) string {
+	return fmt.Sprintf("%.3f", float64(durationMs)/1000)
+}
+
+// jsonSummaryReport renders the counts of a run and the reasons of its failures
+func jsonSummaryReport(result *models.CollectionRunResult) ([]byte, error) {
+	summary := models.CollectionRunSummary{
+		CollectionID:   result.CollectionID,
+		CollectionName: result.CollectionName,
+		EnvironmentID:  result.EnvironmentID,
+		Iterations:     result.Iterations,
+		Total:          result.Total,
+		Passed:         result.Passed,
+		Failed:         result.Failed,
+		Stopped:        result.Stopped,
+		Failures:       []models.CollectionRunFailure{},
+		StartedAt:      result.StartedAt,
+		DurationMs:     result.DurationMs,
+	}
+	for _, item := range result.Results {
+		if !item.Passed {
+			summary.Failures = append(summary.Failures, models.CollectionRunFailure{
+				Iteration:   item.Iteration,
+				RequestID:   item.RequestID,
+				RequestName: item.RequestName,
+				Status:      item.Status,
+				Reasons:     itemFailureDetails(item),
+			})
+		}
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(summary); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// htmlReportItem is a request of the HTML report with its details rendered as JSON
+type htmlReportItem struct {
+	models.CollectionRunItem
+	Reasons  []string
+	Request  string
+	Response string
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Result.CollectionName}} - collection run</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 2rem; color: #1f2328; }
+h1 { margin-bottom: 0.25rem; }
+.meta { color: #59636e; margin-bottom: 1.5rem; }
+.counts span { display: inline-block; padding: 0.4rem 0.8rem; margin-right: 0.5rem; border-radius: 6px; background: #f6f8fa; }
+.passed { color: #1a7f37; }
+.failed { color: #cf222e; }
+table { border-collapse: collapse; width: 100%; margin: 1rem 0 2rem; }
+th, td { text-align: left; padding: 0.4rem 0.6rem; border-bottom: 1px solid #d1d9e0; vertical-align: top; }
+details { margin: 0.5rem 0; border: 1px solid #d1d9e0; border-radius: 6px; padding: 0.5rem 0.8rem; }
+summary { cursor: pointer; font-weight: 600; }
+pre { background: #f6f8fa; padding: 0.6rem; overflow-x: auto; font-size: 0.85rem; }
+ul.reasons { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>{{.Result.CollectionName}}</h1>
+<div class="meta">Started {{.StartedAt}}{{if .Result.EnvironmentID}} &middot; environment {{.Result.EnvironmentID}}{{end}} &middot; {{.Result.DurationMs}} ms{{if .Result.Stopped}} &middot; stopped early{{end}}</div>
+<div class="counts">
+<span>{{.Result.Total}} requests</span>
+<span class="passed">{{.Result.Passed}} passed</span>
+<span class="failed">{{.Result.Failed}} failed</span>
+{{if gt .Result.Iterations 1}}<span>{{.Result.Iterations}} iterations</span>{{end}}
+</div>
+<table>
+<tr><th>Iteration</th><th>Request</th><th>Status</th><th>Result</th><th>Duration</th></tr>
+{{range .Items}}<tr>
+<td>{{.Iteration}}</td><td>{{.RequestName}}</td><td>{{.Status}}</td>
+<td>{{if .Passed}}<span class="passed">passed</span>{{else}}<span class="failed">failed</span>{{end}}</td>
+<td>{{.DurationMs}} ms</td>
+</tr>
+{{end}}</table>
+{{range .Items}}<details{{if not .Passed}} open{{end}}>
+<summary>{{if .Passed}}<span class="passed">&#10003;</span>{{else}}<span class="failed">&#10007;</span>{{end}} {{.RequestName}}{{if gt $.Result.Iterations 1}} (iteration {{.Iteration}}){{end}}</summary>
+{{if .Reasons}}<ul class="reasons">{{range .Reasons}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Assertions}}<h4>Assertions</h4><ul>{{range .Assertions}}<li class="{{if .Passed}}passed{{else}}failed{{end}}">{{.Assertion.Type}}{{if .Assertion.Path}} {{.Assertion.Path}}{{end}}{{if .Message}}: {{.Message}}{{end}}</li>{{end}}</ul>{{end}}
+{{if .ScriptTests}}<h4>Tests</h4><ul>{{range .ScriptTests}}<li class="{{if .Passed}}passed{{else}}failed{{end}}">{{.Name}}{{if .Error}}: {{.Error}}{{end}}</li>{{end}}</ul>{{end}}
+{{if .Request}}<h4>Request</h4><pre>{{.Request}}</pre>{{end}}
+{{if .Response}}<h4>Response</h4><pre>{{.Response}}</pre>{{end}}
+</details>
+{{end}}
+</body>
+</html>
+`))
+
+// htmlReport renders a run as a self-contained HTML page. The request and response of each
+// item are included when the run kept them (includeDetails).
+func htmlReport(result *models.CollectionRunResult) ([]byte, error) {
+	items := make([]htmlReportItem, 0, len(result.Results))
+	for _, item := range result.Results {
+		reportItem := htmlReportItem{CollectionRunItem: item}
+		if !item.Passed {
+			reportItem.Reasons = itemFailureDetails(item)
+		}
+		if item.Request != nil {
+			content, _ := json.MarshalIndent(item.Request, "", "  ")
+			reportItem.Request = string(content)
+		}
+		if item.Response != nil {
+			content, _ := json.MarshalIndent(item.Response, "", "  ")
+			reportItem.Response = string(content)
+		}
+		items = append(items, reportItem)
+	}
+
+	var buffer bytes.Buffer
+	err := htmlReportTemplate.Execute(&buffer, map[string]interface{}{
+		"Result":    result,
+		"Items":     items,
+		"StartedAt": result.StartedAt.Format(time.RFC1123),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"grpc-client/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRunResult() *models.CollectionRunResult {
+	return &models.CollectionRunResult{
+		CollectionID:   "c1",
+		CollectionName: "Smoke tests",
+		EnvironmentID:  "staging",
+		Iterations:     2,
+		Total:          4,
+		Passed:         1,
+		Failed:         3,
+		StartedAt:      time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		DurationMs:     1500,
+		IterationResults: []models.CollectionIterationResult{
+			{Iteration: 1, Data: map[string]string{"user": "alice", "id": "1"}, Total: 2, Failed: 1, DurationMs: 700},
+			{Iteration: 2, Data: map[string]string{"user": "bob", "id": "2"}, Total: 2, Failed: 2, DurationMs: 800},
+		},
+		Results: []models.CollectionRunItem{
+			{Iteration: 1, RequestID: "r1", RequestName: "get <item>", Status: "OK", Passed: true, DurationMs: 250},
+			{
+				Iteration: 1, RequestID: "r2", RequestName: "list items", Status: "OK", DurationMs: 450,
+				Assertions: []models.AssertionResult{
+					{Assertion: models.Assertion{Type: models.AssertionJSONPath, Path: "$.items"}, Passed: false, Message: "expected 2 items, got 1"},
+				},
+			},
+			{Iteration: 2, RequestID: "r1", RequestName: "get <item>", Error: "connection refused", DurationMs: 3},
+			{
+				Iteration: 2, RequestID: "r2", RequestName: "list items", Status: "OK", DurationMs: 20,
+				ScriptTests:        []models.ScriptTestResult{{Name: "has items", Passed: false, Error: "expected true"}},
+				UndefinedVariables: []string{"token"},
+			},
+		},
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	content, err := junitReport(testRunResult())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), xml.Header) {
+		t.Errorf("report does not start with the XML header:\n%s", content)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(content, &report); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, content)
+	}
+	if report.Name != "Smoke tests" || report.Tests != 4 || report.Failures != 2 || report.Errors != 1 || report.Time != "1.500" {
+		t.Errorf("test suites = %s tests=%d failures=%d errors=%d time=%s", report.Name, report.Tests, report.Failures, report.Errors, report.Time)
+	}
+	if len(report.Suites) != 2 {
+		t.Fatalf("report has %d suites, want one per iteration", len(report.Suites))
+	}
+
+	first := report.Suites[0]
+	if first.Name != "Smoke tests (iteration 1)" || first.Tests != 2 || first.Failures != 1 || first.Errors != 0 || first.Timestamp != "2024-05-01T12:00:00" {
+		t.Errorf("first suite = %+v", first)
+	}
+	wantProperties := []junitProperty{{Name: "environment", Value: "staging"}, {Name: "data.id", Value: "1"}, {Name: "data.user", Value: "alice"}}
+	if !reflect.DeepEqual(first.Properties, wantProperties) {
+		t.Errorf("properties = %+v, want %+v", first.Properties, wantProperties)
+	}
+	passed := first.Cases[0]
+	if passed.Name != "get <item>" || passed.Failure != nil || passed.Error != nil || passed.Time != "0.250" || passed.SystemOut != "status: OK" {
+		t.Errorf("passed case = %+v", passed)
+	}
+	if failure := first.Cases[1].Failure; failure == nil || failure.Type != "AssertionFailure" || failure.Message != "assertion jsonPath failed: expected 2 items, got 1" {
+		t.Errorf("assertion failure = %+v", failure)
+	}
+
+	second := report.Suites[1]
+	if second.Errors != 1 || second.Failures != 1 {
+		t.Errorf("second suite errors=%d failures=%d, want 1 and 1", second.Errors, second.Failures)
+	}
+	if problem := second.Cases[0].Error; problem == nil || problem.Type != "CallError" || problem.Message != "connection refused" || second.Cases[0].Failure != nil {
+		t.Errorf("call error case = %+v", second.Cases[0])
+	}
+	wantDetails := "test \"has items\" failed: expected true\nundefined variables: token"
+	if failure := second.Cases[1].Failure; failure == nil || failure.Type != "TestFailure" || failure.Details != wantDetails {
+		t.Errorf("test failure = %+v", failure)
+	}
+}
+
+func TestJUnitReportSingleIteration(t *testing.T) {
+	result := testRunResult()
+	result.EnvironmentID = ""
+	result.IterationResults = result.IterationResults[:1]
+	result.IterationResults[0].Data = nil
+
+	content, err := junitReport(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report junitTestSuites
+	if err := xml.Unmarshal(content, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Suites) != 1 || report.Suites[0].Name != "Smoke tests" || report.Suites[0].Properties != nil || report.Tests != 2 {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestJunitProblemType(t *testing.T) {
+	tests := []struct {
+		name string
+		item models.CollectionRunItem
+		want string
+	}{
+		{name: "call error", item: models.CollectionRunItem{Error: "timeout"}, want: "CallError"},
+		{name: "error with a status", item: models.CollectionRunItem{Status: "NOT_FOUND", Error: "not found"}, want: "UnexpectedStatus"},
+		{name: "script error", item: models.CollectionRunItem{Status: "OK", ScriptError: "ReferenceError"}, want: "ScriptError"},
+		{name: "snapshot", item: models.CollectionRunItem{Status: "OK", Snapshot: &models.SnapshotResult{Matched: false}}, want: "SnapshotMismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := junitProblemType(tt.item); got != tt.want {
+				t.Errorf("junitProblemType() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLReport(t *testing.T) {
+	result := testRunResult()
+	result.Results[0].Response = &models.CallResult{Status: "OK", Body: map[string]interface{}{"note": "</pre><b>"}}
+
+	content, err := htmlReport(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(content)
+
+	for _, want := range []string{
+		"<title>Smoke tests - collection run</title>",
+		"environment staging",
+		"<span>4 requests</span>",
+		`<span class="passed">1 passed</span>`,
+		`<span class="failed">3 failed</span>`,
+		"<span>2 iterations</span>",
+		"get &lt;item&gt; (iteration 1)",
+		"<li>assertion jsonPath failed: expected 2 items, got 1</li>",
+		"<li>connection refused</li>",
+		"<details open>",
+		"<h4>Response</h4>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML report does not contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<item>") || strings.Contains(html, "</pre><b>") {
+		t.Error("HTML report contains unescaped values")
+	}
+	if got := strings.Count(html, "<details open>"); got != 3 {
+		t.Errorf("%d requests are expanded, want the 3 failed ones", got)
+	}
+}
+
+func TestJSONSummaryReport(t *testing.T) {
+	content, err := jsonSummaryReport(testRunResult())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary models.CollectionRunSummary
+	if err := json.Unmarshal(content, &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Total != 4 || summary.Failed != 3 || len(summary.Failures) != 3 {
+		t.Fatalf("summary = %+v", summary)
+	}
+	if failure := summary.Failures[1]; failure.Iteration != 2 || failure.RequestName != "get <item>" || !reflect.DeepEqual(failure.Reasons, []string{"connection refused"}) {
+		t.Errorf("second failure = %+v", failure)
+	}
+	if !strings.Contains(string(content), "get <item>") {
+		t.Error("JSON summary escapes HTML characters")
+	}
+}
+
+func TestRunReportFileName(t *testing.T) {
+	format, err := lookupRunReportFormat("JUnit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := testRunResult()
+	if got := reportFileName(result, format); got != "Smoke-tests-20240501-120000.xml" {
+		t.Errorf("reportFileName() = %s", got)
+	}
+	result.CollectionName = "//"
+	if got := reportFileName(result, format); got != "collection-run-20240501-120000.xml" {
+		t.Errorf("reportFileName() without a usable name = %s", got)
+	}
+	if _, err := lookupRunReportFormat("pdf"); err == nil {
+		t.Error("unsupported format should fail")
+	}
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"fmt"
+	"grpc-client/models"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunnerController runs requests saved in the workspace
+type RunnerController struct {
+	runner   *RequestRunner
+	notifier *NotificationDispatcher
+}
+
+func NewRunnerController(runner *RequestRunner, notifier *NotificationDispatcher) *RunnerController {
+	return &RunnerController{
+		runner:   runner,
+		notifier: notifier,
+	}
+}
+
+// RunRequest runs a saved request by ID. The environment defaults to the collection's
+// active environment and can be selected with "environmentId" in the body or query.
+// Call failures are part of the returned envelope; only lookup errors fail the request.
+// With data rows or more than one iteration the request is run like a one-request
+// collection and the collection run summary is returned. Failed runs are sent to the notifiers.
+func (rc *RunnerController) RunRequest(c *gin.Context) {
+	requestID := c.Param("requestId")
+	if requestID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Request ID is required"})
+		return
+	}
+
+	options, err := bindCollectionRunOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if options.EnvironmentID == "" {
+		options.EnvironmentID = c.Query("environmentId")
+	}
+
+	request, collection, err := rc.runner.FindRequest(requestID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if len(options.Data) > 0 || options.Iterations > 1 {
+		options.RequestIDs = []string{request.ID}
+		options.Tags = nil
+		runResult, err := rc.runner.RunCollection(c.Request.Context(), collection, options, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		rc.notifier.notifyCollectionRun(c.Request.Context(), runResult)
+		c.JSON(http.StatusOK, runResult)
+		return
+	}
+
+	result, err := rc.runner.RunRequest(c.Request.Context(), request, collection, models.RunRequestOptions{
+		EnvironmentID: options.EnvironmentID,
+		Variables:     options.Variables,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	log.Printf("Saved request %s finished with status %s in %dms", request.Name, result.Status, result.DurationMs)
+	rc.notifier.notifyRequestRun(c.Request.Context(), result, collection)
+	c.JSON(http.StatusOK, result)
+}
+
+// RunCollection runs the requests of a collection in order and returns a per-request summary.
+// Runs with failing requests are sent to the notifiers. With ?format=junit, html or json the
+// run is returned as a downloadable report instead.
+func (rc *RunnerController) RunCollection(c *gin.Context) {
+	collectionID := c.Param("id")
+	if collectionID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Collection ID is required"})
+		return
+	}
+
+	options, err := bindCollectionRunOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	var format *runReportFormat
+	if name := c.Query("format"); name != "" {
+		reportFormat, err := lookupRunReportFormat(name)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		format = &reportFormat
+		options.IncludeDetails = options.IncludeDetails || reportFormat.extension == "html"
+	}
+
+	collection, err := rc.runner.FindCollection(collectionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := rc.runner.RunCollection(c.Request.Context(), collection, options, nil)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	rc.notifier.notifyCollectionRun(c.Request.Context(), result)
+
+	if format != nil {
+		writeRunReport(c, result, *format)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// writeRunReport sends a collection run as a report download
+func writeRunReport(c *gin.Context, result *models.CollectionRunResult, format runReportFormat) {
+	content, err := format.render(result)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: fmt.Sprintf("Failed to render report: %v", err)})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", reportFileName(result, format)))
+	c.Data(http.StatusOK, format.contentType, content)
+}
+
+// bindCollectionRunOptions reads run options from a JSON body, or from a multipart form with
+// the options as JSON in the "options" field and a CSV or JSON data file in the "file" field
+func bindCollectionRunOptions(c *gin.Context) (models.CollectionRunOptions, error) {
+	var options models.CollectionRunOptions
+
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		if c.Request.ContentLength != 0 {
+			if err := decodeJSONWithNumbers(c.Request.Body, &options); err != nil {
+				return options, err
+			}
+		}
+		return options, nil
+	}
+
+	if rawOptions := c.PostForm("options"); strings.TrimSpace(rawOptions) != "" {
+		if err := decodeJSONWithNumbers(strings.NewReader(rawOptions), &options); err != nil {
+			return options, fmt.Errorf("invalid options: %v", err)
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err == http.ErrMissingFile {
+		return options, nil
+	}
+	if err != nil {
+		return options, err
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return options, err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return options, err
+	}
+
+	options.Data, err = parseDataFile(fileHeader.Filename, content)
+	if err != nil {
+		return options, err
+	}
+	return options, nil
+}
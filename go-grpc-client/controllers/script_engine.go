@@ -0,0 +1,364 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"grpc-client/models"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// scriptTimeout bounds how long a single script may run
+const scriptTimeout = 5 * time.Second
+
+// scriptSession holds the state shared by the scripts of one request run. Scripts run in
+// a fresh goja runtime without require, file or network access; they interact with the
+// run through the Postman-style "pm" object and console.
+type scriptSession struct {
+	resolver *VariableResolver
+	request  map[string]interface{} // Editable view of the request (pm.request)
+	response *models.CallResult     // Set after the call (pm.response)
+	tests    []models.ScriptTestResult
+	logs     []string
+
+	// Variables changed by scripts, persisted after the run; nil values were unset
+	environmentChanges map[string]*string
+	collectionChanges  map[string]*string
+}
+
+func newScriptSession(resolver *VariableResolver) *scriptSession {
+	return &scriptSession{
+		resolver:           resolver,
+		environmentChanges: make(map[string]*string),
+		collectionChanges:  make(map[string]*string),
+	}
+}
+
+// runScripts runs the given scripts in order, skipping empty ones
+func (ss *scriptSession) runScripts(phase string, sources ...string) error {
+	for _, source := range sources {
+		if strings.TrimSpace(source) == "" {
+			continue
+		}
+		if err := ss.run(phase, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ss *scriptSession) run(phase, source string) error {
+	vm := goja.New()
+
+	timer := time.AfterFunc(scriptTimeout, func() {
+		vm.Interrupt(fmt.Sprintf("%s script timed out after %s", phase, scriptTimeout))
+	})
+	defer timer.Stop()
+
+	if err := ss.install(vm); err != nil {
+		return err
+	}
+
+	if _, err := vm.RunString(source); err != nil {
+		return scriptError(err)
+	}
+
+	// Read back changes made to pm.request
+	if ss.response == nil {
+		pm := vm.Get("pm").ToObject(vm)
+		request, err := exportJSON(vm, pm.Get("request"))
+		if err != nil {
+			return fmt.Errorf("invalid pm.request: %v", err)
+		}
+		if requestMap, ok := request.(map[string]interface{}); ok {
+			ss.request = requestMap
+		}
+	}
+	return nil
+}
+
+// install defines console and pm in the runtime
+func (ss *scriptSession) install(vm *goja.Runtime) error {
+	console := vm.NewObject()
+	for _, level := range []string{"log", "info", "warn", "error"} {
+		level := level
+		console.Set(level, func(call goja.FunctionCall) goja.Value {
+			parts := make([]string, len(call.Arguments))
+			for i, argument := range call.Arguments {
+				parts[i] = scriptValueString(vm, argument)
+			}
+			message := strings.Join(parts, " ")
+			if level != "log" {
+				message = "[" + level + "] " + message
+			}
+			ss.logs = append(ss.logs, message)
+			return goja.Undefined()
+		})
+	}
+	vm.Set("console", console)
+
+	pm := vm.NewObject()
+	pm.Set("variables", ss.variableAccessor(vm, scopeRun, nil))
+	pm.Set("environment", ss.variableAccessor(vm, scopeEnvironment, ss.environmentChanges))
+	pm.Set("collectionVariables", ss.variableAccessor(vm, scopeCollection, ss.collectionChanges))
+
+	request, err := importJSON(vm, ss.request)
+	if err != nil {
+		return err
+	}
+	pm.Set("request", request)
+
+	if ss.response != nil {
+		response, err := importJSON(vm, ss.response)
+		if err != nil {
+			return err
+		}
+		responseObject := response.ToObject(vm)
+		body := responseObject.Get("body")
+		responseObject.Set("json", func(call goja.FunctionCall) goja.Value {
+			return body
+		})
+		responseObject.Set("text", func(call goja.FunctionCall) goja.Value {
+			return vm.ToValue(formatFieldValue(ss.response.Body))
+		})
+		pm.Set("response", responseObject)
+	}
+
+	pm.Set("test", func(name string, fn goja.Callable) {
+		result := models.ScriptTestResult{Name: name, Passed: true}
+		if _, err := fn(goja.Undefined()); err != nil {
+			var interrupted *goja.InterruptedError
+			if errors.As(err, &interrupted) {
+				// Keep the timeout in effect for the rest of the script
+				vm.Interrupt(interrupted.Value())
+			}
+			result.Passed = false
+			result.Error = scriptError(err).Error()
+		}
+		ss.tests = append(ss.tests, result)
+	})
+
+	vm.Set("pm", pm)
+	return nil
+}
+
+// variableAccessor builds the get/set/unset/has object of a variable scope.
+// pm.variables.get looks through all scopes, like a {{name}} placeholder.
+func (ss *scriptSession) variableAccessor(vm *goja.Runtime, scope string, changes map[string]*string) *goja.Object {
+	accessor := vm.NewObject()
+
+	accessor.Set("get", func(name string) goja.Value {
+		if scope == scopeRun {
+			if value, ok := ss.resolver.Lookup(name); ok {
+				return vm.ToValue(value)
+			}
+			return goja.Undefined()
+		}
+		if value, ok := ss.resolver.Scope(scope)[name]; ok {
+			return vm.ToValue(value)
+		}
+		return goja.Undefined()
+	})
+	accessor.Set("has", func(name string) bool {
+		_, ok := ss.resolver.Scope(scope)[name]
+		return ok
+	})
+	accessor.Set("set", func(name string, value goja.Value) {
+		variables := ss.resolver.Scope(scope)
+		if variables == nil {
+			panic(vm.NewTypeError(fmt.Sprintf("no %s is selected for this run", scope)))
+		}
+		text := scriptValueString(vm, value)
+		variables[name] = text
+		if changes != nil {
+			changes[name] = &text
+		}
+	})
+	accessor.Set("unset", func(name string) {
+		variables := ss.resolver.Scope(scope)
+		if variables == nil {
+			return
+		}
+		delete(variables, name)
+		if changes != nil {
+			changes[name] = nil
+		}
+	})
+
+	return accessor
+}
+
+// testsPassed reports whether every pm.test passed
+func (ss *scriptSession) testsPassed() bool {
+	for _, test := range ss.tests {
+		if !test.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// importJSON converts a Go value into a plain JavaScript value through JSON
+func importJSON(vm *goja.Runtime, value interface{}) (goja.Value, error) {
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	parse, _ := goja.AssertFunction(vm.Get("JSON").ToObject(vm).Get("parse"))
+	return parse(goja.Undefined(), vm.ToValue(string(jsonBytes)))
+}
+
+// exportJSON converts a JavaScript value into decoded JSON
+func exportJSON(vm *goja.Runtime, value goja.Value) (interface{}, error) {
+	stringify, _ := goja.AssertFunction(vm.Get("JSON").ToObject(vm).Get("stringify"))
+	text, err := stringify(goja.Undefined(), value)
+	if err != nil {
+		return nil, err
+	}
+	if goja.IsUndefined(text) {
+		return nil, nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(text.String()), &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// scriptValueString converts a value to text; objects and arrays are JSON encoded
+func scriptValueString(vm *goja.Runtime, value goja.Value) string {
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return ""
+	}
+	if _, isObject := value.(*goja.Object); isObject {
+		if decoded, err := exportJSON(vm, value); err == nil && decoded != nil {
+			return formatFieldValue(decoded)
+		}
+	}
+	return value.String()
+}
+
+// scriptError turns a goja error into a readable message
+func scriptError(err error) error {
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		return errors.New(exception.Value().String())
+	}
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		return fmt.Errorf("%v", interrupted.Value())
+	}
+	return err
+}
+
+// grpcRequestView exposes a gRPC call to scripts as pm.request
+func grpcRequestView(grpcRequest models.GrpcRequest) map[string]interface{} {
+	metadata := make(map[string]interface{}, len(grpcRequest.MetaData))
+	for key, value := range grpcRequest.MetaData {
+		metadata[key] = value
+	}
+	return map[string]interface{}{
+		"host":     grpcRequest.Host,
+		"method":   grpcRequest.Method,
+		"message":  grpcRequest.Message,
+		"metadata": metadata,
+	}
+}
+
+// applyGrpcRequestView copies script changes of pm.request back into the call
+func applyGrpcRequestView(view map[string]interface{}, grpcRequest *models.GrpcRequest) {
+	if host, ok := view["host"].(string); ok {
+		grpcRequest.Host = host
+	}
+	if method, ok := view["method"].(string); ok {
+		grpcRequest.Method = method
+	}
+	grpcRequest.Message = view["message"]
+	if metadata, ok := view["metadata"].(map[string]interface{}); ok {
+		grpcRequest.MetaData = make(map[string]string, len(metadata))
+		for key, value := range metadata {
+			grpcRequest.MetaData[key] = formatFieldValue(value)
+		}
+	}
+}
+
+// restRequestView exposes a REST call to scripts as pm.request
+func restRequestView(restRequest models.RestCallRequest) map[string]interface{} {
+	headers := make(map[string]interface{})
+	for _, header := range restRequest.RESTConfig.Headers {
+		if header.Enabled && header.Key != "" {
+			headers[header.Key] = header.Value
+		}
+	}
+	params := make(map[string]interface{})
+	for _, param := range restRequest.RESTConfig.Params {
+		if param.Enabled && param.Key != "" {
+			params[param.Key] = param.Value
+		}
+	}
+	return map[string]interface{}{
+		"host":    restRequest.Host,
+		"method":  restRequest.RESTConfig.Method,
+		"url":     restRequest.RESTConfig.URL,
+		"headers": headers,
+		"params":  params,
+		"body":    restRequest.RESTConfig.Body,
+	}
+}
+
+// applyRestRequestView copies script changes of pm.request back into the call
+func applyRestRequestView(view map[string]interface{}, restRequest *models.RestCallRequest) {
+	if host, ok := view["host"].(string); ok {
+		restRequest.Host = host
+	}
+	if method, ok := view["method"].(string); ok {
+		restRequest.RESTConfig.Method = method
+	}
+	if requestURL, ok := view["url"].(string); ok {
+		restRequest.RESTConfig.URL = requestURL
+	}
+	if headers, ok := view["headers"].(map[string]interface{}); ok {
+		restRequest.RESTConfig.Headers = headerList(headers)
+	}
+	if params, ok := view["params"].(map[string]interface{}); ok {
+		restRequest.RESTConfig.Params = headerList(params)
+	}
+	restRequest.RESTConfig.Body = view["body"]
+}
+
+func headerList(values map[string]interface{}) []models.RequestHeader {
+	headers := make([]models.RequestHeader, 0, len(values))
+	for key, value := range values {
+		headers = append(headers, models.RequestHeader{Key: key, Value: formatFieldValue(value), Enabled: true})
+	}
+	return headers
+}
+
+// evaluateCondition evaluates a JavaScript expression such as "steps.create.passed && vars.region === 'eu'".
+// The context values are exposed as global variables.
+func evaluateCondition(expression string, context map[string]interface{}) (bool, error) {
+	vm := goja.New()
+
+	timer := time.AfterFunc(scriptTimeout, func() {
+		vm.Interrupt(fmt.Sprintf("condition timed out after %s", scriptTimeout))
+	})
+	defer timer.Stop()
+
+	for name, value := range context {
+		jsValue, err := importJSON(vm, value)
+		if err != nil {
+			return false, err
+		}
+		vm.Set(name, jsValue)
+	}
+
+	result, err := vm.RunString(expression)
+	if err != nil {
+		return false, scriptError(err)
+	}
+	return result.ToBoolean(), nil
+}
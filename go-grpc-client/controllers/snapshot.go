@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"grpc-client/models"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxResponseDifferences caps the differences reported for one comparison
+const maxResponseDifferences = 100
+
+var plainJSONKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// newSnapshot turns a response into a snapshot with the given ignore paths
+func newSnapshot(result *models.CallResult, ignorePaths []string) (*models.ResponseSnapshot, error) {
+	if err := validateIgnorePaths(ignorePaths); err != nil {
+		return nil, err
+	}
+	return &models.ResponseSnapshot{
+		Status:      result.Status,
+		StatusCode:  result.StatusCode,
+		Body:        normalizeJSONValue(result.Body),
+		IgnorePaths: ignorePaths,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// validateIgnorePaths checks that every ignore path parses
+func validateIgnorePaths(ignorePaths []string) error {
+	for _, path := range ignorePaths {
+		if _, err := parseJSONPath(path); err != nil {
+			return fmt.Errorf("invalid ignore path %q: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// compareSnapshot compares a response with a snapshot field by field. Differences at or
+// below an ignore path are left out.
+func compareSnapshot(snapshot *models.ResponseSnapshot, result *models.CallResult) *models.SnapshotResult {
+	expected := &models.CallResult{Status: snapshot.Status, StatusCode: snapshot.StatusCode, Body: snapshot.Body}
+	differences := diffResponses(expected, result, snapshot.IgnorePaths)
+	return &models.SnapshotResult{
+		Matched:     len(differences) == 0,
+		Differences: differences,
+	}
+}
+
+// diffResponses lists the differences in status and body between an expected and an actual
+// response, leaving out fields at or below the ignore paths
+func diffResponses(expected, actual *models.CallResult, ignorePaths []string) []models.ResponseDifference {
+	differences := []models.ResponseDifference{}
+
+	var ignored [][]jsonPathSegment
+	for _, path := range ignorePaths {
+		if segments, err := parseJSONPath(path); err == nil {
+			ignored = append(ignored, segments)
+		}
+	}
+
+	if expected.Status != actual.Status || expected.StatusCode != actual.StatusCode {
+		differences = append(differences, models.ResponseDifference{
+			Path:     "status",
+			Kind:     models.DifferenceChanged,
+			Expected: expected.Status,
+			Actual:   actual.Status,
+		})
+	}
+	diffJSONValues(nil, normalizeJSONValue(expected.Body), normalizeJSONValue(actual.Body), ignored, &differences)
+	return differences
+}
+
+// diffJSONValues appends the differences between two decoded JSON values
+func diffJSONValues(path []jsonPathSegment, expected, actual interface{}, ignored [][]jsonPathSegment, differences *[]models.ResponseDifference) {
+	if len(*differences) >= maxResponseDifferences || ignoredPath(path, ignored) {
+		return
+	}
+
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(expectedValue)+len(actualValue))
+		for key := range expectedValue {
+			keys = append(keys, key)
+		}
+		for key := range actualValue {
+			if _, ok := expectedValue[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fieldPath := append(append([]jsonPathSegment{}, path...), jsonPathSegment{key: key})
+			expectedField, inExpected := expectedValue[key]
+			actualField, inActual := actualValue[key]
+			switch {
+			case !inActual:
+				addResponseDifference(fieldPath, models.DifferenceRemoved, expectedField, nil, ignored, differences)
+			case !inExpected:
+				addResponseDifference(fieldPath, models.DifferenceAdded, nil, actualField, ignored, differences)
+			default:
+				diffJSONValues(fieldPath, expectedField, actualField, ignored, differences)
+			}
+		}
+		return
+
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(expectedValue) || i < len(actualValue); i++ {
+			itemPath := append(append([]jsonPathSegment{}, path...), jsonPathSegment{index: i, isIndex: true})
+			switch {
+			case i >= len(actualValue):
+				addResponseDifference(itemPath, models.DifferenceRemoved, expectedValue[i], nil, ignored, differences)
+			case i >= len(expectedValue):
+				addResponseDifference(itemPath, models.DifferenceAdded, nil, actualValue[i], ignored, differences)
+			default:
+				diffJSONValues(itemPath, expectedValue[i], actualValue[i], ignored, differences)
+			}
+		}
+		return
+	}
+
+	switch {
+	case jsonTypeName(expected) != jsonTypeName(actual):
+		addResponseDifference(path, models.DifferenceTypeChanged, expected, actual, ignored, differences)
+	case !reflect.DeepEqual(expected, actual):
+		addResponseDifference(path, models.DifferenceChanged, expected, actual, ignored, differences)
+	}
+}
+
+func addResponseDifference(path []jsonPathSegment, kind string, expected, actual interface{}, ignored [][]jsonPathSegment, differences *[]models.ResponseDifference) {
+	if len(*differences) >= maxResponseDifferences || ignoredPath(path, ignored) {
+		return
+	}
+	*differences = append(*differences, models.ResponseDifference{
+		Path:     formatJSONPath(path),
+		Kind:     kind,
+		Expected: expected,
+		Actual:   actual,
+	})
+}
+
+// ignoredPath reports whether a field is at or below one of the ignore paths. Wildcards
+// match any key or index; negative indexes are not supported in ignore paths.
+func ignoredPath(path []jsonPathSegment, ignored [][]jsonPathSegment) bool {
+	for _, pattern := range ignored {
+		if len(pattern) > len(path) {
+			continue
+		}
+		matched := true
+		for i, segment := range pattern {
+			switch {
+			case segment.wildcard:
+			case segment.isIndex:
+				matched = path[i].isIndex && path[i].index == segment.index
+			default:
+				matched = !path[i].isIndex && path[i].key == segment.key
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// formatJSONPath renders path segments as "$.items[0].id", quoting keys that are not plain names
+func formatJSONPath(path []jsonPathSegment) string {
+	var builder strings.Builder
+	builder.WriteString("$")
+	for _, segment := range path {
+		switch {
+		case segment.isIndex:
+			builder.WriteString("[" + strconv.Itoa(segment.index) + "]")
+		case plainJSONKey.MatchString(segment.key):
+			builder.WriteString("." + segment.key)
+		default:
+			builder.WriteString("['" + segment.key + "']")
+		}
+	}
+	return builder.String()
+}
+
+// normalizeJSONValue converts a value to its decoded JSON form, so stored snapshots and live
+// responses compare alike
+func normalizeJSONValue(value interface{}) interface{} {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(content, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// describeDifference renders a difference as one line, e.g. "$.id changed: "a" -> "b""
+func describeDifference(difference models.ResponseDifference) string {
+	switch difference.Kind {
+	case models.DifferenceAdded:
+		return fmt.Sprintf("%s added: %s", difference.Path, describeValue(difference.Actual))
+	case models.DifferenceRemoved:
+		return fmt.Sprintf("%s removed: %s", difference.Path, describeValue(difference.Expected))
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", difference.Path, difference.Kind, describeValue(difference.Expected), describeValue(difference.Actual))
+}
+
+// snapshotFailure describes a snapshot mismatch by its first difference
+func snapshotFailure(snapshot *models.SnapshotResult) string {
+	if len(snapshot.Differences) == 0 {
+		return "response differs from snapshot"
+	}
+	first := snapshot.Differences[0]
+	message := fmt.Sprintf("response differs from snapshot at %s (%s)", first.Path, first.Kind)
+	if len(snapshot.Differences) > 1 {
+		message += fmt.Sprintf(" and %d more", len(snapshot.Differences)-1)
+	}
+	return message
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"grpc-client/constants"
+	"grpc-client/models"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errNoSnapshot is returned when a snapshot is changed on a request that has none
+var errNoSnapshot = errors.New("request has no snapshot")
+
+// SnapshotController manages the golden response snapshots of saved requests
+type SnapshotController struct {
+	runner *RequestRunner
+	store  *WorkspaceStore
+}
+
+func NewSnapshotController(runner *RequestRunner, store *WorkspaceStore) *SnapshotController {
+	return &SnapshotController{
+		runner: runner,
+		store:  store,
+	}
+}
+
+// GetSnapshot returns the snapshot of a saved request
+func (sc *SnapshotController) GetSnapshot(c *gin.Context) {
+	request, _, err := sc.runner.FindRequest(c.Param("requestId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if request.Snapshot == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Request has no snapshot"})
+		return
+	}
+	c.JSON(http.StatusOK, request.Snapshot)
+}
+
+// SaveSnapshot marks a response as the snapshot of a saved request, replacing any earlier
+// one. Without a response in the body the request is run and its response is used. The
+// ignore paths of the earlier snapshot are kept unless new ones are given.
+func (sc *SnapshotController) SaveSnapshot(c *gin.Context) {
+	var save models.SaveSnapshotRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&save); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+			return
+		}
+	}
+
+	request, collection, err := sc.runner.FindRequest(c.Param("requestId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := save.Response
+	if response == nil {
+		run, err := sc.runner.RunRequest(c.Request.Context(), request, collection, models.RunRequestOptions{
+			EnvironmentID: save.EnvironmentID,
+			Variables:     save.Variables,
+		})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if run.Status == "" {
+			c.JSON(http.StatusBadGateway, models.ErrorResponse{Error: fmt.Sprintf("Request returned no response: %s", run.Error)})
+			return
+		}
+		response = run.CallResult
+	}
+
+	ignorePaths := save.IgnorePaths
+	if ignorePaths == nil && request.Snapshot != nil {
+		ignorePaths = request.Snapshot.IgnorePaths
+	}
+	snapshot, err := newSnapshot(response, ignorePaths)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	sc.updateSnapshot(c, request.ID, "Snapshot saved successfully", func(current *models.ResponseSnapshot) (*models.ResponseSnapshot, error) {
+		return snapshot, nil
+	})
+}
+
+// UpdateSnapshotIgnorePaths replaces the ignore paths of a snapshot
+func (sc *SnapshotController) UpdateSnapshotIgnorePaths(c *gin.Context) {
+	var update struct {
+		IgnorePaths []string `json:"ignorePaths"`
+	}
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if err := validateIgnorePaths(update.IgnorePaths); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	sc.updateSnapshot(c, c.Param("requestId"), "Snapshot updated successfully", func(current *models.ResponseSnapshot) (*models.ResponseSnapshot, error) {
+		if current == nil {
+			return nil, errNoSnapshot
+		}
+		updated := *current
+		updated.IgnorePaths = update.IgnorePaths
+		return &updated, nil
+	})
+}
+
+// DeleteSnapshot removes the snapshot of a saved request
+func (sc *SnapshotController) DeleteSnapshot(c *gin.Context) {
+	sc.updateSnapshot(c, c.Param("requestId"), "Snapshot deleted successfully", func(current *models.ResponseSnapshot) (*models.ResponseSnapshot, error) {
+		if current == nil {
+			return nil, errNoSnapshot
+		}
+		return nil, nil
+	})
+}
+
+// updateSnapshot replaces the snapshot of a request in the workspace with the one returned
+// by update and writes the response
+func (sc *SnapshotController) updateSnapshot(c *gin.Context, requestID, message string, update func(current *models.ResponseSnapshot) (*models.ResponseSnapshot, error)) {
+	if _, _, err := sc.runner.FindRequest(requestID); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	found := false
+	var snapshot *models.ResponseSnapshot
+	err := sc.store.Update(func(workspace *models.Workspace) error {
+		for i := range workspace.Collections {
+			for j := range workspace.Collections[i].Requests {
+				request := &workspace.Collections[i].Requests[j]
+				if request.ID != requestID {
+					continue
+				}
+				updated, err := update(request.Snapshot)
+				if err != nil {
+					return err
+				}
+				request.Snapshot = updated
+				request.UpdatedAt = time.Now()
+				snapshot = updated
+				found = true
+				return nil
+			}
+		}
+		return nil
+	})
+	if err == errNoSnapshot {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Request has no snapshot"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error saving snapshot: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to save snapshot"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Cannot update requests in read-only collections"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: message,
+		Status:  constants.ResponseStatusSuccess,
+		Data:    snapshot,
+	})
+}
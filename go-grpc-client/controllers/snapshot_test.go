@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"encoding/json"
+	"grpc-client/models"
+	"reflect"
+	"testing"
+)
+
+func TestIgnoredPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		ignored []string
+		want    bool
+	}{
+		{name: "no ignore paths", path: "$.id", want: false},
+		{name: "exact key", path: "$.id", ignored: []string{"$.id"}, want: true},
+		{name: "below ignored key", path: "$.meta.updatedAt", ignored: []string{"$.meta"}, want: true},
+		{name: "above ignored key", path: "$.meta", ignored: []string{"$.meta.updatedAt"}, want: false},
+		{name: "other key", path: "$.name", ignored: []string{"$.id"}, want: false},
+		{name: "index", path: "$.items[1].id", ignored: []string{"$.items[1]"}, want: true},
+		{name: "other index", path: "$.items[0].id", ignored: []string{"$.items[1]"}, want: false},
+		{name: "wildcard index", path: "$.items[3].id", ignored: []string{"$.items[*].id"}, want: true},
+		{name: "wildcard key", path: "$.a.id", ignored: []string{"$.*.id"}, want: true},
+		{name: "quoted key", path: "$['odd key'].value", ignored: []string{"$['odd key']"}, want: true},
+		{name: "index does not match key", path: "$.items.0", ignored: []string{"$.items[0]"}, want: false},
+		{name: "any of several paths", path: "$.b", ignored: []string{"$.a", "$.b"}, want: true},
+		{name: "root ignores everything", path: "$.a", ignored: []string{"$"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := mustParseJSONPath(t, tt.path)
+			var ignored [][]jsonPathSegment
+			for _, ignorePath := range tt.ignored {
+				ignored = append(ignored, mustParseJSONPath(t, ignorePath))
+			}
+			if got := ignoredPath(path, ignored); got != tt.want {
+				t.Errorf("ignoredPath(%q, %q) = %v, want %v", tt.path, tt.ignored, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffJSONValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		ignored  []string
+		want     []models.ResponseDifference
+	}{
+		{name: "equal", expected: `{"a": [1, {"b": null}]}`, actual: `{"a": [1, {"b": null}]}`},
+		{
+			name:     "changed value",
+			expected: `{"id": "1"}`,
+			actual:   `{"id": "2"}`,
+			want:     []models.ResponseDifference{{Path: "$.id", Kind: models.DifferenceChanged, Expected: "1", Actual: "2"}},
+		},
+		{
+			name:     "changed type",
+			expected: `{"id": "1"}`,
+			actual:   `{"id": 1}`,
+			want:     []models.ResponseDifference{{Path: "$.id", Kind: models.DifferenceTypeChanged, Expected: "1", Actual: float64(1)}},
+		},
+		{
+			name:     "added and removed keys in order",
+			expected: `{"b": 1, "c": 2}`,
+			actual:   `{"a": 0, "b": 1}`,
+			want: []models.ResponseDifference{
+				{Path: "$.a", Kind: models.DifferenceAdded, Actual: float64(0)},
+				{Path: "$.c", Kind: models.DifferenceRemoved, Expected: float64(2)},
+			},
+		},
+		{
+			name:     "array items",
+			expected: `{"items": [1, 2, 3]}`,
+			actual:   `{"items": [1, 5]}`,
+			want: []models.ResponseDifference{
+				{Path: "$.items[1]", Kind: models.DifferenceChanged, Expected: float64(2), Actual: float64(5)},
+				{Path: "$.items[2]", Kind: models.DifferenceRemoved, Expected: float64(3)},
+			},
+		},
+		{
+			name:     "quoted key in path",
+			expected: `{"odd key": true}`,
+			actual:   `{"odd key": false}`,
+			want:     []models.ResponseDifference{{Path: "$['odd key']", Kind: models.DifferenceChanged, Expected: true, Actual: false}},
+		},
+		{
+			name:     "object replaced by array",
+			expected: `{"a": {}}`,
+			actual:   `{"a": []}`,
+			want:     []models.ResponseDifference{{Path: "$.a", Kind: models.DifferenceTypeChanged, Expected: map[string]interface{}{}, Actual: []interface{}{}}},
+		},
+		{
+			name:     "ignored fields",
+			expected: `{"id": "1", "meta": {"at": 1}, "items": [{"ts": 1, "v": "a"}]}`,
+			actual:   `{"id": "1", "meta": {"at": 2, "by": "x"}, "items": [{"ts": 2, "v": "b"}, {"ts": 3}]}`,
+			ignored:  []string{"$.meta", "$.items[*].ts", "$.items[1]"},
+			want:     []models.ResponseDifference{{Path: "$.items[0].v", Kind: models.DifferenceChanged, Expected: "a", Actual: "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var expected, actual interface{}
+			if err := json.Unmarshal([]byte(tt.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.actual), &actual); err != nil {
+				t.Fatal(err)
+			}
+			var ignored [][]jsonPathSegment
+			for _, ignorePath := range tt.ignored {
+				ignored = append(ignored, mustParseJSONPath(t, ignorePath))
+			}
+
+			var differences []models.ResponseDifference
+			diffJSONValues(nil, expected, actual, ignored, &differences)
+			if !reflect.DeepEqual(differences, tt.want) {
+				t.Errorf("diffJSONValues() = %+v, want %+v", differences, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffResponsesStatus(t *testing.T) {
+	expected := &models.CallResult{Status: "OK", Body: map[string]interface{}{"id": "1"}}
+	actual := &models.CallResult{Status: "NOT_FOUND", StatusCode: 5, Body: map[string]interface{}{"id": "1"}}
+
+	differences := diffResponses(expected, actual, nil)
+	want := []models.ResponseDifference{{Path: "status", Kind: models.DifferenceChanged, Expected: "OK", Actual: "NOT_FOUND"}}
+	if !reflect.DeepEqual(differences, want) {
+		t.Errorf("diffResponses() = %+v, want %+v", differences, want)
+	}
+}
+
+func mustParseJSONPath(t *testing.T, path string) []jsonPathSegment {
+	t.Helper()
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		t.Fatalf("parseJSONPath(%q) error = %v", path, err)
+	}
+	return segments
+}
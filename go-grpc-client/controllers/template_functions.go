@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const randomStringAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+const (
+	// allowedEnvPrefix marks server environment variables that {{$env NAME}} may read
+	allowedEnvPrefix = "GRPC_CLIENT_VAR_"
+	// allowedEnvListEnv lists further comma separated variable names that {{$env NAME}} may read
+	allowedEnvListEnv = "GRPC_CLIENT_ALLOWED_ENV"
+)
+
+// templateFunctions are the built-in generators available as {{$name args...}}. Each function
+// gets the whitespace separated arguments and the raw argument text (used by $base64).
+// Every placeholder is evaluated separately, so two {{$uuid}} in one request differ.
+var templateFunctions = map[string]func(args []string, raw string) (string, error){
+	"uuid": func(args []string, raw string) (string, error) {
+		return uuid.New().String(), nil
+	},
+	"timestamp": func(args []string, raw string) (string, error) {
+		return strconv.FormatInt(time.Now().Unix(), 10), nil
+	},
+	"timestampMs": func(args []string, raw string) (string, error) {
+		return strconv.FormatInt(time.Now().UnixMilli(), 10), nil
+	},
+	"isoDate": func(args []string, raw string) (string, error) {
+		return time.Now().UTC().Format(time.RFC3339), nil
+	},
+	"randomInt": func(args []string, raw string) (string, error) {
+		min, max := int64(0), int64(1000)
+		if len(args) > 0 {
+			value, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return "", fmt.Errorf("invalid min %q", args[0])
+			}
+			min = value
+		}
+		if len(args) > 1 {
+			value, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return "", fmt.Errorf("invalid max %q", args[1])
+			}
+			max = value
+		}
+		if max < min {
+			return "", fmt.Errorf("max %d is lower than min %d", max, min)
+		}
+		return strconv.FormatInt(randomInt64(min, max), 10), nil
+	},
+	"randomString": func(args []string, raw string) (string, error) {
+		length := 16
+		if len(args) > 0 {
+			value, err := strconv.Atoi(args[0])
+			if err != nil || value < 0 {
+				return "", fmt.Errorf("invalid length %q", args[0])
+			}
+			length = value
+		}
+		var builder strings.Builder
+		for i := 0; i < length; i++ {
+			builder.WriteByte(randomStringAlphabet[rand.Intn(len(randomStringAlphabet))])
+		}
+		return builder.String(), nil
+	},
+	"base64": func(args []string, raw string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(raw)), nil
+	},
+	"env": func(args []string, raw string) (string, error) {
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing variable name")
+		}
+		if !envVariableAllowed(args[0]) {
+			return "", fmt.Errorf("environment variable %s is not allowed; use the %s prefix or list it in %s", args[0], allowedEnvPrefix, allowedEnvListEnv)
+		}
+		value, ok := os.LookupEnv(args[0])
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", args[0])
+		}
+		return value, nil
+	},
+}
+
+// randomInt64 returns a random number in [min, max]. The span is computed unsigned, since
+// max-min+1 overflows int64 for ranges covering most of it.
+func randomInt64(min, max int64) int64 {
+	span := uint64(max) - uint64(min)
+	if span < math.MaxInt64 {
+		return min + rand.Int63n(int64(span)+1)
+	}
+	for {
+		// At least half of all values are in range, so this ends quickly
+		offset := rand.Uint64()
+		if span == math.MaxUint64 || offset <= span {
+			return int64(uint64(min) + offset)
+		}
+	}
+}
+
+// envVariableAllowed reports whether {{$env NAME}} may read a server environment variable:
+// its name has the GRPC_CLIENT_VAR_ prefix or is listed in GRPC_CLIENT_ALLOWED_ENV
+func envVariableAllowed(name string) bool {
+	if strings.HasPrefix(name, allowedEnvPrefix) && len(name) > len(allowedEnvPrefix) {
+		return true
+	}
+	for _, allowed := range strings.Split(os.Getenv(allowedEnvListEnv), ",") {
+		if allowed = strings.TrimSpace(allowed); allowed != "" && allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnvTemplateFunction reports whether a placeholder expression calls $env
+func isEnvTemplateFunction(expression string) bool {
+	name, _, _ := strings.Cut(strings.TrimPrefix(expression, "$"), " ")
+	return name == "env"
+}
+
+// evaluateTemplateFunction evaluates an expression such as "$randomInt 1 10" (without the braces)
+func evaluateTemplateFunction(expression string) (string, error) {
+	expression = strings.TrimPrefix(expression, "$")
+	name, raw, _ := strings.Cut(expression, " ")
+	raw = strings.TrimSpace(raw)
+
+	function, ok := templateFunctions[name]
+	if !ok {
+		return "", fmt.Errorf("unknown function $%s", name)
+	}
+	return function(strings.Fields(raw), raw)
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestEvaluateTemplateFunction(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		check      func(string) bool
+		wantErr    bool
+	}{
+		{name: "base64 of raw arguments", expression: "$base64 a b", check: func(v string) bool { return v == "YSBi" }},
+		{name: "uuid", expression: "$uuid", check: func(v string) bool { return len(v) == 36 }},
+		{name: "random string length", expression: "$randomString 5", check: func(v string) bool { return len(v) == 5 }},
+		{name: "random string invalid length", expression: "$randomString -1", wantErr: true},
+		{name: "random int in range", expression: "$randomInt 3 5", check: intBetween(3, 5)},
+		{name: "random int single value", expression: "$randomInt 7 7", check: intBetween(7, 7)},
+		{name: "random int negative range", expression: "$randomInt -10 -5", check: intBetween(-10, -5)},
+		{name: "random int up to max int64", expression: "$randomInt 0 9223372036854775807", check: intBetween(0, math.MaxInt64)},
+		{name: "random int over all of int64", expression: "$randomInt -9223372036854775808 9223372036854775807", check: intBetween(math.MinInt64, math.MaxInt64)},
+		{name: "random int at the upper end", expression: "$randomInt 9223372036854775806 9223372036854775807", check: intBetween(math.MaxInt64-1, math.MaxInt64)},
+		{name: "random int max below min", expression: "$randomInt 5 3", wantErr: true},
+		{name: "random int invalid bound", expression: "$randomInt a", wantErr: true},
+		{name: "unknown function", expression: "$nope", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluateTemplateFunction(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("evaluateTemplateFunction(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+			}
+			if !tt.wantErr && !tt.check(got) {
+				t.Errorf("evaluateTemplateFunction(%q) = %q", tt.expression, got)
+			}
+		})
+	}
+}
+
+func TestEnvTemplateFunction(t *testing.T) {
+	t.Setenv("GRPC_CLIENT_VAR_TOKEN", "allowed-token")
+	t.Setenv("GRPC_CLIENT_TEST_LISTED", "listed")
+	t.Setenv("GRPC_CLIENT_TEST_SECRET", "secret")
+	t.Setenv(allowedEnvListEnv, "GRPC_CLIENT_TEST_LISTED, OTHER")
+
+	tests := []struct {
+		name    string
+		value   string
+		preview bool
+		want    string
+	}{
+		{name: "prefixed variable", value: "{{$env GRPC_CLIENT_VAR_TOKEN}}", want: "allowed-token"},
+		{name: "listed variable", value: "{{$env GRPC_CLIENT_TEST_LISTED}}", want: "listed"},
+		{name: "variable not allowed", value: "{{$env GRPC_CLIENT_TEST_SECRET}}", want: "{{$env GRPC_CLIENT_TEST_SECRET}}"},
+		{name: "preview keeps placeholder", value: "{{$env GRPC_CLIENT_VAR_TOKEN}}", preview: true, want: "{{$env GRPC_CLIENT_VAR_TOKEN}}"},
+		{name: "preview expands other functions", value: "{{$base64 a}}", preview: true, want: "YQ=="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewVariableResolver()
+			resolver.keepEnvPlaceholders = tt.preview
+			if got := resolver.ResolveString(tt.value); got != tt.want {
+				t.Errorf("ResolveString(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func intBetween(min, max int64) func(string) bool {
+	return func(value string) bool {
+		number, err := strconv.ParseInt(value, 10, 64)
+		return err == nil && number >= min && number <= max
+	}
+}
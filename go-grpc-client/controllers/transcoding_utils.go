@@ -0,0 +1,381 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"grpc-client/models"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// Field numbers of google.api.http (an extension of MethodOptions) and of google.api.HttpRule
+const (
+	httpRuleExtensionNumber = 72295728
+
+	httpRuleGet                = 2
+	httpRulePut                = 3
+	httpRulePost               = 4
+	httpRuleDelete             = 5
+	httpRulePatch              = 6
+	httpRuleBody               = 7
+	httpRuleCustom             = 8
+	httpRuleAdditionalBindings = 11
+	httpRuleResponseBody       = 12
+)
+
+// pathTemplateVariable matches "{field.path}" and "{field.path=pattern}" in HTTP path templates
+var pathTemplateVariable = regexp.MustCompile(`\{([^}=]+)(?:=([^}]*))?\}`)
+
+// getHTTPRules returns the google.api.http annotations declared on a method.
+// The annotation is read from the raw option bytes, so the googleapis
+// descriptors do not need to be linked into the binary.
+func getHTTPRules(methodDesc *desc.MethodDescriptor) []models.HTTPRule {
+	options := methodDesc.GetMethodOptions()
+	if options == nil {
+		return nil
+	}
+
+	raw, err := proto.Marshal(options)
+	if err != nil {
+		return nil
+	}
+
+	var rules []models.HTTPRule
+	for len(raw) > 0 {
+		number, wireType, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return rules
+		}
+		raw = raw[n:]
+
+		if number == httpRuleExtensionNumber && wireType == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(raw)
+			if n < 0 {
+				return rules
+			}
+			if rule, err := parseHTTPRule(value); err == nil {
+				rules = append(rules, rule)
+			}
+			raw = raw[n:]
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(number, wireType, raw)
+		if n < 0 {
+			return rules
+		}
+		raw = raw[n:]
+	}
+
+	return rules
+}
+
+func parseHTTPRule(raw []byte) (models.HTTPRule, error) {
+	var rule models.HTTPRule
+	for len(raw) > 0 {
+		number, wireType, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return rule, protowire.ParseError(n)
+		}
+		raw = raw[n:]
+
+		if wireType != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(number, wireType, raw)
+			if n < 0 {
+				return rule, protowire.ParseError(n)
+			}
+			raw = raw[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(raw)
+		if n < 0 {
+			return rule, protowire.ParseError(n)
+		}
+		raw = raw[n:]
+
+		switch number {
+		case httpRuleGet:
+			rule.Method, rule.Path = "GET", string(value)
+		case httpRulePut:
+			rule.Method, rule.Path = "PUT", string(value)
+		case httpRulePost:
+			rule.Method, rule.Path = "POST", string(value)
+		case httpRuleDelete:
+			rule.Method, rule.Path = "DELETE", string(value)
+		case httpRulePatch:
+			rule.Method, rule.Path = "PATCH", string(value)
+		case httpRuleCustom:
+			kind, path, err := parseCustomHTTPPattern(value)
+			if err != nil {
+				return rule, err
+			}
+			rule.Method, rule.Path = strings.ToUpper(kind), path
+		case httpRuleBody:
+			rule.Body = string(value)
+		case httpRuleResponseBody:
+			rule.ResponseBody = string(value)
+		case httpRuleAdditionalBindings:
+			binding, err := parseHTTPRule(value)
+			if err != nil {
+				return rule, err
+			}
+			rule.AdditionalBindings = append(rule.AdditionalBindings, binding)
+		}
+	}
+	return rule, nil
+}
+
+// parseCustomHTTPPattern parses a google.api.CustomHttpPattern (kind = 1, path = 2)
+func parseCustomHTTPPattern(raw []byte) (string, string, error) {
+	var kind, path string
+	for len(raw) > 0 {
+		number, wireType, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return "", "", protowire.ParseError(n)
+		}
+		raw = raw[n:]
+
+		if wireType != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(number, wireType, raw)
+			if n < 0 {
+				return "", "", protowire.ParseError(n)
+			}
+			raw = raw[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(raw)
+		if n < 0 {
+			return "", "", protowire.ParseError(n)
+		}
+		raw = raw[n:]
+
+		switch number {
+		case 1:
+			kind = string(value)
+		case 2:
+			path = string(value)
+		}
+	}
+	return kind, path, nil
+}
+
+// executeTranscodedCall sends a gRPC request through the REST route of its google.api.http annotation
+func (ce *CallExecutor) executeTranscodedCall(ctx context.Context, grpcRequest models.GrpcRequest, headers http.Header) *models.CallResult {
+	failed := func(format string, args ...interface{}) *models.CallResult {
+		return &models.CallResult{
+			Protocol: models.ProtocolTranscoding,
+			Error:    fmt.Sprintf(format, args...),
+		}
+	}
+
+	conn, err := ce.createConnection(grpcRequest.Host)
+	if err != nil {
+		return failed("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	methodDesc, err := ce.getMethodDescriptor(conn, grpcRequest.Method)
+	if err != nil {
+		return failed("Method descriptor error: %v", err)
+	}
+
+	rules := getHTTPRules(methodDesc)
+	if len(rules) == 0 {
+		return failed("Method %s has no google.api.http annotation", methodDesc.GetFullyQualifiedName())
+	}
+
+	// Normalize the message to proto field names, which is what path templates and body selectors use
+	requestMsg, err := parseDynamicMessage(methodDesc.GetInputType(), grpcRequest.Message)
+	if err != nil {
+		return failed("Failed to parse request message: %v", err)
+	}
+	// Unset fields are left out of the body and query; path variables may still hold a default value such as 0
+	fields, err := transcodedMessageFields(requestMsg, false)
+	if err != nil {
+		return failed("Failed to marshal request message: %v", err)
+	}
+	defaults, err := transcodedMessageFields(requestMsg, true)
+	if err != nil {
+		return failed("Failed to marshal request message: %v", err)
+	}
+
+	restConfig, err := buildTranscodedRESTConfig(rules[0], fields, defaults)
+	if err != nil {
+		return failed("Failed to build HTTP request: %v", err)
+	}
+
+	// grpc-gateway forwards Authorization as is and other metadata with the Grpc-Metadata- prefix
+	md := ce.createMetadata(grpcRequest, headers)
+	for key, values := range md {
+		headerName := key
+		if key != "authorization" {
+			headerName = "Grpc-Metadata-" + key
+		}
+		for _, value := range values {
+			restConfig.Headers = append(restConfig.Headers, models.RequestHeader{Key: headerName, Value: value, Enabled: true})
+		}
+	}
+
+	baseURL := grpcRequest.Host
+	if grpcRequest.Transcoding != nil && grpcRequest.Transcoding.BaseURL != "" {
+		baseURL = grpcRequest.Transcoding.BaseURL
+	}
+
+	log.Printf("Sending %s through transcoded route %s %s", methodDesc.GetFullyQualifiedName(), restConfig.Method, restConfig.URL)
+
+	result := ce.ExecuteRest(ctx, models.RestCallRequest{
+		Host:       baseURL,
+		RESTConfig: restConfig,
+	})
+	result.Protocol = models.ProtocolTranscoding
+	if result.Error == "" && !result.Succeeded() {
+		result.Error = fmt.Sprintf("Transcoded call returned %s", result.Status)
+	}
+	return result
+}
+
+// transcodedMessageFields decodes a message to a JSON object with proto field names,
+// optionally with the fields left at their default values
+func transcodedMessageFields(message *dynamic.Message, emitDefaults bool) (map[string]interface{}, error) {
+	jsonBytes, err := message.MarshalJSONPB(&jsonpb.Marshaler{OrigName: true, EmitDefaults: emitDefaults})
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// buildTranscodedRESTConfig maps message fields onto the path, body and query of an HTTP rule.
+// A path field missing from fields is read from defaults, the message with default values emitted.
+func buildTranscodedRESTConfig(rule models.HTTPRule, fields, defaults map[string]interface{}) (models.RESTConfig, error) {
+	config := models.RESTConfig{Method: rule.Method}
+
+	var expandErr error
+	config.URL = pathTemplateVariable.ReplaceAllStringFunc(rule.Path, func(variable string) string {
+		match := pathTemplateVariable.FindStringSubmatch(variable)
+		fieldPath, pattern := match[1], match[2]
+
+		value, ok := takeFieldValue(fields, fieldPath)
+		if !ok {
+			value, ok = takeFieldValue(defaults, fieldPath)
+		}
+		if !ok || value == nil {
+			expandErr = fmt.Errorf("path field %q is not set", fieldPath)
+			return variable
+		}
+
+		text := formatFieldValue(value)
+		if strings.Contains(pattern, "/") || strings.Contains(pattern, "**") {
+			// Multi-segment variables keep their slashes
+			segments := strings.Split(text, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			return strings.Join(segments, "/")
+		}
+		return url.PathEscape(text)
+	})
+	if expandErr != nil {
+		return config, expandErr
+	}
+
+	switch rule.Body {
+	case "*":
+		config.Body = fields
+		return config, nil
+	case "":
+	default:
+		if value, ok := takeFieldValue(fields, rule.Body); ok {
+			config.Body = value
+		}
+	}
+
+	config.Params = queryParamsFromFields("", fields)
+	return config, nil
+}
+
+// takeFieldValue returns the value at a dotted field path and removes it from the message
+func takeFieldValue(fields map[string]interface{}, fieldPath string) (interface{}, bool) {
+	parts := strings.Split(fieldPath, ".")
+	current := fields
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+
+	last := parts[len(parts)-1]
+	value, ok := current[last]
+	if ok {
+		delete(current, last)
+	}
+	return value, ok
+}
+
+// queryParamsFromFields flattens the remaining message fields into query parameters
+func queryParamsFromFields(prefix string, fields map[string]interface{}) []models.RequestHeader {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var params []models.RequestHeader
+	for _, key := range keys {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		switch value := fields[key].(type) {
+		case map[string]interface{}:
+			params = append(params, queryParamsFromFields(name, value)...)
+		case []interface{}:
+			for _, item := range value {
+				if _, isObject := item.(map[string]interface{}); !isObject {
+					params = append(params, models.RequestHeader{Key: name, Value: formatFieldValue(item), Enabled: true})
+				}
+			}
+		default:
+			params = append(params, models.RequestHeader{Key: name, Value: formatFieldValue(value), Enabled: true})
+		}
+	}
+	return params
+}
+
+func formatFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	default:
+		jsonBytes, _ := json.Marshal(v)
+		return string(jsonBytes)
+	}
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"encoding/json"
+	"grpc-client/models"
+	"reflect"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+)
+
+func TestBuildTranscodedRESTConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       models.HTTPRule
+		message    string
+		defaults   map[string]interface{}
+		wantURL    string
+		wantBody   interface{}
+		wantParams []models.RequestHeader
+		wantErr    bool
+	}{
+		{
+			name:    "simple variable",
+			rule:    models.HTTPRule{Method: "GET", Path: "/v1/items/{id}"},
+			message: `{"id": "42"}`,
+			wantURL: "/v1/items/42",
+		},
+		{
+			name:    "variable is escaped",
+			rule:    models.HTTPRule{Method: "GET", Path: "/v1/items/{id}"},
+			message: `{"id": "a b/c"}`,
+			wantURL: "/v1/items/a%20b%2Fc",
+		},
+		{
+			name:    "multi-segment variable keeps slashes",
+			rule:    models.HTTPRule{Method: "GET", Path: "/v1/{name=shelves/*/books/*}"},
+			message: `{"name": "shelves/1/books/a b"}`,
+			wantURL: "/v1/shelves/1/books/a%20b",
+		},
+		{
+			name:    "nested field leaves no parameters",
+			rule:    models.HTTPRule{Method: "GET", Path: "/v1/books/{book.id}"},
+			message: `{"book": {"id": 7}}`,
+			wantURL: "/v1/books/7",
+		},
+		{
+			name:       "remaining fields become query parameters",
+			rule:       models.HTTPRule{Method: "GET", Path: "/v1/items/{id}"},
+			message:    `{"id": "1", "view": "FULL", "filter": {"tag": "red"}, "ids": [1, 2], "verbose": true}`,
+			wantURL:    "/v1/items/1",
+			wantParams: []models.RequestHeader{{Key: "filter.tag", Value: "red", Enabled: true}, {Key: "ids", Value: "1", Enabled: true}, {Key: "ids", Value: "2", Enabled: true}, {Key: "verbose", Value: "true", Enabled: true}, {Key: "view", Value: "FULL", Enabled: true}},
+		},
+		{
+			name:       "body field",
+			rule:       models.HTTPRule{Method: "POST", Path: "/v1/{parent=shelves/*}/items", Body: "item"},
+			message:    `{"parent": "shelves/3", "item": {"name": "a"}, "requestId": "r1"}`,
+			wantURL:    "/v1/shelves/3/items",
+			wantBody:   map[string]interface{}{"name": "a"},
+			wantParams: []models.RequestHeader{{Key: "requestId", Value: "r1", Enabled: true}},
+		},
+		{
+			name:     "whole message as body",
+			rule:     models.HTTPRule{Method: "PATCH", Path: "/v1/items/{id}", Body: "*"},
+			message:  `{"id": "1", "name": "a"}`,
+			wantURL:  "/v1/items/1",
+			wantBody: map[string]interface{}{"name": "a"},
+		},
+		{
+			name:     "path field at its default value",
+			rule:     models.HTTPRule{Method: "GET", Path: "/v1/items/{id}"},
+			message:  `{}`,
+			defaults: map[string]interface{}{"id": "0"},
+			wantURL:  "/v1/items/0",
+		},
+		{
+			name:     "unset message in path",
+			rule:     models.HTTPRule{Method: "GET", Path: "/v1/books/{book.id}"},
+			message:  `{}`,
+			defaults: map[string]interface{}{"book": nil},
+			wantErr:  true,
+		},
+		{
+			name:    "missing path field",
+			rule:    models.HTTPRule{Method: "GET", Path: "/v1/items/{id}"},
+			message: `{}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.message), &fields); err != nil {
+				t.Fatal(err)
+			}
+			config, err := buildTranscodedRESTConfig(tt.rule, fields, tt.defaults)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildTranscodedRESTConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if config.Method != tt.rule.Method {
+				t.Errorf("method = %q, want %q", config.Method, tt.rule.Method)
+			}
+			if config.URL != tt.wantURL {
+				t.Errorf("URL = %q, want %q", config.URL, tt.wantURL)
+			}
+			if !reflect.DeepEqual(config.Body, tt.wantBody) {
+				t.Errorf("body = %#v, want %#v", config.Body, tt.wantBody)
+			}
+			if !reflect.DeepEqual(config.Params, tt.wantParams) {
+				t.Errorf("params = %+v, want %+v", config.Params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestFormatFieldValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "string", value: "text", want: "text"},
+		{name: "integral float", value: float64(42), want: "42"},
+		{name: "large float", value: float64(1e21), want: "1000000000000000000000"},
+		{name: "fraction", value: 1.25, want: "1.25"},
+		{name: "json number keeps digits", value: json.Number("9007199254740993"), want: "9007199254740993"},
+		{name: "boolean", value: false, want: "false"},
+		{name: "nil", value: nil, want: ""},
+		{name: "object", value: map[string]interface{}{"a": "b"}, want: `{"a":"b"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatFieldValue(tt.value); got != tt.want {
+				t.Errorf("formatFieldValue(%#v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscodedPathFieldsAtDefaultValues(t *testing.T) {
+	fileDesc := parseTestProto(t, `
+		syntax = "proto3";
+		package demo;
+		enum Kind {
+			KIND_UNSPECIFIED = 0;
+			KIND_BOOK = 1;
+		}
+		message Request {
+			int64 id = 1;
+			string name = 2;
+			bool archived = 3;
+			Kind kind = 4;
+			string filter = 5;
+		}`)
+	msgDesc := fileDesc.FindMessage("demo.Request")
+
+	tests := []struct {
+		name       string
+		path       string
+		message    map[string]interface{}
+		wantURL    string
+		wantParams []models.RequestHeader
+	}{
+		{name: "zero integer", path: "/v1/items/{id}", message: map[string]interface{}{"id": 0}, wantURL: "/v1/items/0"},
+		{name: "false boolean", path: "/v1/items/{archived}", message: map[string]interface{}{}, wantURL: "/v1/items/false"},
+		{name: "first enum value", path: "/v1/kinds/{kind}", message: map[string]interface{}{}, wantURL: "/v1/kinds/KIND_UNSPECIFIED"},
+		{name: "empty string", path: "/v1/names/{name}/items", message: map[string]interface{}{}, wantURL: "/v1/names//items"},
+		{
+			name:       "defaults stay out of the query",
+			path:       "/v1/items/{id}",
+			message:    map[string]interface{}{"filter": "red"},
+			wantURL:    "/v1/items/0",
+			wantParams: []models.RequestHeader{{Key: "filter", Value: "red", Enabled: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := parseDynamicMessage(msgDesc, tt.message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields, err := transcodedMessageFields(message, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defaults, err := transcodedMessageFields(message, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := buildTranscodedRESTConfig(models.HTTPRule{Method: "GET", Path: tt.path}, fields, defaults)
+			if err != nil {
+				t.Fatalf("buildTranscodedRESTConfig() error = %v", err)
+			}
+			if config.URL != tt.wantURL {
+				t.Errorf("URL = %q, want %q", config.URL, tt.wantURL)
+			}
+			if !reflect.DeepEqual(config.Params, tt.wantParams) {
+				t.Errorf("params = %+v, want %+v", config.Params, tt.wantParams)
+			}
+		})
+	}
+}
+
+// parseTestProto compiles the source of a .proto file for tests
+func parseTestProto(t *testing.T, source string) *desc.FileDescriptor {
+	t.Helper()
+	parser := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(map[string]string{"test.proto": source}),
+	}
+	files, err := parser.ParseFiles("test.proto")
+	if err != nil {
+		t.Fatalf("parsing test proto: %v", err)
+	}
+	return files[0]
+}
@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"encoding/json"
+	"grpc-client/models"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxResolvePasses bounds how often nested placeholders (variables referring to other variables) are expanded
+const maxResolvePasses = 5
+
+// variablePlaceholder matches "{{name}}" placeholders; the innermost placeholder matches first
+var variablePlaceholder = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+// wholeValuePlaceholder matches a string that consists of a single placeholder
+var wholeValuePlaceholder = regexp.MustCompile(`^\s*\{\{\s*([^{}]+?)\s*\}\}\s*$`)
+
+// Names of the variable scopes, from highest to lowest precedence
+const (
+	scopeRun         = "run"
+	scopeRequest     = "request"
+	scopeEnvironment = "environment"
+	scopeCollection  = "collection"
+	scopeWorkspace   = "workspace"
+)
+
+// VariableResolver expands {{name}} placeholders using layered variable scopes
+type VariableResolver struct {
+	scopes     []map[string]string // Highest precedence first
+	scopeNames []string
+	undefined  map[string]bool
+
+	// keepEnvPlaceholders leaves {{$env NAME}} unexpanded, so previews never show server environment values
+	keepEnvPlaceholders bool
+}
+
+// NewVariableResolver creates a resolver; earlier scopes take precedence over later ones
+func NewVariableResolver(scopes ...map[string]string) *VariableResolver {
+	return &VariableResolver{
+		scopes:     scopes,
+		scopeNames: make([]string, len(scopes)),
+		undefined:  make(map[string]bool),
+	}
+}
+
+// addScope adds a named scope with lower precedence than the existing ones
+func (vr *VariableResolver) addScope(name string, values map[string]string) {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	vr.scopes = append(vr.scopes, values)
+	vr.scopeNames = append(vr.scopeNames, name)
+}
+
+// pushScope adds a named scope that takes precedence over the existing ones
+func (vr *VariableResolver) pushScope(name string, values map[string]string) {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	vr.scopes = append([]map[string]string{values}, vr.scopes...)
+	vr.scopeNames = append([]string{name}, vr.scopeNames...)
+}
+
+// Scope returns the variables of a named scope, or nil if the resolver has no such scope.
+// The map is shared with the resolver, so changes apply to later lookups.
+func (vr *VariableResolver) Scope(name string) map[string]string {
+	for i, scopeName := range vr.scopeNames {
+		if scopeName == name {
+			return vr.scopes[i]
+		}
+	}
+	return nil
+}
+
+// Lookup returns the value of a variable from the first scope that defines it
+func (vr *VariableResolver) Lookup(name string) (string, bool) {
+	for _, scope := range vr.scopes {
+		if value, ok := scope[name]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Undefined returns the names of placeholders that could not be resolved, sorted
+func (vr *VariableResolver) Undefined() []string {
+	names := make([]string, 0, len(vr.undefined))
+	for name := range vr.undefined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveString expands all placeholders in a string. Placeholders starting with "$" call a
+// built-in template function. Unknown placeholders are left untouched.
+func (vr *VariableResolver) ResolveString(value string) string {
+	for pass := 0; pass < maxResolvePasses && strings.Contains(value, "{{"); pass++ {
+		changed := false
+		value = variablePlaceholder.ReplaceAllStringFunc(value, func(placeholder string) string {
+			name := variablePlaceholder.FindStringSubmatch(placeholder)[1]
+			if strings.HasPrefix(name, "$") {
+				if vr.keepEnvPlaceholders && isEnvTemplateFunction(name) {
+					return placeholder
+				}
+				generated, err := evaluateTemplateFunction(name)
+				if err != nil {
+					log.Printf("Warning: template function %s failed: %v", name, err)
+					vr.undefined[name] = true
+					return placeholder
+				}
+				changed = true
+				return generated
+			}
+			resolved, ok := vr.Lookup(name)
+			if !ok {
+				vr.undefined[name] = true
+				return placeholder
+			}
+			changed = true
+			return resolved
+		})
+		if !changed {
+			break
+		}
+	}
+	return value
+}
+
+// ResolveValue expands placeholders in a decoded JSON value. A string that consists of a
+// single placeholder is replaced by the typed value when the variable holds a JSON number,
+// boolean, null, object or array; all other strings are expanded as text.
+func (vr *VariableResolver) ResolveValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if match := wholeValuePlaceholder.FindStringSubmatch(v); match != nil {
+			resolved := vr.ResolveString(v)
+			if resolved != v {
+				if typed, ok := parseTypedValue(resolved); ok {
+					return typed
+				}
+			}
+			return resolved
+		}
+		return vr.ResolveString(v)
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved[vr.ResolveString(key)] = vr.ResolveValue(item)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved[i] = vr.ResolveValue(item)
+		}
+		return resolved
+	default:
+		return value
+	}
+}
+
+// ResolveStringMap expands placeholders in the keys and values of a string map
+func (vr *VariableResolver) ResolveStringMap(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	resolved := make(map[string]string, len(values))
+	for key, value := range values {
+		resolved[vr.ResolveString(key)] = vr.ResolveString(value)
+	}
+	return resolved
+}
+
+// ResolveHeaders expands placeholders in request headers, metadata or query parameters
+func (vr *VariableResolver) ResolveHeaders(headers []models.RequestHeader) []models.RequestHeader {
+	if headers == nil {
+		return nil
+	}
+	resolved := make([]models.RequestHeader, len(headers))
+	for i, header := range headers {
+		resolved[i] = models.RequestHeader{
+			Key:     vr.ResolveString(header.Key),
+			Value:   vr.ResolveString(header.Value),
+			Enabled: header.Enabled,
+		}
+	}
+	return resolved
+}
+
+// ResolveAuth expands placeholders in an auth configuration
+func (vr *VariableResolver) ResolveAuth(auth *models.RequestAuth) *models.RequestAuth {
+	if auth == nil {
+		return nil
+	}
+	return &models.RequestAuth{
+		Type:   auth.Type,
+		Config: vr.ResolveStringMap(auth.Config),
+	}
+}
+
+// ResolveGrpcRequest expands placeholders in the host, method, message, metadata and auth of a gRPC request
+func (vr *VariableResolver) ResolveGrpcRequest(grpcRequest models.GrpcRequest) models.GrpcRequest {
+	resolved := grpcRequest
+	resolved.Host = vr.ResolveString(grpcRequest.Host)
+	resolved.Method = vr.ResolveString(grpcRequest.Method)
+	resolved.Message = vr.ResolveValue(grpcRequest.Message)
+	resolved.MetaData = vr.ResolveStringMap(grpcRequest.MetaData)
+	resolved.Auth = vr.ResolveAuth(grpcRequest.Auth)
+	if grpcRequest.Transcoding != nil {
+		resolved.Transcoding = &models.TranscodingOptions{BaseURL: vr.ResolveString(grpcRequest.Transcoding.BaseURL)}
+	}
+	return resolved
+}
+
+// ResolveRestRequest expands placeholders in the host, URL, headers, params, body and auth of a REST request
+func (vr *VariableResolver) ResolveRestRequest(restRequest models.RestCallRequest) models.RestCallRequest {
+	resolved := restRequest
+	resolved.Host = vr.ResolveString(restRequest.Host)
+	resolved.RESTConfig.Method = vr.ResolveString(restRequest.RESTConfig.Method)
+	resolved.RESTConfig.URL = vr.ResolveString(restRequest.RESTConfig.URL)
+	resolved.RESTConfig.Headers = vr.ResolveHeaders(restRequest.RESTConfig.Headers)
+	resolved.RESTConfig.Params = vr.ResolveHeaders(restRequest.RESTConfig.Params)
+	resolved.RESTConfig.Body = vr.ResolveValue(restRequest.RESTConfig.Body)
+	resolved.Auth = vr.ResolveAuth(restRequest.Auth)
+	return resolved
+}
+
+// parseTypedValue parses a variable value as a JSON number, boolean, null, object or array.
+// Numbers are kept as json.Number, so 64-bit IDs keep all their digits.
+func parseTypedValue(value string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" || strings.HasPrefix(trimmed, `"`) {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var typed interface{}
+	if err := decoder.Decode(&typed); err != nil {
+		return nil, false
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, false
+	}
+	return typed, true
+}
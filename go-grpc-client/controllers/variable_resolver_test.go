@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestVariableResolverScopePrecedence(t *testing.T) {
+	resolver := NewVariableResolver()
+	resolver.addScope(scopeEnvironment, map[string]string{"host": "env-host", "port": "8080", "region": "eu"})
+	resolver.addScope(scopeCollection, map[string]string{"host": "collection-host", "user": "collection-user", "region": "us"})
+	resolver.addScope(scopeWorkspace, map[string]string{"user": "workspace-user", "team": "core"})
+	resolver.pushScope(scopeRequest, map[string]string{"port": "9090"})
+	resolver.pushScope(scopeRun, map[string]string{"region": "ap"})
+
+	tests := []struct {
+		name      string
+		variable  string
+		want      string
+		wantFound bool
+	}{
+		{name: "run overrides all", variable: "region", want: "ap", wantFound: true},
+		{name: "request overrides environment", variable: "port", want: "9090", wantFound: true},
+		{name: "environment overrides collection", variable: "host", want: "env-host", wantFound: true},
+		{name: "collection overrides workspace", variable: "user", want: "collection-user", wantFound: true},
+		{name: "workspace", variable: "team", want: "core", wantFound: true},
+		{name: "undefined", variable: "missing", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := resolver.Lookup(tt.variable)
+			if found != tt.wantFound || got != tt.want {
+				t.Errorf("Lookup(%q) = %q, %v, want %q, %v", tt.variable, got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+
+	resolver.Scope(scopeRun)["team"] = "platform"
+	if got, _ := resolver.Lookup("team"); got != "platform" {
+		t.Errorf("Lookup(team) after changing the run scope = %q, want platform", got)
+	}
+	if resolver.Scope("unknown") != nil {
+		t.Errorf("Scope(unknown) should be nil")
+	}
+}
+
+func TestVariableResolverResolveString(t *testing.T) {
+	tests := []struct {
+		name          string
+		variables     map[string]string
+		value         string
+		want          string
+		wantUndefined []string
+	}{
+		{name: "no placeholders", value: "plain", want: "plain", wantUndefined: []string{}},
+		{name: "placeholder", variables: map[string]string{"host": "localhost"}, value: "{{host}}:50051", want: "localhost:50051", wantUndefined: []string{}},
+		{name: "spaces inside braces", variables: map[string]string{"id": "7"}, value: "{{ id }}", want: "7", wantUndefined: []string{}},
+		{name: "nested variables", variables: map[string]string{"url": "{{scheme}}://{{host}}", "scheme": "https", "host": "api"}, value: "{{url}}/v1", want: "https://api/v1", wantUndefined: []string{}},
+		{name: "undefined kept", value: "{{missing}}/{{other}}", want: "{{missing}}/{{other}}", wantUndefined: []string{"missing", "other"}},
+		{name: "self reference stops", variables: map[string]string{"loop": "{{loop}}"}, value: "{{loop}}", want: "{{loop}}", wantUndefined: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewVariableResolver(tt.variables)
+			if got := resolver.ResolveString(tt.value); got != tt.want {
+				t.Errorf("ResolveString(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+			if got := resolver.Undefined(); !reflect.DeepEqual(got, tt.wantUndefined) {
+				t.Errorf("Undefined() = %q, want %q", got, tt.wantUndefined)
+			}
+		})
+	}
+}
+
+func TestVariableResolverResolveValue(t *testing.T) {
+	resolver := NewVariableResolver(map[string]string{
+		"id":     "9007199254740993",
+		"ratio":  "0.5",
+		"active": "true",
+		"none":   "null",
+		"tags":   `["a", "b"]`,
+		"name":   "widget",
+		"quoted": `"42"`,
+	})
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{name: "large integer keeps its digits", value: "{{id}}", want: json.Number("9007199254740993")},
+		{name: "fraction", value: "{{ratio}}", want: json.Number("0.5")},
+		{name: "boolean", value: "{{active}}", want: true},
+		{name: "null", value: "{{none}}", want: nil},
+		{name: "array", value: "{{tags}}", want: []interface{}{"a", "b"}},
+		{name: "text stays a string", value: "{{name}}", want: "widget"},
+		{name: "quoted number stays a string", value: "{{quoted}}", want: `"42"`},
+		{name: "placeholder within text", value: "id-{{id}}", want: "id-9007199254740993"},
+		{name: "undefined stays a string", value: "{{missing}}", want: "{{missing}}"},
+		{
+			name:  "nested values and keys",
+			value: map[string]interface{}{"{{name}}": []interface{}{"{{active}}", float64(1)}},
+			want:  map[string]interface{}{"widget": []interface{}{true, float64(1)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolver.ResolveValue(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveValue(%#v) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTypedValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   interface{}
+		wantOk bool
+	}{
+		{name: "integer", value: "42", want: json.Number("42"), wantOk: true},
+		{name: "beyond float64 precision", value: "12345678901234567890", want: json.Number("12345678901234567890"), wantOk: true},
+		{name: "surrounding spaces", value: " 1.5 ", want: json.Number("1.5"), wantOk: true},
+		{name: "boolean", value: "false", want: false, wantOk: true},
+		{name: "object", value: `{"a": 1}`, want: map[string]interface{}{"a": json.Number("1")}, wantOk: true},
+		{name: "empty", value: "", wantOk: false},
+		{name: "quoted string", value: `"text"`, wantOk: false},
+		{name: "plain text", value: "text", wantOk: false},
+		{name: "trailing content", value: "1 2", wantOk: false},
+		{name: "invalid JSON", value: "{", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseTypedValue(tt.value)
+			if ok != tt.wantOk {
+				t.Fatalf("parseTypedValue(%q) ok = %v, want %v", tt.value, ok, tt.wantOk)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTypedValue(%q) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"fmt"
+	"grpc-client/constants"
+	"grpc-client/models"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WorkflowController manages and runs workflows stored in the workspace
+type WorkflowController struct {
+	runner   *RequestRunner
+	store    *WorkspaceStore
+	notifier *NotificationDispatcher
+}
+
+func NewWorkflowController(runner *RequestRunner, store *WorkspaceStore, notifier *NotificationDispatcher) *WorkflowController {
+	return &WorkflowController{
+		runner:   runner,
+		store:    store,
+		notifier: notifier,
+	}
+}
+
+// ListWorkflows returns all workflows of the workspace
+func (wfc *WorkflowController) ListWorkflows(c *gin.Context) {
+	workspace, err := wfc.store.Load()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to load workspace"})
+		return
+	}
+
+	workflows := workspace.Workflows
+	if workflows == nil {
+		workflows = []models.Workflow{}
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Workflows loaded successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    workflows,
+	})
+}
+
+// CreateWorkflow validates and stores a new workflow
+func (wfc *WorkflowController) CreateWorkflow(c *gin.Context) {
+	var workflow models.Workflow
+	if err := c.ShouldBindJSON(&workflow); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if workflow.Name == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Workflow name is required"})
+		return
+	}
+	if err := validateWorkflow(&workflow); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if workflow.ID == "" {
+		workflow.ID = uuid.New().String()
+	}
+	workflow.CreatedAt = time.Now()
+	workflow.UpdatedAt = time.Now()
+
+	err := wfc.store.Update(func(workspace *models.Workspace) error {
+		for _, existing := range workspace.Workflows {
+			if existing.ID == workflow.ID {
+				return fmt.Errorf("workflow %q already exists", workflow.ID)
+			}
+		}
+		workspace.Workflows = append(workspace.Workflows, workflow)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error saving workflow: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: fmt.Sprintf("Failed to create workflow: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Workflow created successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    workflow,
+	})
+}
+
+// UpdateWorkflow replaces the definition of an existing workflow
+func (wfc *WorkflowController) UpdateWorkflow(c *gin.Context) {
+	workflowID := c.Param("id")
+
+	var workflow models.Workflow
+	if err := c.ShouldBindJSON(&workflow); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if err := validateWorkflow(&workflow); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	found := false
+	err := wfc.store.Update(func(workspace *models.Workspace) error {
+		for i := range workspace.Workflows {
+			if workspace.Workflows[i].ID == workflowID {
+				workflow.ID = workflowID
+				workflow.CreatedAt = workspace.Workflows[i].CreatedAt
+				workflow.UpdatedAt = time.Now()
+				if workflow.Name == "" {
+					workflow.Name = workspace.Workflows[i].Name
+				}
+				workspace.Workflows[i] = workflow
+				found = true
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error saving workflow: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update workflow"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Workflow not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Workflow updated successfully",
+		Status:  constants.ResponseStatusSuccess,
+		Data:    workflow,
+	})
+}
+
+// DeleteWorkflow removes a workflow
+func (wfc *WorkflowController) DeleteWorkflow(c *gin.Context) {
+	workflowID := c.Param("id")
+
+	found := false
+	err := wfc.store.Update(func(workspace *models.Workspace) error {
+		for i := range workspace.Workflows {
+			if workspace.Workflows[i].ID == workflowID {
+				workspace.Workflows = append(workspace.Workflows[:i], workspace.Workflows[i+1:]...)
+				found = true
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error saving workspace: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to delete workflow"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Workflow not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Workflow deleted successfully",
+		Status:  constants.ResponseStatusSuccess,
+	})
+}
+
+// RunWorkflow runs a stored workflow and returns the per-step trace. Failed runs are sent to
+// the notifiers.
+func (wfc *WorkflowController) RunWorkflow(c *gin.Context) {
+	var options models.RunRequestOptions
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&options); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+			return
+		}
+	}
+
+	workflow, err := wfc.runner.FindWorkflow(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := wfc.runner.RunWorkflow(c.Request.Context(), workflow, options, nil)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	wfc.notifier.notifyWorkflowRun(c.Request.Context(), result, options.EnvironmentID)
+
+	c.JSON(http.StatusOK, result)
+}
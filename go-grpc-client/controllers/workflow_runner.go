@@ -0,0 +1,422 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"grpc-client/models"
+	"log"
+	"sync"
+	"time"
+)
+
+const defaultWorkflowItemVariable = "item"
+
+// FindWorkflow finds a workflow of the workspace by ID
+func (rr *RequestRunner) FindWorkflow(workflowID string) (*models.Workflow, error) {
+	workspace, err := rr.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range workspace.Workflows {
+		if workspace.Workflows[i].ID == workflowID {
+			return &workspace.Workflows[i], nil
+		}
+	}
+	return nil, fmt.Errorf("workflow %q not found", workflowID)
+}
+
+// validateWorkflow checks that step IDs are unique, dependencies exist and there are no cycles
+func validateWorkflow(workflow *models.Workflow) error {
+	if len(workflow.Steps) == 0 {
+		return fmt.Errorf("workflow has no steps")
+	}
+
+	steps := make(map[string]*models.WorkflowStep, len(workflow.Steps))
+	for i := range workflow.Steps {
+		step := &workflow.Steps[i]
+		if step.ID == "" {
+			return fmt.Errorf("step %d has no ID", i+1)
+		}
+		if step.RequestID == "" {
+			return fmt.Errorf("step %q has no request", step.ID)
+		}
+		if _, exists := steps[step.ID]; exists {
+			return fmt.Errorf("duplicate step ID %q", step.ID)
+		}
+		steps[step.ID] = step
+	}
+
+	// Kahn's algorithm: every step must become ready once its dependencies are done
+	pending := make(map[string]int, len(steps))
+	dependents := make(map[string][]string)
+	for _, step := range workflow.Steps {
+		for _, dependency := range step.DependsOn {
+			if _, exists := steps[dependency]; !exists {
+				return fmt.Errorf("step %q depends on unknown step %q", step.ID, dependency)
+			}
+			dependents[dependency] = append(dependents[dependency], step.ID)
+		}
+		pending[step.ID] = len(step.DependsOn)
+	}
+
+	var ready []string
+	for id, count := range pending {
+		if count == 0 {
+			ready = append(ready, id)
+		}
+	}
+	visited := 0
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+		visited++
+		for _, dependent := range dependents[id] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	if visited != len(steps) {
+		return fmt.Errorf("workflow steps have a dependency cycle")
+	}
+	return nil
+}
+
+// workflowRun is the shared state of a running workflow
+type workflowRun struct {
+	steps        map[string]*models.WorkflowStep
+	mu           sync.Mutex
+	runVariables map[string]string
+	stepContexts map[string]interface{} // Step outcomes exposed to conditions and forEach as "steps"
+	states       map[string]models.WorkflowStepState
+	trace        []models.WorkflowTraceEntry
+	progress     func(entry models.WorkflowTraceEntry)
+}
+
+func (wr *workflowRun) record(entry models.WorkflowTraceEntry) {
+	wr.mu.Lock()
+	wr.trace = append(wr.trace, entry)
+	wr.mu.Unlock()
+
+	if wr.progress != nil {
+		wr.progress(entry)
+	}
+}
+
+// expressionContext returns a snapshot of the values available to conditions and forEach paths
+func (wr *workflowRun) expressionContext() map[string]interface{} {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	steps := make(map[string]interface{}, len(wr.stepContexts))
+	for id, value := range wr.stepContexts {
+		steps[id] = value
+	}
+	vars := make(map[string]interface{}, len(wr.runVariables))
+	for name, value := range wr.runVariables {
+		vars[name] = value
+	}
+	return map[string]interface{}{"steps": steps, "vars": vars}
+}
+
+// RunWorkflow executes a workflow. Steps start as soon as their dependencies have finished,
+// so independent branches run in parallel. A failed step skips its dependents unless it has
+// continueOnFailure. progress, if set, is called for each trace entry.
+func (rr *RequestRunner) RunWorkflow(ctx context.Context, workflow *models.Workflow, options models.RunRequestOptions, progress func(entry models.WorkflowTraceEntry)) (*models.WorkflowRunResult, error) {
+	if err := validateWorkflow(workflow); err != nil {
+		return nil, err
+	}
+
+	environmentID := options.EnvironmentID
+	if environmentID == "" {
+		environmentID = workflow.EnvironmentID
+	}
+
+	run := &workflowRun{
+		steps:        make(map[string]*models.WorkflowStep, len(workflow.Steps)),
+		runVariables: make(map[string]string),
+		stepContexts: make(map[string]interface{}),
+		states:       make(map[string]models.WorkflowStepState),
+		trace:        []models.WorkflowTraceEntry{},
+		progress:     progress,
+	}
+	for name, value := range workflow.Variables {
+		run.runVariables[name] = value
+	}
+	for name, value := range options.Variables {
+		run.runVariables[name] = value
+	}
+
+	result := &models.WorkflowRunResult{
+		WorkflowID:   workflow.ID,
+		WorkflowName: workflow.Name,
+		StartedAt:    time.Now(),
+	}
+
+	log.Printf("Running workflow %s with %d steps", workflow.Name, len(workflow.Steps))
+
+	pending := make(map[string]int, len(workflow.Steps))
+	dependents := make(map[string][]*models.WorkflowStep)
+	for i := range workflow.Steps {
+		step := &workflow.Steps[i]
+		run.steps[step.ID] = step
+		pending[step.ID] = len(step.DependsOn)
+		for _, dependency := range step.DependsOn {
+			dependents[dependency] = append(dependents[dependency], step)
+		}
+	}
+
+	finished := make(chan string)
+	start := func(step *models.WorkflowStep) {
+		go func() {
+			state := rr.runWorkflowStep(ctx, step, environmentID, run)
+			run.mu.Lock()
+			run.states[step.ID] = state
+			run.mu.Unlock()
+			finished <- step.ID
+		}()
+	}
+
+	for i := range workflow.Steps {
+		if pending[workflow.Steps[i].ID] == 0 {
+			start(&workflow.Steps[i])
+		}
+	}
+	for completed := 0; completed < len(workflow.Steps); completed++ {
+		id := <-finished
+		for _, dependent := range dependents[id] {
+			pending[dependent.ID]--
+			if pending[dependent.ID] == 0 {
+				start(dependent)
+			}
+		}
+	}
+
+	result.Passed = true
+	for _, step := range workflow.Steps {
+		state := run.states[step.ID]
+		result.Steps = append(result.Steps, state)
+		if state.State == models.WorkflowStepFailed {
+			result.Passed = false
+		}
+		if state.State == models.WorkflowStepSkipped && state.Reason != conditionNotMetReason {
+			result.Passed = false
+		}
+	}
+	result.Trace = run.trace
+	result.Variables = run.runVariables
+	result.DurationMs = time.Since(result.StartedAt).Milliseconds()
+
+	log.Printf("Workflow %s finished in %dms (passed: %t)", workflow.Name, result.DurationMs, result.Passed)
+	return result, nil
+}
+
+const conditionNotMetReason = "condition not met"
+
+// runWorkflowStep runs one step (once, or once per forEach item) and returns its final state.
+// Each execution works on a snapshot of the run variables. When it is done, only the variables
+// it set, changed or unset are written back, so parallel branches do not undo each other's
+// changes; when two branches change the same variable, the branch that finishes last wins.
+func (rr *RequestRunner) runWorkflowStep(ctx context.Context, step *models.WorkflowStep, environmentID string, run *workflowRun) models.WorkflowStepState {
+	state := models.WorkflowStepState{StepID: step.ID}
+	skip := func(reason string) models.WorkflowStepState {
+		state.State = models.WorkflowStepSkipped
+		state.Reason = reason
+		run.record(models.WorkflowTraceEntry{
+			StepID:    step.ID,
+			RequestID: step.RequestID,
+			State:     state.State,
+			Reason:    reason,
+			StartedAt: time.Now(),
+		})
+		run.mu.Lock()
+		run.stepContexts[step.ID] = map[string]interface{}{"state": state.State, "passed": false}
+		run.mu.Unlock()
+		return state
+	}
+
+	if ctx.Err() != nil {
+		return skip("run cancelled")
+	}
+
+	// A dependency that did not pass blocks this step, unless it failed with continueOnFailure
+	run.mu.Lock()
+	for _, dependency := range step.DependsOn {
+		dependencyState := run.states[dependency]
+		if dependencyState.State == models.WorkflowStepPassed ||
+			(dependencyState.State == models.WorkflowStepFailed && run.steps[dependency].ContinueOnFailure) {
+			continue
+		}
+		run.mu.Unlock()
+		return skip(fmt.Sprintf("dependency %q %s", dependency, dependencyState.State))
+	}
+	run.mu.Unlock()
+
+	if step.Condition != "" {
+		ok, err := evaluateCondition(step.Condition, run.expressionContext())
+		if err != nil {
+			return rr.failWorkflowStep(step, state, run, fmt.Sprintf("condition error: %v", err))
+		}
+		if !ok {
+			return skip(conditionNotMetReason)
+		}
+	}
+
+	request, collection, err := rr.FindRequest(step.RequestID)
+	if err != nil {
+		return rr.failWorkflowStep(step, state, run, err.Error())
+	}
+
+	// Without forEach the step runs once with no item
+	items := []interface{}{nil}
+	if step.ForEach != "" {
+		value, found, err := evaluateJSONPath(run.expressionContext(), step.ForEach)
+		if err != nil {
+			return rr.failWorkflowStep(step, state, run, fmt.Sprintf("forEach error: %v", err))
+		}
+		list, isList := value.([]interface{})
+		if found && !isList {
+			list = []interface{}{value}
+		}
+		if len(list) == 0 {
+			return skip("forEach matched no items")
+		}
+		items = list
+	}
+
+	itemVariable := step.ItemVariable
+	if itemVariable == "" {
+		itemVariable = defaultWorkflowItemVariable
+	}
+
+	passed := true
+	var bodies []interface{}
+	var lastRun *models.RequestRunResult
+	for index, item := range items {
+		if ctx.Err() != nil {
+			passed = false
+			state.Reason = "run cancelled"
+			break
+		}
+
+		// Each execution works on a copy of the run variables, so parallel branches do not
+		// share a map; snapshot is kept to find what the execution changed
+		run.mu.Lock()
+		snapshot := make(map[string]string, len(run.runVariables))
+		variables := make(map[string]string, len(run.runVariables)+2)
+		for name, value := range run.runVariables {
+			snapshot[name] = value
+			variables[name] = value
+		}
+		run.mu.Unlock()
+
+		entry := models.WorkflowTraceEntry{
+			StepID:    step.ID,
+			RequestID: step.RequestID,
+			StartedAt: time.Now(),
+		}
+		if step.ForEach != "" {
+			itemIndex := index
+			entry.ItemIndex = &itemIndex
+			entry.Item = item
+			variables[itemVariable] = formatFieldValue(item)
+			variables[itemVariable+"Index"] = fmt.Sprint(index)
+		}
+
+		requestRun, err := rr.RunRequest(ctx, request, collection, models.RunRequestOptions{
+			EnvironmentID: environmentID,
+			Variables:     variables,
+		})
+		entry.DurationMs = time.Since(entry.StartedAt).Milliseconds()
+
+		if err != nil {
+			entry.State = models.WorkflowStepFailed
+			entry.Reason = err.Error()
+			run.record(entry)
+			passed = false
+			state.Reason = err.Error()
+			break
+		}
+
+		// Merge back the changes, leaving out the loop variables
+		if step.ForEach != "" {
+			delete(variables, itemVariable)
+			delete(variables, itemVariable+"Index")
+			delete(snapshot, itemVariable)
+			delete(snapshot, itemVariable+"Index")
+		}
+		run.mu.Lock()
+		mergeVariableChanges(run.runVariables, snapshot, variables)
+		run.mu.Unlock()
+
+		entry.Run = requestRun
+		entry.State = models.WorkflowStepPassed
+		if !requestRun.Passed {
+			entry.State = models.WorkflowStepFailed
+			entry.Reason = runFailureReason(requestRun)
+			passed = false
+			if state.Reason == "" {
+				state.Reason = entry.Reason
+			}
+		}
+		run.record(entry)
+
+		lastRun = requestRun
+		bodies = append(bodies, requestRun.Body)
+	}
+
+	stepContext := map[string]interface{}{"passed": passed}
+	if lastRun != nil {
+		stepContext["status"] = lastRun.Status
+		stepContext["statusCode"] = lastRun.StatusCode
+		stepContext["headers"] = lastRun.Headers
+		stepContext["body"] = lastRun.Body
+	}
+	if step.ForEach != "" {
+		stepContext["body"] = bodies
+	}
+
+	state.State = models.WorkflowStepPassed
+	if !passed {
+		state.State = models.WorkflowStepFailed
+	}
+	stepContext["state"] = state.State
+
+	run.mu.Lock()
+	run.stepContexts[step.ID] = stepContext
+	run.mu.Unlock()
+	return state
+}
+
+func (rr *RequestRunner) failWorkflowStep(step *models.WorkflowStep, state models.WorkflowStepState, run *workflowRun, reason string) models.WorkflowStepState {
+	state.State = models.WorkflowStepFailed
+	state.Reason = reason
+	run.record(models.WorkflowTraceEntry{
+		StepID:    step.ID,
+		RequestID: step.RequestID,
+		State:     state.State,
+		Reason:    reason,
+		StartedAt: time.Now(),
+	})
+	run.mu.Lock()
+	run.stepContexts[step.ID] = map[string]interface{}{"state": state.State, "passed": false}
+	run.mu.Unlock()
+	return state
+}
+
+// mergeVariableChanges applies to target the variables that were set, changed or removed in
+// updated compared to snapshot. Variables left as they were in snapshot are not written, so
+// newer values written to target in the meantime are kept.
+func mergeVariableChanges(target, snapshot, updated map[string]string) {
+	for name, value := range updated {
+		if previous, ok := snapshot[name]; !ok || previous != value {
+			target[name] = value
+		}
+	}
+	for name := range snapshot {
+		if _, ok := updated[name]; !ok {
+			delete(target, name)
+		}
+	}
+}
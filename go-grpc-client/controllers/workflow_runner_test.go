@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeVariableChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   map[string]string
+		snapshot map[string]string
+		updated  map[string]string
+		want     map[string]string
+	}{
+		{
+			name:     "unchanged variables keep newer values",
+			target:   map[string]string{"token": "from-other-branch"},
+			snapshot: map[string]string{"token": "old"},
+			updated:  map[string]string{"token": "old"},
+			want:     map[string]string{"token": "from-other-branch"},
+		},
+		{
+			name:     "changed variables are written",
+			target:   map[string]string{"token": "from-other-branch"},
+			snapshot: map[string]string{"token": "old"},
+			updated:  map[string]string{"token": "new"},
+			want:     map[string]string{"token": "new"},
+		},
+		{
+			name:     "new variables are added",
+			target:   map[string]string{"a": "1"},
+			snapshot: map[string]string{"a": "1"},
+			updated:  map[string]string{"a": "1", "b": "2"},
+			want:     map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name:     "unset variables are deleted",
+			target:   map[string]string{"a": "1", "b": "2"},
+			snapshot: map[string]string{"a": "1", "b": "2"},
+			updated:  map[string]string{"a": "1"},
+			want:     map[string]string{"a": "1"},
+		},
+		{
+			name:     "variables added elsewhere are kept",
+			target:   map[string]string{"a": "1", "other": "x"},
+			snapshot: map[string]string{"a": "1"},
+			updated:  map[string]string{"a": "1"},
+			want:     map[string]string{"a": "1", "other": "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mergeVariableChanges(tt.target, tt.snapshot, tt.updated)
+			if !reflect.DeepEqual(tt.target, tt.want) {
+				t.Errorf("mergeVariableChanges() = %v, want %v", tt.target, tt.want)
+			}
+		})
+	}
+}
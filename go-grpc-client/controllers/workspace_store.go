@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"grpc-client/constants"
+	"grpc-client/models"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WorkspaceStore reads and writes the workspace file shared by all controllers
+type WorkspaceStore struct {
+	baseFolderPath string
+	workspaceFile  string
+	mu             sync.Mutex
+}
+
+func NewWorkspaceStore() *WorkspaceStore {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		log.Printf("Error getting home directory: %v", err)
+		homeDir = "."
+	}
+
+	baseFolderPath := filepath.Join(homeDir, constants.GrpcCollectionLocation)
+	workspaceFile := filepath.Join(baseFolderPath, "workspace.json")
+
+	// Ensure that the base folder exists
+	if err := os.MkdirAll(baseFolderPath, 0755); err != nil {
+		log.Printf("Error creating base folder: %v", err)
+	}
+
+	return &WorkspaceStore{
+		baseFolderPath: baseFolderPath,
+		workspaceFile:  workspaceFile,
+	}
+}
+
+// BaseFolderPath returns the folder holding the workspace and other persisted data
+func (ws *WorkspaceStore) BaseFolderPath() string {
+	return ws.baseFolderPath
+}
+
+// Load reads the workspace file, returning an empty workspace if it does not exist yet
+func (ws *WorkspaceStore) Load() (*models.Workspace, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.load()
+}
+
+// Save writes the workspace file
+func (ws *WorkspaceStore) Save(workspace *models.Workspace) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.save(workspace)
+}
+
+// Update loads the workspace, applies fn and saves the result as one atomic step
+func (ws *WorkspaceStore) Update(fn func(workspace *models.Workspace) error) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	workspace, err := ws.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(workspace); err != nil {
+		return err
+	}
+	return ws.save(workspace)
+}
+
+// AllCollections returns the workspace collections followed by the read-only sample collections
+func (ws *WorkspaceStore) AllCollections() ([]models.Collection, error) {
+	workspace, err := ws.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	collections := append([]models.Collection{}, workspace.Collections...)
+	existingIDs := make(map[string]bool)
+	for _, collection := range collections {
+		existingIDs[collection.ID] = true
+	}
+	for _, collection := range constants.SampleData {
+		if !existingIDs[collection.ID] {
+			collections = append(collections, collection)
+		}
+	}
+	return collections, nil
+}
+
+// FindEnvironment finds an environment by ID or (case-insensitive) name.
+// When collectionID is set, only that collection is searched.
+func (ws *WorkspaceStore) FindEnvironment(ref, collectionID string) (*models.Environment, *models.Collection, error) {
+	collections, err := ws.AllCollections()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range collections {
+		collection := &collections[i]
+		if collectionID != "" && collection.ID != collectionID {
+			continue
+		}
+		for j := range collection.Environments {
+			environment := &collection.Environments[j]
+			if environment.ID == ref || strings.EqualFold(environment.Name, ref) {
+				return environment, collection, nil
+			}
+		}
+	}
+
+	return nil, nil, fmt.Errorf("environment %q not found", ref)
+}
+
+func (ws *WorkspaceStore) load() (*models.Workspace, error) {
+	if _, err := os.Stat(ws.workspaceFile); os.IsNotExist(err) {
+		return &models.Workspace{
+			Collections: []models.Collection{},
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}, nil
+	}
+
+	content, err := ioutil.ReadFile(ws.workspaceFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var workspace models.Workspace
+	if err := json.Unmarshal(content, &workspace); err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
+
+func (ws *WorkspaceStore) save(workspace *models.Workspace) error {
+	workspace.UpdatedAt = time.Now()
+
+	content, err := json.MarshalIndent(workspace, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(ws.workspaceFile, content, 0644)
+}
+
+// ResolverFor builds a variable resolver for a request. Variables are looked up in the
+// request, the selected environment (or the collection's active one), the collection
+// and finally the workspace. The selected environment is returned when there is one.
+func (ws *WorkspaceStore) ResolverFor(collectionID, environmentID string, requestVariables map[string]string) (*VariableResolver, *models.Environment, error) {
+	workspace, err := ws.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	collections, err := ws.AllCollections()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var collection *models.Collection
+	if collectionID != "" {
+		for i := range collections {
+			if collections[i].ID == collectionID {
+				collection = &collections[i]
+				break
+			}
+		}
+		if collection == nil {
+			return nil, nil, fmt.Errorf("collection %q not found", collectionID)
+		}
+	}
+
+	var environment *models.Environment
+	if environmentID != "" {
+		environment, collection, err = ws.FindEnvironment(environmentID, collectionID)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if collection != nil {
+		for i := range collection.Environments {
+			if collection.Environments[i].IsActive {
+				environment = &collection.Environments[i]
+				break
+			}
+		}
+	}
+
+	resolver := NewVariableResolver()
+	resolver.addScope(scopeRequest, requestVariables)
+	if environment != nil {
+		resolver.addScope(scopeEnvironment, environment.Variables)
+	}
+	if collection != nil {
+		resolver.addScope(scopeCollection, collection.Variables)
+	}
+	resolver.addScope(scopeWorkspace, workspace.Variables)
+
+	return resolver, environment, nil
+}
@@ -33,12 +33,35 @@ func main() {
 	router.Use(gin.Recovery())
 
 	// Initialize controllers
-	grpcController := controllers.NewGrpcController()
+	workspaceStore := controllers.NewWorkspaceStore()
+	callExecutor := controllers.NewCallExecutor()
+	historyStore := controllers.NewHistoryStore(workspaceStore)
+	grpcController := controllers.NewGrpcController(callExecutor, workspaceStore, historyStore)
+	restController := controllers.NewRestController(callExecutor, workspaceStore)
+	gatewayController := controllers.NewGatewayController(callExecutor, workspaceStore)
+	requestRunner := controllers.NewRequestRunner(callExecutor, workspaceStore)
+	notificationDispatcher := controllers.NewNotificationDispatcher(workspaceStore)
+	runnerController := controllers.NewRunnerController(requestRunner, notificationDispatcher)
+	workflowController := controllers.NewWorkflowController(requestRunner, workspaceStore, notificationDispatcher)
+	benchmarkController := controllers.NewBenchmarkController(callExecutor, requestRunner)
+	compareController := controllers.NewCompareController(requestRunner)
+	jobManager := controllers.NewJobManager(workspaceStore)
+	jobController := controllers.NewJobController(jobManager, requestRunner, callExecutor, notificationDispatcher)
+	historyController := controllers.NewHistoryController(historyStore, callExecutor, workspaceStore)
+	monitorScheduler := controllers.NewMonitorScheduler(requestRunner, workspaceStore, notificationDispatcher)
+	monitorController := controllers.NewMonitorController(monitorScheduler, requestRunner, workspaceStore)
+	notifierController := controllers.NewNotifierController(notificationDispatcher, workspaceStore)
+	snapshotController := controllers.NewSnapshotController(requestRunner, workspaceStore)
 	reflectionController := controllers.NewReflectionController()
-	enhancedCollectionController := controllers.NewEnhancedCollectionController()
+	enhancedCollectionController := controllers.NewEnhancedCollectionController(workspaceStore)
+
+	// Start the monitors of the workspace
+	if err := monitorScheduler.Start(); err != nil {
+		log.Printf("Error starting monitors: %v", err)
+	}
 
 	// Setup routes
-	setupRoutes(router, grpcController, reflectionController, enhancedCollectionController)
+	setupRoutes(router, grpcController, restController, gatewayController, runnerController, workflowController, benchmarkController, compareController, jobController, historyController, monitorController, notifierController, snapshotController, reflectionController, enhancedCollectionController)
 
 	// Get port from environment or use default
 	port := os.Getenv("PORT")
@@ -55,6 +78,17 @@ func main() {
 func setupRoutes(
 	router *gin.Engine,
 	grpcController *controllers.GrpcController,
+	restController *controllers.RestController,
+	gatewayController *controllers.GatewayController,
+	runnerController *controllers.RunnerController,
+	workflowController *controllers.WorkflowController,
+	benchmarkController *controllers.BenchmarkController,
+	compareController *controllers.CompareController,
+	jobController *controllers.JobController,
+	historyController *controllers.HistoryController,
+	monitorController *controllers.MonitorController,
+	notifierController *controllers.NotifierController,
+	snapshotController *controllers.SnapshotController,
 	reflectionController *controllers.ReflectionController,
 	enhancedCollectionController *controllers.EnhancedCollectionController,
 ) {
@@ -65,6 +99,48 @@ func setupRoutes(
 	{
 		grpcGroup.GET("/", grpcController.DefaultEndpoint)
 		grpcGroup.POST("/call", grpcController.MakeGrpcCall)
+		grpcGroup.POST("/batch", grpcController.MakeBatchCall)
+		grpcGroup.POST("/resolve", grpcController.ResolveGrpcRequest)
+		grpcGroup.POST("/benchmark", benchmarkController.RunBenchmark)
+		grpcGroup.POST("/compare", compareController.CompareResponses)
+	}
+
+	// REST routes
+	restGroup := router.Group("/rest")
+	{
+		restGroup.GET("/", restController.DefaultEndpoint)
+		restGroup.POST("/call", restController.MakeRestCall)
+		restGroup.POST("/resolve", restController.ResolveRestRequest)
+	}
+
+	// REST-to-gRPC gateway routes
+	gatewayGroup := router.Group("/gateway")
+	{
+		gatewayGroup.Any("/:host/:service/:method", gatewayController.Invoke)
+	}
+
+	// Background job routes
+	jobGroup := router.Group("/jobs")
+	{
+		jobGroup.GET("", jobController.ListJobs)
+		jobGroup.POST("", jobController.StartJob)
+		jobGroup.GET("/:id", jobController.GetJob)
+		jobGroup.DELETE("/:id", jobController.DeleteJob)
+		jobGroup.POST("/:id/cancel", jobController.CancelJob)
+		jobGroup.GET("/:id/events", jobController.StreamJobEvents)
+		jobGroup.GET("/:id/report", jobController.GetJobReport)
+	}
+
+	// Call history routes
+	historyGroup := router.Group("/history")
+	{
+		historyGroup.GET("", historyController.ListHistory)
+		historyGroup.DELETE("", historyController.ClearHistory)
+		historyGroup.GET("/stats", historyController.GetHistoryStats)
+		historyGroup.GET("/:id", historyController.GetHistoryEntry)
+		historyGroup.DELETE("/:id", historyController.DeleteHistoryEntry)
+		historyGroup.POST("/:id/replay", historyController.ReplayHistoryEntry)
+		historyGroup.POST("/:id/save", historyController.SaveHistoryEntry)
 	}
 
 	// Metadata/reflection routes
@@ -82,20 +158,51 @@ func setupRoutes(
 		collectionGroup.GET("/workspace", enhancedCollectionController.LoadWorkspace)
 		collectionGroup.GET("/workspace/export", enhancedCollectionController.ExportWorkspace)
 		collectionGroup.POST("/workspace/import", enhancedCollectionController.ImportWorkspace)
+		collectionGroup.PUT("/workspace/variables", enhancedCollectionController.UpdateWorkspaceVariables)
 
 		// Collection management
 		collectionGroup.POST("/collections", enhancedCollectionController.CreateCollection)
 		collectionGroup.PUT("/collections/:id", enhancedCollectionController.UpdateCollection)
 		collectionGroup.DELETE("/collections/:id", enhancedCollectionController.DeleteCollection)
 		collectionGroup.PUT("/collections/:id/order", enhancedCollectionController.UpdateOrder)
+		collectionGroup.POST("/collections/:id/run", runnerController.RunCollection)
 
 		// Request management
 		collectionGroup.POST("/requests", enhancedCollectionController.SaveRequest)
 		collectionGroup.PUT("/requests/:requestId", enhancedCollectionController.UpdateRequest)
 		collectionGroup.DELETE("/requests/:requestId", enhancedCollectionController.DeleteRequest)
+		collectionGroup.POST("/requests/:requestId/run", runnerController.RunRequest)
+		collectionGroup.GET("/requests/:requestId/snapshot", snapshotController.GetSnapshot)
+		collectionGroup.POST("/requests/:requestId/snapshot", snapshotController.SaveSnapshot)
+		collectionGroup.PUT("/requests/:requestId/snapshot", snapshotController.UpdateSnapshotIgnorePaths)
+		collectionGroup.DELETE("/requests/:requestId/snapshot", snapshotController.DeleteSnapshot)
 
 		// Environment management
 		collectionGroup.POST("/environments", enhancedCollectionController.CreateEnvironment)
+		collectionGroup.PUT("/environments/:id", enhancedCollectionController.UpdateEnvironment)
+
+		// Workflow management
+		collectionGroup.GET("/workflows", workflowController.ListWorkflows)
+		collectionGroup.POST("/workflows", workflowController.CreateWorkflow)
+		collectionGroup.PUT("/workflows/:id", workflowController.UpdateWorkflow)
+		collectionGroup.DELETE("/workflows/:id", workflowController.DeleteWorkflow)
+		collectionGroup.POST("/workflows/:id/run", workflowController.RunWorkflow)
+
+		// Monitor management
+		collectionGroup.GET("/monitors", monitorController.ListMonitors)
+		collectionGroup.POST("/monitors", monitorController.CreateMonitor)
+		collectionGroup.GET("/monitors/status", monitorController.MonitorStatuses)
+		collectionGroup.PUT("/monitors/:id", monitorController.UpdateMonitor)
+		collectionGroup.DELETE("/monitors/:id", monitorController.DeleteMonitor)
+		collectionGroup.GET("/monitors/:id/checks", monitorController.MonitorChecks)
+		collectionGroup.POST("/monitors/:id/run", monitorController.RunMonitor)
+
+		// Notifier management
+		collectionGroup.GET("/notifiers", notifierController.ListNotifiers)
+		collectionGroup.POST("/notifiers", notifierController.CreateNotifier)
+		collectionGroup.PUT("/notifiers/:id", notifierController.UpdateNotifier)
+		collectionGroup.DELETE("/notifiers/:id", notifierController.DeleteNotifier)
+		collectionGroup.POST("/notifiers/:id/test", notifierController.TestNotifier)
 	}
 
 	// Serve embedded static files - create filesystem for assets subdirectory
@@ -138,6 +245,10 @@ func setupRoutes(
 		path := c.Request.URL.Path
 		// Don't serve SPA for API routes or asset requests
 		if strings.HasPrefix(path, "/grpc") ||
+			strings.HasPrefix(path, "/rest") ||
+			strings.HasPrefix(path, "/gateway") ||
+			strings.HasPrefix(path, "/jobs") ||
+			strings.HasPrefix(path, "/history") ||
 			strings.HasPrefix(path, "/metadata") ||
 			strings.HasPrefix(path, "/collection") ||
 			strings.HasPrefix(path, "/assets") ||
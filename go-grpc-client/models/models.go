@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -12,6 +13,63 @@ const (
 	RequestTypeREST RequestType = "rest"
 )
 
+// CallProtocol defines the wire protocol used to invoke a gRPC method
+type CallProtocol string
+
+const (
+	ProtocolGRPC    CallProtocol = "grpc"
+	ProtocolConnect CallProtocol = "connect"
+	ProtocolREST    CallProtocol = "rest" // Plain HTTP calls made from a RESTConfig
+
+	// ProtocolTranscoding sends the call through the REST route declared by the
+	// method's google.api.http annotation (e.g. via grpc-gateway)
+	ProtocolTranscoding CallProtocol = "transcoding"
+)
+
+// ConnectOptions configures calls made with the Connect protocol
+type ConnectOptions struct {
+	Codec       string `json:"codec,omitempty"`       // json (default) or proto
+	HTTPVersion string `json:"httpVersion,omitempty"` // 1.1 (default) or 2
+}
+
+// TranscodingOptions configures calls sent through gRPC-HTTP transcoding routes
+type TranscodingOptions struct {
+	BaseURL string `json:"baseUrl,omitempty"` // HTTP gateway address; defaults to the request host
+}
+
+// RetryPolicy retries a call while it fails with a retryable status, waiting an exponentially
+// growing, jittered delay between attempts
+type RetryPolicy struct {
+	MaxAttempts       int           `json:"maxAttempts"`                 // Attempts including the first one (at most 10)
+	RetryableStatuses []interface{} `json:"retryableStatuses,omitempty"` // gRPC status names or codes, or HTTP status codes; defaults to UNAVAILABLE (503 for REST)
+	InitialBackoffMs  int           `json:"initialBackoffMs,omitempty"`  // Delay before the first retry (default 100)
+	MaxBackoffMs      int           `json:"maxBackoffMs,omitempty"`      // Upper bound of a single delay (default 5000)
+	BackoffMultiplier float64       `json:"backoffMultiplier,omitempty"` // Growth of the delay per retry (default 2)
+	Jitter            *float64      `json:"jitter,omitempty"`            // Fraction of the delay that is randomized, 0 to 1 (default 0.2)
+	IgnoreRetryInfo   bool          `json:"ignoreRetryInfo,omitempty"`   // Do not wait for the delay the server asks for
+}
+
+// CallAttempt records one attempt of a retried call
+type CallAttempt struct {
+	Attempt      int       `json:"attempt"`
+	Status       string    `json:"status,omitempty"`
+	StatusCode   int       `json:"statusCode"`
+	Error        string    `json:"error,omitempty"`
+	StartedAt    time.Time `json:"startedAt"`
+	DurationMs   int64     `json:"durationMs"`
+	RetryDelayMs int64     `json:"retryDelayMs,omitempty"` // Wait before the next attempt
+	DelaySource  string    `json:"delaySource,omitempty"`  // backoff, or retryInfo (gRPC) / retryAfter (REST) when the server chose the delay
+}
+
+// HTTPRule describes a google.api.http annotation on a gRPC method
+type HTTPRule struct {
+	Method             string     `json:"method"` // GET, POST, PUT, PATCH, DELETE or a custom verb
+	Path               string     `json:"path"`
+	Body               string     `json:"body,omitempty"`
+	ResponseBody       string     `json:"responseBody,omitempty"`
+	AdditionalBindings []HTTPRule `json:"additionalBindings,omitempty"`
+}
+
 // Environment represents a collection environment (dev, staging, prod, etc.)
 type Environment struct {
 	ID          string            `json:"id"`
@@ -21,6 +79,10 @@ type Environment struct {
 	IsActive    bool              `json:"isActive"`
 	CreatedAt   time.Time         `json:"createdAt"`
 	UpdatedAt   time.Time         `json:"updatedAt"`
+
+	// Auth and metadata applied to gateway calls and saved request runs using this environment
+	Auth     *RequestAuth    `json:"auth,omitempty"`
+	Metadata []RequestHeader `json:"metadata,omitempty"`
 }
 
 // RequestAuth represents authentication configuration
@@ -42,6 +104,11 @@ type GRPCConfig struct {
 	Method   string          `json:"method"`
 	Message  interface{}     `json:"message"`
 	Metadata []RequestHeader `json:"metadata"`
+
+	// Transport selection (defaults to native gRPC)
+	Protocol    CallProtocol        `json:"protocol,omitempty"`
+	Connect     *ConnectOptions     `json:"connect,omitempty"`
+	Transcoding *TranscodingOptions `json:"transcoding,omitempty"`
 }
 
 // RESTConfig represents REST API specific configuration
@@ -53,6 +120,158 @@ type RESTConfig struct {
 	Params  []RequestHeader `json:"params,omitempty"` // Query parameters
 }
 
+// AssertionType identifies what an assertion checks
+type AssertionType string
+
+const (
+	AssertionStatus   AssertionType = "status"   // gRPC status name/code or HTTP status code
+	AssertionJSONPath AssertionType = "jsonPath" // Response field at a JSON path
+	AssertionHeader   AssertionType = "header"   // Response header (gRPC header metadata)
+	AssertionTrailer  AssertionType = "trailer"  // gRPC trailer metadata
+	AssertionLatency  AssertionType = "latency"  // Call duration in milliseconds
+	AssertionSchema   AssertionType = "schema"   // Response (or the value at path) conforms to a JSON schema
+)
+
+// Assertion is a check evaluated against the response of a saved request
+type Assertion struct {
+	Type     AssertionType `json:"type"`
+	Path     string        `json:"path,omitempty"`     // JSON path for jsonPath and schema, name for header and trailer
+	Operator string        `json:"operator,omitempty"` // equals, notEquals, contains, notContains, matches, exists, notExists, greaterThan, lessThan
+	Expected interface{}   `json:"expected,omitempty"`
+	Schema   interface{}   `json:"schema,omitempty"`
+	Disabled bool          `json:"disabled,omitempty"`
+}
+
+// ExtractionRule copies a value from a response into a variable for later requests
+type ExtractionRule struct {
+	Variable string `json:"variable"`
+	Source   string `json:"source,omitempty"` // body (default), header, trailer or status
+	Path     string `json:"path,omitempty"`   // JSONPath ($.order.id) or jq-style (.order.id) for body, name for header and trailer
+	Scope    string `json:"scope,omitempty"`  // run (default) or environment
+}
+
+// ExtractionResult is the outcome of one extraction rule
+type ExtractionResult struct {
+	Variable string `json:"variable"`
+	Scope    string `json:"scope"`
+	Value    string `json:"value,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ScriptTestResult is the outcome of a test registered by a script with pm.test
+type ScriptTestResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AssertionResult is the outcome of one assertion
+type AssertionResult struct {
+	Assertion Assertion   `json:"assertion"`
+	Passed    bool        `json:"passed"`
+	Actual    interface{} `json:"actual,omitempty"`
+	Message   string      `json:"message,omitempty"` // Reason for a failure
+}
+
+// ResponseSnapshot is the golden response of a saved request. Later runs fail when their
+// response differs from it outside the ignored paths.
+type ResponseSnapshot struct {
+	Status      string      `json:"status"`
+	StatusCode  int         `json:"statusCode"`
+	Body        interface{} `json:"body,omitempty"`
+	IgnorePaths []string    `json:"ignorePaths,omitempty"` // Volatile fields, e.g. "$.createdAt" or "$.items[*].id"
+	CreatedAt   time.Time   `json:"createdAt"`
+}
+
+// Kinds of response differences
+const (
+	DifferenceAdded       = "added"       // Only in the response
+	DifferenceRemoved     = "removed"     // Only in the expected response
+	DifferenceChanged     = "changed"     // Different value of the same type
+	DifferenceTypeChanged = "typeChanged" // Different JSON type
+)
+
+// ResponseDifference is a field where a response differs from the expected response: a
+// snapshot, or the baseline of a comparison
+type ResponseDifference struct {
+	Path     string      `json:"path"` // JSON path of the field, or "status"
+	Kind     string      `json:"kind"`
+	Expected interface{} `json:"expected"` // Expected value; null for added fields
+	Actual   interface{} `json:"actual"`   // Response value; null for removed fields
+}
+
+// SnapshotResult is the comparison of a response with a snapshot
+type SnapshotResult struct {
+	Matched     bool                 `json:"matched"`
+	Differences []ResponseDifference `json:"differences"`
+}
+
+// SaveSnapshotRequest marks a response as the snapshot of a saved request. Without a
+// response the request is run and its response is used.
+type SaveSnapshotRequest struct {
+	Response      *CallResult       `json:"response,omitempty"`
+	IgnorePaths   []string          `json:"ignorePaths,omitempty"`
+	EnvironmentID string            `json:"environmentId,omitempty"` // Environment of the run
+	Variables     map[string]string `json:"variables,omitempty"`     // Run variables of the run
+}
+
+// CompareTarget is an environment and/or host that a compared request is sent to
+type CompareTarget struct {
+	Name          string `json:"name,omitempty"`          // Label; defaults to the environment or host
+	EnvironmentID string `json:"environmentId,omitempty"` // Environment the request is resolved in
+	Host          string `json:"host,omitempty"`          // Replaces the resolved host
+}
+
+// CompareRequest sends the same gRPC requests to several targets and compares the responses
+// with those of the first target
+type CompareRequest struct {
+	Request     *GrpcRequest      `json:"request,omitempty"`    // Inline call, or
+	RequestIDs  []string          `json:"requestIds,omitempty"` // saved gRPC requests
+	Variables   map[string]string `json:"variables,omitempty"`  // Run variables for every target
+	Targets     []CompareTarget   `json:"targets" binding:"required"`
+	IgnorePaths []string          `json:"ignorePaths,omitempty"` // Fields left out of the diff, e.g. "$.serverTime"
+}
+
+// CompareResponse is the response of one target
+type CompareResponse struct {
+	Target             string      `json:"target"`
+	EnvironmentID      string      `json:"environmentId,omitempty"`
+	Host               string      `json:"host"`
+	Status             string      `json:"status,omitempty"`
+	StatusCode         int         `json:"statusCode"`
+	Error              string      `json:"error,omitempty"`
+	Body               interface{} `json:"body,omitempty"`
+	UndefinedVariables []string    `json:"undefinedVariables,omitempty"`
+	DurationMs         int64       `json:"durationMs"`
+}
+
+// CompareDiff compares the response of a target with the baseline response
+type CompareDiff struct {
+	Target         string               `json:"target"`
+	Matched        bool                 `json:"matched"`
+	Differences    []ResponseDifference `json:"differences"`
+	LatencyDeltaMs int64                `json:"latencyDeltaMs"` // Target latency minus baseline latency
+}
+
+// CompareResult compares the responses of one request across the targets
+type CompareResult struct {
+	RequestID   string            `json:"requestId,omitempty"`
+	RequestName string            `json:"requestName,omitempty"`
+	Method      string            `json:"method"`
+	Matched     bool              `json:"matched"`
+	Responses   []CompareResponse `json:"responses"`
+	Diffs       []CompareDiff     `json:"diffs"` // One per target after the baseline
+}
+
+// CompareRunResult is the outcome of a comparison
+type CompareRunResult struct {
+	Baseline   string          `json:"baseline"` // Target the others are compared with
+	Matched    bool            `json:"matched"`
+	Results    []CompareResult `json:"results"`
+	StartedAt  time.Time       `json:"startedAt"`
+	DurationMs int64           `json:"durationMs"`
+}
+
 // Request represents a single request in a collection
 type Request struct {
 	ID          string      `json:"id"`
@@ -62,8 +281,9 @@ type Request struct {
 	Order       int         `json:"order"`
 
 	// Common configuration
-	Host string      `json:"host"`
-	Auth RequestAuth `json:"auth,omitempty"`
+	Host  string       `json:"host"`
+	Auth  RequestAuth  `json:"auth,omitempty"`
+	Retry *RetryPolicy `json:"retry,omitempty"`
 
 	// Type-specific configuration
 	GRPCConfig *GRPCConfig `json:"grpcConfig,omitempty"`
@@ -72,6 +292,19 @@ type Request struct {
 	// Variables used in this request
 	Variables map[string]string `json:"variables,omitempty"`
 
+	// Checks evaluated against the response after each run
+	Assertions []Assertion `json:"assertions,omitempty"`
+
+	// JavaScript run before the call and after the response
+	PreRequestScript   string `json:"preRequestScript,omitempty"`
+	PostResponseScript string `json:"postResponseScript,omitempty"`
+
+	// Response values stored into variables after each run
+	Extractions []ExtractionRule `json:"extractions,omitempty"`
+
+	// Golden response that later runs are compared with
+	Snapshot *ResponseSnapshot `json:"snapshot,omitempty"`
+
 	// Metadata
 	Tags      []string  `json:"tags,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
@@ -89,6 +322,10 @@ type Collection struct {
 	// Collection-level variables (inherited by all requests)
 	Variables map[string]string `json:"variables,omitempty"`
 
+	// JavaScript run around every request of the collection, before the request's own scripts
+	PreRequestScript   string `json:"preRequestScript,omitempty"`
+	PostResponseScript string `json:"postResponseScript,omitempty"`
+
 	// Metadata
 	Tags      []string  `json:"tags,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
@@ -97,18 +334,576 @@ type Collection struct {
 
 // Workspace represents the entire workspace containing all collections
 type Workspace struct {
-	Collections []Collection `json:"collections"`
-	Settings    interface{}  `json:"settings,omitempty"`
-	CreatedAt   time.Time    `json:"createdAt"`
-	UpdatedAt   time.Time    `json:"updatedAt"`
+	Collections []Collection      `json:"collections"`
+	Workflows   []Workflow        `json:"workflows,omitempty"`
+	Monitors    []Monitor         `json:"monitors,omitempty"`
+	Notifiers   []Notifier        `json:"notifiers,omitempty"`
+	Variables   map[string]string `json:"variables,omitempty"` // Workspace-level variables (lowest precedence)
+	Settings    interface{}       `json:"settings,omitempty"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
+
+// Workflow is a graph of saved requests with dependencies, conditions and loops
+type Workflow struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Description   string            `json:"description,omitempty"`
+	EnvironmentID string            `json:"environmentId,omitempty"` // Environment ID or name, looked up in each step's collection
+	Variables     map[string]string `json:"variables,omitempty"`     // Initial run variables
+	Steps         []WorkflowStep    `json:"steps"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	UpdatedAt     time.Time         `json:"updatedAt"`
+}
+
+// WorkflowStep runs a saved request once its dependencies have finished. Steps without
+// a dependency between them run in parallel.
+type WorkflowStep struct {
+	ID                string   `json:"id"`
+	RequestID         string   `json:"requestId"`
+	DependsOn         []string `json:"dependsOn,omitempty"`
+	Condition         string   `json:"condition,omitempty"`         // JavaScript expression over steps and vars; the step is skipped when false
+	ForEach           string   `json:"forEach,omitempty"`           // JSONPath over {steps, vars} returning the items to loop over
+	ItemVariable      string   `json:"itemVariable,omitempty"`      // Run variable holding the current item (default "item")
+	ContinueOnFailure bool     `json:"continueOnFailure,omitempty"` // Let dependent steps run even if this step fails
 }
 
 // Legacy support - Keep for backward compatibility
 type GrpcRequest struct {
-	Host     string            `json:"host" binding:"required"`
-	Method   string            `json:"method" binding:"required"`
-	Message  interface{}       `json:"message"`
-	MetaData map[string]string `json:"metaData,omitempty"`
+	Host        string              `json:"host" binding:"required"`
+	Method      string              `json:"method" binding:"required"`
+	Message     interface{}         `json:"message"`
+	MetaData    map[string]string   `json:"metaData,omitempty"`
+	Protocol    CallProtocol        `json:"protocol,omitempty"`
+	Connect     *ConnectOptions     `json:"connect,omitempty"`
+	Transcoding *TranscodingOptions `json:"transcoding,omitempty"`
+	Auth        *RequestAuth        `json:"auth,omitempty"`
+	Retry       *RetryPolicy        `json:"retry,omitempty"`
+
+	// Variable resolution context for {{name}} placeholders
+	CollectionID  string            `json:"collectionId,omitempty"`
+	EnvironmentID string            `json:"environmentId,omitempty"`
+	Variables     map[string]string `json:"variables,omitempty"`
+}
+
+// RestCallRequest executes a REST request described by a RESTConfig
+type RestCallRequest struct {
+	Host       string       `json:"host,omitempty"` // Base URL used when restConfig.url is relative
+	RESTConfig RESTConfig   `json:"restConfig" binding:"required"`
+	Auth       *RequestAuth `json:"auth,omitempty"`
+	Retry      *RetryPolicy `json:"retry,omitempty"`
+
+	// Variable resolution context for {{name}} placeholders
+	CollectionID  string            `json:"collectionId,omitempty"`
+	EnvironmentID string            `json:"environmentId,omitempty"`
+	Variables     map[string]string `json:"variables,omitempty"`
+}
+
+// ResolvedGrpcRequest previews a gRPC request after variable substitution
+type ResolvedGrpcRequest struct {
+	Request            GrpcRequest `json:"request"`
+	UndefinedVariables []string    `json:"undefinedVariables"`
+}
+
+// ResolvedRestRequest previews a REST request after variable substitution
+type ResolvedRestRequest struct {
+	Request            RestCallRequest `json:"request"`
+	UndefinedVariables []string        `json:"undefinedVariables"`
+}
+
+// CallResult is the response envelope shared by all executed calls
+type CallResult struct {
+	Protocol     CallProtocol        `json:"protocol"`
+	Status       string              `json:"status"`     // gRPC status name (OK, NOT_FOUND, ...) or HTTP status line
+	StatusCode   int                 `json:"statusCode"` // gRPC status code or HTTP status code
+	Headers      map[string][]string `json:"headers,omitempty"`
+	Trailers     map[string][]string `json:"trailers,omitempty"`
+	Body         interface{}         `json:"body,omitempty"`
+	Error        string              `json:"error,omitempty"`
+	ErrorDetails []interface{}       `json:"errorDetails,omitempty"`
+	StartedAt    time.Time           `json:"startedAt"`
+	DurationMs   int64               `json:"durationMs"`
+	Attempts     []CallAttempt       `json:"attempts,omitempty"` // Every attempt of a call sent with a retry policy
+}
+
+// Succeeded reports whether the call completed with an OK (gRPC) or 2xx (HTTP) status
+func (r *CallResult) Succeeded() bool {
+	if r.Error != "" {
+		return false
+	}
+	if r.Protocol == ProtocolREST || r.Protocol == ProtocolTranscoding {
+		return r.StatusCode >= 200 && r.StatusCode < 300
+	}
+	return r.StatusCode == 0
+}
+
+// RunRequestOptions selects the environment and extra variables for running a saved request
+type RunRequestOptions struct {
+	EnvironmentID string            `json:"environmentId,omitempty"` // Defaults to the collection's active environment
+	Variables     map[string]string `json:"variables,omitempty"`     // Override all other variable scopes
+}
+
+// RequestRunResult is the result envelope of a saved request run
+type RequestRunResult struct {
+	RequestID          string             `json:"requestId"`
+	RequestName        string             `json:"requestName"`
+	EnvironmentID      string             `json:"environmentId,omitempty"`
+	UndefinedVariables []string           `json:"undefinedVariables,omitempty"`
+	Passed             bool               `json:"passed"` // Call succeeded (unless a status assertion is set), all assertions and script tests passed
+	Assertions         []AssertionResult  `json:"assertions,omitempty"`
+	ScriptTests        []ScriptTestResult `json:"scriptTests,omitempty"`
+	ScriptLogs         []string           `json:"scriptLogs,omitempty"`
+	ScriptError        string             `json:"scriptError,omitempty"`
+	Extracted          []ExtractionResult `json:"extracted,omitempty"`
+	Snapshot           *SnapshotResult    `json:"snapshot,omitempty"` // Comparison with the request's snapshot
+	Request            interface{}        `json:"request,omitempty"`  // Sent gRPC or REST request, with secrets redacted
+	*CallResult
+}
+
+// CollectionRunOptions controls which requests of a collection are run and how
+type CollectionRunOptions struct {
+	EnvironmentID  string            `json:"environmentId,omitempty"`
+	Variables      map[string]string `json:"variables,omitempty"`
+	RequestIDs     []string          `json:"requestIds,omitempty"` // Run only these requests
+	Tags           []string          `json:"tags,omitempty"`       // Run only requests having one of these tags
+	StopOnFailure  bool              `json:"stopOnFailure,omitempty"`
+	DelayMs        int               `json:"delayMs,omitempty"`        // Pause between requests
+	Iterations     int               `json:"iterations,omitempty"`     // Defaults to 1, or the number of data rows
+	IncludeDetails bool              `json:"includeDetails,omitempty"` // Keep the sent request and the response of each item
+
+	// Data rows for data-driven runs: one iteration per row, with each column bound to a run variable
+	Data []map[string]interface{} `json:"data,omitempty"`
+}
+
+// CollectionRunItem summarizes one request executed during a collection run
+type CollectionRunItem struct {
+	Iteration          int                `json:"iteration"`
+	RequestID          string             `json:"requestId"`
+	RequestName        string             `json:"requestName"`
+	Protocol           CallProtocol       `json:"protocol,omitempty"`
+	Status             string             `json:"status,omitempty"`
+	StatusCode         int                `json:"statusCode"`
+	Passed             bool               `json:"passed"`
+	Error              string             `json:"error,omitempty"`
+	Attempts           int                `json:"attempts,omitempty"` // Attempts made under the request's retry policy
+	UndefinedVariables []string           `json:"undefinedVariables,omitempty"`
+	Assertions         []AssertionResult  `json:"assertions,omitempty"`
+	ScriptTests        []ScriptTestResult `json:"scriptTests,omitempty"`
+	ScriptError        string             `json:"scriptError,omitempty"`
+	Snapshot           *SnapshotResult    `json:"snapshot,omitempty"`
+	Request            interface{}        `json:"request,omitempty"`  // Sent request, with secrets redacted; set with includeDetails
+	Response           *CallResult        `json:"response,omitempty"` // Set with includeDetails
+	StartedAt          time.Time          `json:"startedAt"`
+	DurationMs         int64              `json:"durationMs"`
+}
+
+// Workflow step states
+const (
+	WorkflowStepPassed  = "passed"
+	WorkflowStepFailed  = "failed"
+	WorkflowStepSkipped = "skipped"
+)
+
+// WorkflowTraceEntry records one execution (or skip) of a workflow step
+type WorkflowTraceEntry struct {
+	StepID     string            `json:"stepId"`
+	RequestID  string            `json:"requestId"`
+	State      string            `json:"state"`
+	Reason     string            `json:"reason,omitempty"`
+	ItemIndex  *int              `json:"itemIndex,omitempty"` // Set for forEach iterations
+	Item       interface{}       `json:"item,omitempty"`
+	Run        *RequestRunResult `json:"run,omitempty"`
+	StartedAt  time.Time         `json:"startedAt"`
+	DurationMs int64             `json:"durationMs"`
+}
+
+// WorkflowStepState is the final state of a workflow step
+type WorkflowStepState struct {
+	StepID string `json:"stepId"`
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// WorkflowRunResult is the outcome of a workflow run with the per-step trace in completion order
+type WorkflowRunResult struct {
+	WorkflowID   string               `json:"workflowId"`
+	WorkflowName string               `json:"workflowName"`
+	Passed       bool                 `json:"passed"`
+	Steps        []WorkflowStepState  `json:"steps"`
+	Trace        []WorkflowTraceEntry `json:"trace"`
+	Variables    map[string]string    `json:"variables,omitempty"` // Run variables at the end of the run
+	StartedAt    time.Time            `json:"startedAt"`
+	DurationMs   int64                `json:"durationMs"`
+}
+
+// CollectionIterationResult summarizes one iteration of a collection run
+type CollectionIterationResult struct {
+	Iteration  int               `json:"iteration"`
+	Data       map[string]string `json:"data,omitempty"` // Data row bound in this iteration
+	Passed     bool              `json:"passed"`
+	Total      int               `json:"total"`
+	Failed     int               `json:"failed"`
+	DurationMs int64             `json:"durationMs"`
+}
+
+// CollectionRunResult is the summary of a collection run
+type CollectionRunResult struct {
+	CollectionID     string                      `json:"collectionId"`
+	CollectionName   string                      `json:"collectionName"`
+	EnvironmentID    string                      `json:"environmentId,omitempty"`
+	Iterations       int                         `json:"iterations"`
+	Total            int                         `json:"total"`
+	Passed           int                         `json:"passed"`
+	Failed           int                         `json:"failed"`
+	Stopped          bool                        `json:"stopped"` // The run ended early (failure with stopOnFailure, or cancellation)
+	Results          []CollectionRunItem         `json:"results"`
+	IterationResults []CollectionIterationResult `json:"iterationResults"`
+	StartedAt        time.Time                   `json:"startedAt"`
+	DurationMs       int64                       `json:"durationMs"`
+}
+
+// CollectionRunFailure is a request that did not pass in a collection run summary
+type CollectionRunFailure struct {
+	Iteration   int      `json:"iteration"`
+	RequestID   string   `json:"requestId"`
+	RequestName string   `json:"requestName"`
+	Status      string   `json:"status,omitempty"`
+	Reasons     []string `json:"reasons"`
+}
+
+// CollectionRunSummary is the JSON report of a collection run: its counts and failures
+type CollectionRunSummary struct {
+	CollectionID   string                 `json:"collectionId"`
+	CollectionName string                 `json:"collectionName"`
+	EnvironmentID  string                 `json:"environmentId,omitempty"`
+	Iterations     int                    `json:"iterations"`
+	Total          int                    `json:"total"`
+	Passed         int                    `json:"passed"`
+	Failed         int                    `json:"failed"`
+	Stopped        bool                   `json:"stopped"`
+	Failures       []CollectionRunFailure `json:"failures"`
+	StartedAt      time.Time              `json:"startedAt"`
+	DurationMs     int64                  `json:"durationMs"`
+}
+
+// BatchRequest executes several gRPC requests in one HTTP request
+type BatchRequest struct {
+	Requests    []GrpcRequest `json:"requests" binding:"required,min=1,dive"` // Resolved as for /grpc/call
+	Concurrency int           `json:"concurrency,omitempty"`                  // Calls in flight at once, defaults to 10
+}
+
+// BatchItemResult is the outcome of one request of a batch
+type BatchItemResult struct {
+	Index              int      `json:"index"` // Position of the request in the batch
+	Host               string   `json:"host"`
+	Method             string   `json:"method"`
+	UndefinedVariables []string `json:"undefinedVariables,omitempty"`
+	*CallResult
+}
+
+// BatchResult lists the outcome of every request of a batch in request order
+type BatchResult struct {
+	Total      int               `json:"total"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	Results    []BatchItemResult `json:"results"`
+	StartedAt  time.Time         `json:"startedAt"`
+	DurationMs int64             `json:"durationMs"`
+}
+
+// BenchmarkRequest describes a load test of a gRPC method, given inline or as a saved request
+type BenchmarkRequest struct {
+	Request       *GrpcRequest      `json:"request,omitempty"`       // Inline call; variables are resolved as for /grpc/call
+	RequestID     string            `json:"requestId,omitempty"`     // Saved gRPC request, used when request is not set
+	EnvironmentID string            `json:"environmentId,omitempty"` // Environment of the saved request
+	Variables     map[string]string `json:"variables,omitempty"`     // Run variables of the saved request
+
+	Concurrency   int `json:"concurrency,omitempty"`   // Parallel workers, defaults to 10
+	TotalRequests int `json:"totalRequests,omitempty"` // Stop after this many calls; defaults to 200 without durationMs
+	DurationMs    int `json:"durationMs,omitempty"`    // Stop after this long
+	RPS           int `json:"rps,omitempty"`           // Target calls per second over all workers; 0 is unlimited
+	Connections   int `json:"connections,omitempty"`   // Connections shared round-robin by the workers, defaults to 1
+}
+
+// LatencySummary summarizes call latencies in milliseconds
+type LatencySummary struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// HistogramBucket counts the calls with a latency up to UpperMs (and above the previous bucket)
+type HistogramBucket struct {
+	UpperMs   float64 `json:"upperMs"`
+	Count     int     `json:"count"`
+	Frequency float64 `json:"frequency"` // Share of all calls, 0 to 1
+}
+
+// BenchmarkResult is the outcome of a load test
+type BenchmarkResult struct {
+	Host        string            `json:"host"`
+	Method      string            `json:"method"`
+	Concurrency int               `json:"concurrency"`
+	Connections int               `json:"connections"`
+	TargetRPS   int               `json:"targetRps,omitempty"`
+	Total       int               `json:"total"`
+	Succeeded   int               `json:"succeeded"`
+	Failed      int               `json:"failed"`
+	Stopped     bool              `json:"stopped"`          // The run was cancelled before completing
+	Throughput  float64           `json:"throughput"`       // Completed calls per second
+	StatusCodes map[string]int    `json:"statusCodes"`      // Calls per gRPC status name
+	Errors      map[string]int    `json:"errors,omitempty"` // Calls per distinct error message
+	Latency     LatencySummary    `json:"latency"`
+	Histogram   []HistogramBucket `json:"histogram"`
+	StartedAt   time.Time         `json:"startedAt"`
+	DurationMs  int64             `json:"durationMs"`
+}
+
+// JobType selects what a background job runs
+type JobType string
+
+const (
+	JobTypeCollectionRun JobType = "collectionRun"
+	JobTypeWorkflowRun   JobType = "workflowRun"
+	JobTypeBenchmark     JobType = "benchmark"
+)
+
+// JobStatus is the state of a background job
+type JobStatus string
+
+const (
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed" // The run finished; the result tells whether it passed
+	JobStatusFailed    JobStatus = "failed"    // The run could not be carried out
+	JobStatusCancelled JobStatus = "cancelled"
+)
+
+// StartJobRequest starts a collection run, workflow run or benchmark in the background
+type StartJobRequest struct {
+	Type     JobType         `json:"type" binding:"required"`
+	TargetID string          `json:"targetId,omitempty"` // Collection or workflow ID
+	Options  json.RawMessage `json:"options,omitempty"`  // CollectionRunOptions, RunRequestOptions or BenchmarkRequest
+	Persist  bool            `json:"persist,omitempty"`  // Keep the finished job under ~/.grpc-client/jobs
+}
+
+// JobProgress counts the finished units of a job (requests, workflow steps or calls)
+type JobProgress struct {
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	Total     int `json:"total,omitempty"` // 0 when not known in advance
+}
+
+// Job is a background run with its progress and, once finished, its result
+type Job struct {
+	ID         string      `json:"id"`
+	Type       JobType     `json:"type"`
+	TargetID   string      `json:"targetId,omitempty"`
+	Status     JobStatus   `json:"status"`
+	Progress   JobProgress `json:"progress"`
+	Error      string      `json:"error,omitempty"`
+	Result     interface{} `json:"result,omitempty"` // CollectionRunResult, WorkflowRunResult or BenchmarkResult
+	Persist    bool        `json:"persist,omitempty"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	FinishedAt *time.Time  `json:"finishedAt,omitempty"`
+	DurationMs int64       `json:"durationMs"`
+}
+
+// JobEvent is a progress event of a job, streamed to subscribers
+type JobEvent struct {
+	Sequence int         `json:"sequence"`
+	Type     string      `json:"type"` // started, item, step, progress or finished
+	Time     time.Time   `json:"time"`
+	Data     interface{} `json:"data,omitempty"`
+}
+
+// Monitor runs a saved request or a whole collection on a schedule
+type Monitor struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	RequestID       string            `json:"requestId,omitempty"`    // Saved request to run, or
+	CollectionID    string            `json:"collectionId,omitempty"` // collection to run
+	EnvironmentID   string            `json:"environmentId,omitempty"`
+	Variables       map[string]string `json:"variables,omitempty"`
+	IntervalSeconds int               `json:"intervalSeconds,omitempty"` // Run every N seconds, or
+	Cron            string            `json:"cron,omitempty"`            // on a cron schedule ("*/5 * * * *", "@hourly")
+	Enabled         bool              `json:"enabled"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
+}
+
+// MonitorState is the current state of a monitor
+type MonitorState string
+
+const (
+	MonitorStateUp      MonitorState = "up"      // The last check passed
+	MonitorStateDown    MonitorState = "down"    // The last check failed
+	MonitorStatePending MonitorState = "pending" // Not checked yet
+	MonitorStatePaused  MonitorState = "paused"  // Disabled
+)
+
+// MonitorCheck is the outcome of one monitor run
+type MonitorCheck struct {
+	StartedAt  time.Time `json:"startedAt"`
+	Passed     bool      `json:"passed"` // The call and its assertions passed (every request for collections)
+	Status     string    `json:"status,omitempty"`
+	Total      int       `json:"total"`
+	Failed     int       `json:"failed"`
+	Error      string    `json:"error,omitempty"` // Why the check failed
+	DurationMs int64     `json:"durationMs"`
+}
+
+// MonitorStatus summarizes a monitor and its recorded checks
+type MonitorStatus struct {
+	MonitorID           string         `json:"monitorId"`
+	Name                string         `json:"name"`
+	State               MonitorState   `json:"state"`
+	LastCheck           *MonitorCheck  `json:"lastCheck,omitempty"`
+	NextRunAt           *time.Time     `json:"nextRunAt,omitempty"`
+	Checks              int            `json:"checks"`
+	Failures            int            `json:"failures"`
+	ConsecutiveFailures int            `json:"consecutiveFailures"`
+	Uptime              float64        `json:"uptime"` // Share of passed checks, 0 to 1
+	Latency             LatencySummary `json:"latency"`
+}
+
+// NotifierType selects how a notification is delivered
+type NotifierType string
+
+const (
+	NotifierTypeWebhook NotifierType = "webhook" // POST the notification as JSON
+	NotifierTypeCommand NotifierType = "command" // Run a local command with the notification on stdin
+)
+
+// NotificationEvent is the kind of failure or change a notification reports
+type NotificationEvent string
+
+const (
+	NotificationEventRequestRun       NotificationEvent = "requestRun"       // A saved request failed its call, assertions or tests
+	NotificationEventCollectionRun    NotificationEvent = "collectionRun"    // A collection run had failing requests
+	NotificationEventWorkflowRun      NotificationEvent = "workflowRun"      // A workflow run failed
+	NotificationEventMonitorDown      NotificationEvent = "monitorDown"      // A monitor check failed after passing
+	NotificationEventMonitorRecovered NotificationEvent = "monitorRecovered" // A monitor check passed after failing
+	NotificationEventTest             NotificationEvent = "test"             // Sent by the test endpoint
+)
+
+// Notifier is a webhook or local command that is notified when runs fail
+type Notifier struct {
+	ID             string              `json:"id"`
+	Name           string              `json:"name"`
+	Type           NotifierType        `json:"type"`
+	URL            string              `json:"url,omitempty"`     // Webhook URL
+	Headers        map[string]string   `json:"headers,omitempty"` // Extra webhook headers
+	Command        string              `json:"command,omitempty"` // Command to run
+	Args           []string            `json:"args,omitempty"`
+	Events         []NotificationEvent `json:"events,omitempty"`         // Notify only these events; all when empty
+	CollectionIDs  []string            `json:"collectionIds,omitempty"`  // Notify only runs of these collections; all when empty
+	EnvironmentIDs []string            `json:"environmentIds,omitempty"` // Notify only runs in these environments; all when empty
+	Enabled        bool                `json:"enabled"`
+	CreatedAt      time.Time           `json:"createdAt"`
+	UpdatedAt      time.Time           `json:"updatedAt"`
+}
+
+// Notification is the JSON payload sent to webhooks and written to the stdin of commands
+type Notification struct {
+	Event         NotificationEvent `json:"event"`
+	Title         string            `json:"title"`              // One-line summary, e.g. for a chat message
+	Message       string            `json:"message"`            // First failure, or other details
+	TargetID      string            `json:"targetId,omitempty"` // Request, collection, workflow or monitor
+	TargetName    string            `json:"targetName,omitempty"`
+	CollectionID  string            `json:"collectionId,omitempty"`
+	EnvironmentID string            `json:"environmentId,omitempty"`
+	Total         int               `json:"total"`
+	Failed        int               `json:"failed"`
+	Result        interface{}       `json:"result,omitempty"` // Run result or monitor check
+	Time          time.Time         `json:"time"`
+}
+
+// NotificationDelivery is the outcome of sending a notification to a notifier
+type NotificationDelivery struct {
+	NotifierID string `json:"notifierId"`
+	Delivered  bool   `json:"delivered"`
+	StatusCode int    `json:"statusCode,omitempty"` // Webhook response status
+	Output     string `json:"output,omitempty"`     // Webhook response body or command output, truncated
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// HistoryEntry is a recorded gRPC call. Secrets in metadata, auth and variables are redacted.
+type HistoryEntry struct {
+	ID         string       `json:"id"`
+	Host       string       `json:"host"`
+	Method     string       `json:"method"`
+	Protocol   CallProtocol `json:"protocol"`
+	Status     string       `json:"status"`
+	StatusCode int          `json:"statusCode"`
+	Error      string       `json:"error,omitempty"`
+	Request    *GrpcRequest `json:"request,omitempty"`  // As submitted, before variable substitution
+	Resolved   *GrpcRequest `json:"resolved,omitempty"` // As sent, after variable substitution
+	Response   *CallResult  `json:"response,omitempty"`
+	StartedAt  time.Time    `json:"startedAt"`
+	DurationMs int64        `json:"durationMs"`
+}
+
+// HistoryList is a page of history entries, newest first
+type HistoryList struct {
+	Total   int            `json:"total"` // Number of entries matching the filter
+	Entries []HistoryEntry `json:"entries"`
+}
+
+// HistoryStats aggregates recorded calls per host and method
+type HistoryStats struct {
+	From     time.Time     `json:"from"`
+	To       time.Time     `json:"to"`
+	WindowMs int64         `json:"windowMs"` // Length of the time windows of each method
+	Calls    int           `json:"calls"`
+	Methods  []MethodStats `json:"methods"` // Most called first
+}
+
+// MethodStats are the statistics of the recorded calls of one method on one host
+type MethodStats struct {
+	Host         string         `json:"host"`
+	Method       string         `json:"method"`
+	Calls        int            `json:"calls"`
+	Succeeded    int            `json:"succeeded"`
+	Failed       int            `json:"failed"`
+	SuccessRate  float64        `json:"successRate"` // 0 to 1
+	StatusCodes  map[string]int `json:"statusCodes"` // Calls per status name
+	Latency      LatencySummary `json:"latency"`
+	LastCalledAt time.Time      `json:"lastCalledAt"`
+	LastError    *CallError     `json:"lastError,omitempty"`
+	Windows      []StatsWindow  `json:"windows"` // Oldest first, including windows without calls
+}
+
+// CallError describes a failed call
+type CallError struct {
+	Status string    `json:"status"`
+	Error  string    `json:"error"`
+	At     time.Time `json:"at"`
+}
+
+// StatsWindow holds the statistics of one time window
+type StatsWindow struct {
+	Start   time.Time       `json:"start"`
+	Calls   int             `json:"calls"`
+	Failed  int             `json:"failed"`
+	Latency *LatencySummary `json:"latency,omitempty"` // Not set for windows without calls
+}
+
+// ReplayHistoryRequest overrides parts of a recorded call when replaying it. Redacted
+// metadata and auth values are dropped unless they are supplied again here.
+type ReplayHistoryRequest struct {
+	MetaData      map[string]string `json:"metaData,omitempty"` // Merged over the recorded metadata
+	Auth          *RequestAuth      `json:"auth,omitempty"`     // Replaces the recorded auth
+	EnvironmentID string            `json:"environmentId,omitempty"`
+}
+
+// SaveHistoryRequest saves a recorded call as a request of a collection
+type SaveHistoryRequest struct {
+	CollectionID string `json:"collectionId" binding:"required"`
+	Name         string `json:"name,omitempty"` // Defaults to the method name
 }
 
 type CollectionItem struct {
@@ -132,8 +927,11 @@ type CreateCollectionRequest struct {
 }
 
 type UpdateCollectionRequest struct {
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
+	Name               string            `json:"name,omitempty"`
+	Description        string            `json:"description,omitempty"`
+	Variables          map[string]string `json:"variables,omitempty"`
+	PreRequestScript   *string           `json:"preRequestScript,omitempty"`
+	PostResponseScript *string           `json:"postResponseScript,omitempty"`
 }
 
 type CreateEnvironmentRequest struct {
@@ -141,6 +939,21 @@ type CreateEnvironmentRequest struct {
 	Name         string            `json:"name" binding:"required"`
 	Description  string            `json:"description,omitempty"`
 	Variables    map[string]string `json:"variables"`
+	Auth         *RequestAuth      `json:"auth,omitempty"`
+	Metadata     []RequestHeader   `json:"metadata,omitempty"`
+}
+
+type UpdateEnvironmentRequest struct {
+	Name        string            `json:"name,omitempty"`
+	Description *string           `json:"description,omitempty"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	Auth        *RequestAuth      `json:"auth,omitempty"`
+	Metadata    []RequestHeader   `json:"metadata,omitempty"`
+	IsActive    *bool             `json:"isActive,omitempty"` // Activating an environment deactivates the others in its collection
+}
+
+type UpdateWorkspaceVariablesRequest struct {
+	Variables map[string]string `json:"variables" binding:"required"`
 }
 
 type UpdateOrderRequest struct {
@@ -172,5 +985,10 @@ type Response struct {
 }
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error   string        `json:"error"`
+	Code    string        `json:"code,omitempty"`
+	Details []interface{} `json:"details,omitempty"`
+
+	// Attempts of a call sent with a retry policy
+	Attempts []CallAttempt `json:"attempts,omitempty"`
 }